@@ -0,0 +1,184 @@
+package pty
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// asciicastHeader is the first line of an asciicast v2 recording.
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// RecordAsciicast records s to w in the asciicast v2 format used by
+// asciinema. See Recorder.
+func RecordAsciicast(s *Session, w io.Writer, opts ...RecordOption) (*Recorder, error) {
+	start := time.Now()
+	ws := initialSize(s)
+	h := asciicastHeader{
+		Version:   2,
+		Width:     int(ws.Cols),
+		Height:    int(ws.Rows),
+		Timestamp: start.Unix(),
+	}
+	if term := sessionTerm(s); term != "" {
+		h.Env = map[string]string{"TERM": term}
+	}
+	a := &asciicast{w: w}
+	if err := a.writeLine(h); err != nil {
+		return nil, err
+	}
+	return newRecorder(s, start, a, opts), nil
+}
+
+type asciicast struct {
+	w       io.Writer
+	partial []byte        // Trailing bytes of an incomplete UTF-8 sequence.
+	last    time.Duration // Time of the last output.
+	line    bytes.Buffer
+}
+
+func (a *asciicast) output(t time.Duration, p []byte) error {
+	a.last = t
+	data := append(a.partial, p...)
+	cut := incompleteRune(data)
+	a.partial = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return nil
+	}
+	return a.event(t, "o", string(data[:cut]))
+}
+
+func (a *asciicast) input(t time.Duration, p []byte) error {
+	return a.event(t, "i", string(p))
+}
+
+func (a *asciicast) resize(t time.Duration, ws *Winsize) error {
+	return a.event(t, "r", strconv.Itoa(int(ws.Cols))+"x"+strconv.Itoa(int(ws.Rows)))
+}
+
+func (a *asciicast) close() error {
+	if len(a.partial) == 0 {
+		return nil
+	}
+	data := a.partial
+	a.partial = nil
+	return a.event(a.last, "o", string(data)) // Invalid UTF-8, flushed as is.
+}
+
+// event writes an event line: [time, code, data].
+func (a *asciicast) event(t time.Duration, code, data string) error {
+	return a.writeLine([]interface{}{json.Number(strconv.FormatFloat(t.Seconds(), 'f', 6, 64)), code, data})
+}
+
+func (a *asciicast) writeLine(v interface{}) error {
+	a.line.Reset()
+	enc := json.NewEncoder(&a.line)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := a.w.Write(a.line.Bytes())
+	return err
+}
+
+// incompleteRune returns the index at which an incomplete UTF-8 sequence
+// ends p, or len(p) if there is none, so that output split in the middle
+// of a character is not recorded as invalid UTF-8.
+func incompleteRune(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(p[i]) {
+			continue
+		}
+		if utf8.FullRune(p[i:]) {
+			return len(p)
+		}
+		return i
+	}
+	return len(p)
+}
+
+// LoadAsciicast loads an asciicast v2 recording from r.
+func LoadAsciicast(r io.Reader) (*Recording, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<24)
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	var h asciicastHeader
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+		return nil, fmt.Errorf("asciicast header: %w", err)
+	}
+	if h.Version != 2 {
+		return nil, fmt.Errorf("unsupported asciicast version %d", h.Version)
+	}
+	rec := &Recording{Size: &Winsize{Rows: uint16(h.Height), Cols: uint16(h.Width)}}
+	for line := 2; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		ev, err := parseAsciicastEvent(sc.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("asciicast line %d: %w", line, err)
+		}
+		if ev != nil {
+			rec.Events = append(rec.Events, *ev)
+		}
+	}
+	return rec, sc.Err()
+}
+
+// parseAsciicastEvent parses an event line. Unknown events are skipped
+// and reported as nil.
+func parseAsciicastEvent(line []byte) (*Event, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) != 3 {
+		return nil, errors.New("malformed event")
+	}
+	var (
+		t          float64
+		code, data string
+	)
+	if err := json.Unmarshal(raw[0], &t); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw[1], &code); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw[2], &data); err != nil {
+		return nil, err
+	}
+	ev := &Event{Time: time.Duration(t * float64(time.Second))}
+	switch code {
+	case "o":
+		ev.Kind, ev.Data = EventOutput, []byte(data)
+	case "i":
+		ev.Kind, ev.Data = EventInput, []byte(data)
+	case "r":
+		var cols, rows uint16
+		if _, err := fmt.Sscanf(data, "%dx%d", &cols, &rows); err != nil {
+			return nil, fmt.Errorf("malformed resize %q", data)
+		}
+		ev.Kind, ev.Size = EventResize, &Winsize{Rows: rows, Cols: cols}
+	default:
+		return nil, nil
+	}
+	return ev, nil
+}
@@ -0,0 +1,108 @@
+package pty
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Step is a step of a conversation run by Expecter.Run.
+type Step interface {
+	run(ctx context.Context, e *Expecter, w io.Writer) error
+}
+
+type stepFunc func(ctx context.Context, e *Expecter, w io.Writer) error
+
+func (f stepFunc) run(ctx context.Context, e *Expecter, w io.Writer) error {
+	return f(ctx, e, w)
+}
+
+// Send is a step sending s as is.
+func Send(s string) Step {
+	return stepFunc(func(ctx context.Context, _ *Expecter, w io.Writer) error {
+		_, err := WriteContext(ctx, w, []byte(s))
+		return err
+	})
+}
+
+// SendLine is a step sending s followed by a carriage return, as the enter
+// key does.
+func SendLine(s string) Step {
+	return Send(s + "\r")
+}
+
+// Expect is a step waiting for re. See Expecter.Expect.
+func Expect(re *regexp.Regexp) Step {
+	return ExpectAny(re)
+}
+
+// ExpectString is a step waiting for s. See Expecter.ExpectString.
+func ExpectString(s string) Step {
+	return Expect(regexp.MustCompile(regexp.QuoteMeta(s)))
+}
+
+// ExpectAny is a step waiting for any of res. See Expecter.ExpectAny.
+func ExpectAny(res ...*regexp.Regexp) Step {
+	cases := make([]Branch, len(res))
+	for i, re := range res {
+		cases[i] = Case(re)
+	}
+	return Switch(cases...)
+}
+
+// Branch is a case of a Switch.
+type Branch struct {
+	re    *regexp.Regexp
+	steps []Step
+}
+
+// Case returns a Branch running steps when re matches first.
+func Case(re *regexp.Regexp, steps ...Step) Branch {
+	return Branch{re: re, steps: steps}
+}
+
+// Switch is a step waiting for the pattern of any of cases, and running
+// the steps of the case matching first in the output, for example to
+// answer a prompt which may or may not appear.
+func Switch(cases ...Branch) Step {
+	return stepFunc(func(ctx context.Context, e *Expecter, w io.Writer) error {
+		res := make([]*regexp.Regexp, len(cases))
+		for i, c := range cases {
+			res[i] = c.re
+		}
+		i, _, err := e.ExpectAny(ctx, res...)
+		if err != nil {
+			return err
+		}
+		return runSteps(ctx, e, w, cases[i].steps)
+	})
+}
+
+// Run runs the steps of a conversation in order, sending input to w, the
+// input of the command whose output the Expecter reads, typically the
+// same Session. It stops at the first failing step, with an error telling
+// which one.
+//
+// For example, to log in:
+//
+//	err := e.Run(ctx, s,
+//		pty.ExpectString("login: "),
+//		pty.SendLine("bob"),
+//		pty.Switch(
+//			pty.Case(regexp.MustCompile(`[Pp]assword: `), pty.SendLine(password)),
+//			pty.Case(regexp.MustCompile(`\$ $`)),
+//		),
+//	)
+func (e *Expecter) Run(ctx context.Context, w io.Writer, steps ...Step) error {
+	return runSteps(ctx, e, w, steps)
+}
+
+func runSteps(ctx context.Context, e *Expecter, w io.Writer, steps []Step) error {
+	for i, s := range steps {
+		if err := s.run(ctx, e, w); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
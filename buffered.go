@@ -0,0 +1,158 @@
+package pty
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrBufferFull is returned by BufferedPty.Read once the buffer overflowed
+// with the OverflowError policy.
+var ErrBufferFull = errors.New("pty buffer full")
+
+// OverflowPolicy tells a BufferedPty what to do when the buffered output
+// reaches its high-water mark.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock stops reading from the pty until the buffer is drained,
+	// which eventually blocks the child.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered output.
+	OverflowDropOldest
+	// OverflowError stops reading from the pty and fails the next reads
+	// with ErrBufferFull once the buffer is drained. The output read last,
+	// which overflowed the buffer, is still returned first.
+	OverflowError
+)
+
+// defaultHighWater is the high-water mark of a BufferedPty given none.
+const defaultHighWater = 1 << 20
+
+// BufferedPty reads the output of a pty in the background into a buffer, so
+// the child doesn't freeze while the consumer stalls. Writes go straight to
+// the pty.
+type BufferedPty struct {
+	pty       io.ReadWriteCloser
+	highWater int
+	policy    OverflowPolicy
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    bytes.Buffer
+	err    error // Sticky error from the pty, or ErrBufferFull.
+	closed bool
+}
+
+// NewBufferedPty starts buffering up to highWater bytes of output from pty,
+// applying policy when more is pending. A highWater of zero or less means
+// 1MiB. A single read from the pty, of up to 32KiB, is buffered whole when
+// the buffer is empty, even past highWater, except with OverflowDropOldest.
+func NewBufferedPty(pty io.ReadWriteCloser, highWater int, policy OverflowPolicy) *BufferedPty {
+	if highWater <= 0 {
+		highWater = defaultHighWater
+	}
+	b := &BufferedPty{pty: pty, highWater: highWater, policy: policy}
+	b.cond = sync.NewCond(&b.mu)
+	go b.pump()
+	return b
+}
+
+func (b *BufferedPty) pump() {
+	buf := bufPool.Get().(*[]byte)
+	defer bufPool.Put(buf)
+
+	for {
+		n, err := b.pty.Read(*buf)
+
+		b.mu.Lock()
+		if n > 0 {
+			if !b.store((*buf)[:n]) {
+				b.mu.Unlock()
+				return
+			}
+		}
+		if err != nil {
+			if b.err == nil {
+				b.err = err
+			}
+			b.cond.Broadcast()
+			b.mu.Unlock()
+			return
+		}
+		b.mu.Unlock()
+	}
+}
+
+// store appends p to the buffer according to the overflow policy. It
+// reports whether the pump should keep going. Called with mu held.
+func (b *BufferedPty) store(p []byte) bool {
+	switch b.policy {
+	case OverflowBlock:
+		for !b.closed && b.buf.Len() > 0 && b.buf.Len()+len(p) > b.highWater {
+			b.cond.Wait()
+		}
+	case OverflowError:
+		if b.buf.Len() > 0 && b.buf.Len()+len(p) > b.highWater {
+			if !b.closed {
+				_, _ = b.buf.Write(p) // Read already, kept for the consumer.
+			}
+			b.err = ErrBufferFull
+			b.cond.Broadcast()
+			return false
+		}
+	}
+	if b.closed {
+		return false
+	}
+	_, _ = b.buf.Write(p) // Never fails.
+	if over := b.buf.Len() - b.highWater; over > 0 && b.policy == OverflowDropOldest {
+		b.buf.Next(over)
+	}
+	b.cond.Broadcast()
+	return true
+}
+
+// Read reads buffered output, waiting for some if there is none. Once the
+// buffer is drained, it returns the error that stopped the background reads.
+func (b *BufferedPty) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for !b.closed && b.buf.Len() == 0 && b.err == nil {
+		b.cond.Wait()
+	}
+	if b.closed {
+		return 0, os.ErrClosed
+	}
+	if b.buf.Len() == 0 {
+		return 0, b.err
+	}
+	n, _ := b.buf.Read(p)
+	b.cond.Broadcast()
+	return n, nil
+}
+
+// Write writes p to the pty.
+func (b *BufferedPty) Write(p []byte) (int, error) {
+	return b.pty.Write(p)
+}
+
+// Buffered returns the number of bytes waiting to be read.
+func (b *BufferedPty) Buffered() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Len()
+}
+
+// Close discards the buffer and closes the pty.
+func (b *BufferedPty) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.buf.Reset()
+	b.cond.Broadcast()
+	b.mu.Unlock()
+	return b.pty.Close()
+}
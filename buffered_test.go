@@ -0,0 +1,127 @@
+package pty
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestBufferedPty(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		policy OverflowPolicy
+		input  []string
+		expect string
+		err    error
+	}{
+		{name: "drop oldest", policy: OverflowDropOldest, input: []string{"0123", "45", "6789"}, expect: "6789", err: io.EOF},
+		{name: "error", policy: OverflowError, input: []string{"0123", "45"}, expect: "012345", err: ErrBufferFull},
+		{name: "error large read", policy: OverflowError, input: []string{"012345"}, expect: "012345", err: io.EOF},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			pty, tty := OpenPipe()
+			b := NewBufferedPty(pty, 4, tc.policy)
+			defer func() { _ = b.Close() }()
+
+			for _, s := range tc.input {
+				if _, err := tty.Write([]byte(s)); err != nil {
+					t.Fatalf("Unexpected error from Write: %s", err)
+				}
+			}
+			_ = tty.Close()
+
+			// Wait for the background reads to stop so the whole input
+			// went through the overflow policy.
+			b.mu.Lock()
+			for b.err == nil {
+				b.cond.Wait()
+			}
+			b.mu.Unlock()
+
+			var out bytes.Buffer
+			buf := make([]byte, 16)
+			var err error
+			for err == nil {
+				var n int
+				n, err = b.Read(buf)
+				out.Write(buf[:n])
+			}
+			if err != tc.err {
+				t.Errorf("Unexpected error from Read, got %v expected %v", err, tc.err)
+			}
+			if out.String() != tc.expect {
+				t.Errorf("Unexpected result returned from Read, got %q expected %q", out.String(), tc.expect)
+			}
+		})
+	}
+}
+
+func TestBufferedPtyBlock(t *testing.T) {
+	t.Parallel()
+
+	pty, tty := OpenPipe()
+	b := NewBufferedPty(pty, 4, OverflowBlock)
+	defer func() { _ = b.Close() }()
+
+	// The pump holds "45" until the buffer is drained, so nothing reads the
+	// third write in the meantime.
+	for _, s := range []string{"0123", "45"} {
+		if _, err := tty.Write([]byte(s)); err != nil {
+			t.Fatalf("Unexpected error from Write: %s", err)
+		}
+	}
+	written := make(chan error, 1)
+	go func() {
+		_, err := tty.Write([]byte("6789"))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		t.Fatalf("Unexpected Write through a full buffer, err %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	var out bytes.Buffer
+	buf := make([]byte, 16)
+	for out.Len() < len("0123456789") {
+		n, err := b.Read(buf)
+		if err != nil {
+			t.Fatalf("Unexpected error from Read: %s", err)
+		}
+		out.Write(buf[:n])
+	}
+	if err := <-written; err != nil {
+		t.Errorf("Unexpected error from Write: %s", err)
+	}
+	if out.String() != "0123456789" {
+		t.Errorf("Unexpected result returned from Read, got %q expected %q", out.String(), "0123456789")
+	}
+}
+
+func TestBufferedPtyDefaultHighWater(t *testing.T) {
+	t.Parallel()
+
+	pty, tty := OpenPipe()
+	b := NewBufferedPty(pty, 0, OverflowError)
+	defer func() { _ = b.Close() }()
+
+	if _, err := tty.Write([]byte("0123")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	_ = tty.Close()
+
+	out, err := ioutil.ReadAll(b)
+	if err != nil {
+		t.Errorf("Unexpected error from ReadAll: %s", err)
+	}
+	if string(out) != "0123" {
+		t.Errorf("Unexpected result returned from Read, got %q expected %q", out, "0123")
+	}
+}
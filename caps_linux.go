@@ -0,0 +1,65 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	prCapbsetDrop = 24         // PR_CAPBSET_DROP.
+	capVersion3   = 0x20080522 // _LINUX_CAPABILITY_VERSION_3.
+)
+
+var errCapability = errors.New("invalid capability")
+
+type capHeader struct {
+	version uint32
+	pid     int32 // 0 for the calling thread.
+}
+
+type capData struct {
+	effective   uint32
+	permitted   uint32
+	inheritable uint32
+}
+
+// setCaps drops drop from the bounding and inheritable sets of the calling
+// thread, which must be locked, and adds ambient to its inheritable set,
+// which the ambient capabilities of the processes it starts must be in.
+func setCaps(drop, ambient []uintptr) error {
+	for _, caps := range [][]uintptr{drop, ambient} {
+		for _, c := range caps {
+			if c >= 64 {
+				return errCapability
+			}
+		}
+	}
+	for _, c := range drop {
+		if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prCapbsetDrop, c, 0, 0, 0, 0); errno != 0 {
+			return os.NewSyscallError("prctl", errno)
+		}
+	}
+
+	hdr := capHeader{version: capVersion3}
+	var data [2]capData
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_CAPGET, uintptr(unsafe.Pointer(&hdr)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return os.NewSyscallError("capget", errno)
+	}
+	for _, c := range ambient {
+		data[c/32].inheritable |= 1 << (c % 32)
+	}
+	for _, c := range drop {
+		data[c/32].inheritable &^= 1 << (c % 32)
+	}
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_CAPSET, uintptr(unsafe.Pointer(&hdr)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return os.NewSyscallError("capset", errno)
+	}
+	return nil
+}
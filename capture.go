@@ -0,0 +1,158 @@
+package pty
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// captureMagic starts every capture.
+const captureMagic = "PTYCAP1\n"
+
+// captureHeaderSize is the size of a frame header: the kind of event on a
+// byte, the time since the start in nanoseconds on 8 bytes and the length
+// of the payload on 4 bytes, big endian.
+const captureHeaderSize = 13
+
+// RecordCapture records s to w in a compact binary format made of length
+// prefixed frames, one per event, timed with the monotonic clock, and read
+// back by CaptureReader. Input is recorded with RecordInput. See Recorder.
+func RecordCapture(s *Session, w io.Writer, opts ...RecordOption) (*Recorder, error) {
+	start := time.Now()
+	c := &capture{w: w}
+	if _, err := io.WriteString(w, captureMagic); err != nil {
+		return nil, err
+	}
+	if err := c.resize(0, initialSize(s)); err != nil {
+		return nil, err
+	}
+	return newRecorder(s, start, c, opts), nil
+}
+
+type capture struct {
+	w   io.Writer
+	hdr [captureHeaderSize]byte
+}
+
+func (c *capture) frame(kind EventKind, t time.Duration, p []byte) error {
+	c.hdr[0] = byte(kind)
+	binary.BigEndian.PutUint64(c.hdr[1:], uint64(t))
+	binary.BigEndian.PutUint32(c.hdr[9:], uint32(len(p)))
+	if _, err := c.w.Write(c.hdr[:]); err != nil {
+		return err
+	}
+	_, err := c.w.Write(p)
+	return err
+}
+
+func (c *capture) output(t time.Duration, p []byte) error {
+	return c.frame(EventOutput, t, p)
+}
+
+func (c *capture) input(t time.Duration, p []byte) error {
+	return c.frame(EventInput, t, p)
+}
+
+// resize writes the rows, columns, width and height of ws on 2 bytes each.
+func (c *capture) resize(t time.Duration, ws *Winsize) error {
+	var p [8]byte
+	binary.BigEndian.PutUint16(p[0:], ws.Rows)
+	binary.BigEndian.PutUint16(p[2:], ws.Cols)
+	binary.BigEndian.PutUint16(p[4:], ws.X)
+	binary.BigEndian.PutUint16(p[6:], ws.Y)
+	return c.frame(EventResize, t, p[:])
+}
+
+func (c *capture) close() error { return nil }
+
+// CaptureReader reads the events of a capture made by RecordCapture.
+type CaptureReader struct {
+	r     *bufio.Reader
+	magic bool
+	hdr   [captureHeaderSize]byte
+}
+
+// NewCaptureReader returns a CaptureReader reading from r.
+func NewCaptureReader(r io.Reader) *CaptureReader {
+	return &CaptureReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next event of the capture, or io.EOF at its end. The
+// errors reading the underlying reader are returned as is.
+func (c *CaptureReader) Next() (Event, error) {
+	if !c.magic {
+		var magic [len(captureMagic)]byte
+		_, err := io.ReadFull(c.r, magic[:])
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return Event{}, err
+		}
+		if err != nil || string(magic[:]) != captureMagic {
+			return Event{}, errors.New("not a capture")
+		}
+		c.magic = true
+	}
+	if _, err := io.ReadFull(c.r, c.hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return Event{}, fmt.Errorf("truncated capture: %w", err)
+		}
+		return Event{}, err
+	}
+	ev := Event{
+		Kind: EventKind(c.hdr[0]),
+		Time: time.Duration(binary.BigEndian.Uint64(c.hdr[1:])),
+	}
+	n := binary.BigEndian.Uint32(c.hdr[9:])
+	if n > maxEventData {
+		return Event{}, fmt.Errorf("capture frame too large: %d bytes", n)
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(c.r, p); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == io.ErrUnexpectedEOF {
+			return Event{}, fmt.Errorf("truncated capture: %w", err)
+		}
+		return Event{}, err
+	}
+	switch ev.Kind {
+	case EventOutput, EventInput:
+		ev.Data = p
+	case EventResize:
+		if len(p) != 8 {
+			return Event{}, errors.New("malformed capture resize")
+		}
+		ev.Size = &Winsize{
+			Rows: binary.BigEndian.Uint16(p[0:]),
+			Cols: binary.BigEndian.Uint16(p[2:]),
+			X:    binary.BigEndian.Uint16(p[4:]),
+			Y:    binary.BigEndian.Uint16(p[6:]),
+		}
+	default:
+		return Event{}, fmt.Errorf("unknown capture event %d", ev.Kind)
+	}
+	return ev, nil
+}
+
+// LoadCapture loads a capture made by RecordCapture from r.
+func LoadCapture(r io.Reader) (*Recording, error) {
+	cr := NewCaptureReader(r)
+	rec := &Recording{}
+	for {
+		ev, err := cr.Next()
+		if err == io.EOF {
+			return rec, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ev.Kind == EventResize && ev.Time == 0 && rec.Size == nil && len(rec.Events) == 0 {
+			rec.Size = ev.Size // Initial size.
+			continue
+		}
+		rec.Events = append(rec.Events, ev)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+// startInCgroup calls start, starting c, in the cgroup of o, if any.
+func startInCgroup(c *exec.Cmd, o *startOptions, start func() error) error {
+	if o.cgroup == "" {
+		return start()
+	}
+	dir, err := os.Open(o.cgroup)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dir.Close() }()
+	if attr := c.SysProcAttr; useCgroupFD(c, int(dir.Fd())) {
+		err := start()
+		c.SysProcAttr = attr
+		return err
+	}
+
+	// Starting into a cgroup requires clone3, Go 1.20 and Linux 5.7: move
+	// the command right after starting it instead.
+	if err := start(); err != nil {
+		return err
+	}
+	procs := filepath.Join(o.cgroup, "cgroup.procs")
+	if err := ioutil.WriteFile(procs, []byte(strconv.Itoa(c.Process.Pid)), 0); err != nil {
+		_ = c.Process.Kill() // Best effort.
+		_ = c.Wait()
+		return err
+	}
+	return nil
+}
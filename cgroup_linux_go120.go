@@ -0,0 +1,74 @@
+//go:build linux && go1.20
+// +build linux,go1.20
+
+package pty
+
+import (
+	"math"
+	"os/exec"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	cgroupFDOnce      sync.Once
+	cgroupFDSupported bool
+)
+
+// useCgroupFD makes c start right into the cgroup open as fd, with a copy
+// of its SysProcAttr, which may be shared. It reports false if the kernel
+// can not, leaving c untouched.
+func useCgroupFD(c *exec.Cmd, fd int) bool {
+	cgroupFDOnce.Do(func() { cgroupFDSupported = probeCgroupFD() })
+	if !cgroupFDSupported {
+		return false
+	}
+	attr := &syscall.SysProcAttr{}
+	if c.SysProcAttr != nil {
+		*attr = *c.SysProcAttr
+	}
+	attr.UseCgroupFD = true
+	attr.CgroupFD = fd
+	c.SysProcAttr = attr
+	return true
+}
+
+// cloneArgs is the struct clone_args of clone3, up to its cgroup field.
+type cloneArgs struct {
+	flags      uint64
+	pidFD      uint64
+	childTID   uint64
+	parentTID  uint64
+	exitSignal uint64
+	stack      uint64
+	stackSize  uint64
+	tls        uint64
+	setTID     uint64
+	setTIDSize uint64
+	cgroup     uint64
+}
+
+// probeCgroupFD reports whether clone3 can start a process into a cgroup,
+// which requires Linux 5.7 and may be denied by a seccomp filter. It calls
+// clone3 as exec does, but with a cgroup descriptor which is not open,
+// which only a kernel supporting it checks, failing with EBADF before
+// creating a process.
+func probeCgroupFD() bool {
+	nr := uintptr(435)
+	switch runtime.GOARCH {
+	case "mips", "mipsle":
+		nr = 4435
+	case "mips64", "mips64le":
+		nr = 5435
+	}
+	args := cloneArgs{
+		flags:      syscall.CLONE_VM | syscall.CLONE_VFORK | syscall.CLONE_INTO_CGROUP,
+		exitSignal: uint64(syscall.SIGCHLD),
+		cgroup:     math.MaxInt32, // The largest the kernel takes, never open.
+	}
+	_, _, errno := syscall.RawSyscall(nr, uintptr(unsafe.Pointer(&args)), unsafe.Sizeof(args), 0)
+	logDebug("pty: probe clone3 into cgroup", "err", errno)
+	return errno == syscall.EBADF
+}
@@ -0,0 +1,11 @@
+//go:build linux && !go1.20
+// +build linux,!go1.20
+
+package pty
+
+import "os/exec"
+
+// useCgroupFD reports false: starting right into a cgroup requires Go 1.20.
+func useCgroupFD(*exec.Cmd, int) bool {
+	return false
+}
@@ -0,0 +1,68 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// cgroup2Mount returns where the cgroup v2 hierarchy is mounted, if it is.
+func cgroup2Mount() string {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		for i, field := range fields {
+			if field == "-" && i+1 < len(fields) && fields[i+1] == "cgroup2" {
+				return fields[4]
+			}
+		}
+	}
+	return ""
+}
+
+func TestStartWithOptionsCgroup(t *testing.T) {
+	t.Parallel()
+
+	mnt := cgroup2Mount()
+	if mnt == "" {
+		t.Skip("cgroup v2 not mounted")
+	}
+	name := fmt.Sprintf("pty-test-%d", os.Getpid())
+	dir := filepath.Join(mnt, name)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Skipf("cgroup not creatable: %s", err)
+	}
+	defer func() { _ = os.Remove(dir) }()
+
+	c := exec.Command("cat", "/proc/self/cgroup")
+	attr := &syscall.SysProcAttr{} // May be shared with other commands.
+	c.SysProcAttr = attr
+	pty, err := StartWithOptions(c, WithCgroup(dir))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartWithOptions: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+	if c.SysProcAttr != attr || attr.UseCgroupFD {
+		t.Errorf("Unexpected SysProcAttr of the command, changed to %+v", c.SysProcAttr)
+	}
+
+	out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+	_ = c.Wait()
+	if expected := "0::/" + name; !strings.Contains(string(out), expected) {
+		t.Errorf("Unexpected cgroups, got %q expected a %q line", out, expected)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package pty
+
+import "os/exec"
+
+func startInCgroup(c *exec.Cmd, o *startOptions, start func() error) error {
+	if o.cgroup != "" {
+		return ErrUnsupported
+	}
+	return start()
+}
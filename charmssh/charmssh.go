@@ -0,0 +1,62 @@
+// Package charmssh runs the commands of the sessions of a
+// charmbracelet/ssh server in a pty. It is a module of its own, so that the
+// pty module does not depend on SSH.
+package charmssh
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/creack/pty"
+)
+
+// ErrNoPty is returned by Start when the client requested no pty.
+var ErrNoPty = errors.New("no pty requested")
+
+// Start starts c in a pty set up as the client of s requested: with its
+// TERM, or pty.DefaultTerm if it sent none, its window size and its
+// terminal modes. The session then follows the window size of the client
+// until the command exits, which is waited for from a new goroutine, as
+// Session.Done does. The environment sent by the client is not passed on:
+// set c.Env from s.Environ to do so.
+//
+// opts apply after those set up from the request, and can override them.
+func Start(s ssh.Session, c *exec.Cmd, opts ...pty.StartOption) (*pty.Session, error) {
+	req, winCh, ok := s.Pty()
+	if !ok {
+		return nil, ErrNoPty
+	}
+	ws := winsize(req.Window)
+	opts = append([]pty.StartOption{pty.WithTerm(req.Term), pty.WithSize(&ws), pty.WithTerminalModes(req.Modes)}, opts...)
+	sess, err := pty.StartSession(c, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(chan pty.Winsize)
+	go func() {
+		defer close(sizes)
+		for {
+			select {
+			case w, ok := <-winCh:
+				if !ok {
+					return
+				}
+				select {
+				case sizes <- winsize(w):
+				case <-sess.Done():
+					return
+				}
+			case <-sess.Done():
+				return
+			}
+		}
+	}()
+	sess.ResizeFrom(sizes, 0) // Ends once sizes is closed.
+	return sess, nil
+}
+
+func winsize(w ssh.Window) pty.Winsize {
+	return pty.Winsize{Rows: uint16(w.Height), Cols: uint16(w.Width)}
+}
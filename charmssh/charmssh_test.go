@@ -0,0 +1,88 @@
+package charmssh
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error from Listen: %s", err)
+	}
+	srv := &ssh.Server{Handler: func(s ssh.Session) {
+		sess, err := Start(s, exec.Command("sh", "-c", `echo "term $TERM"; stty size; case $(stty -a) in *-echo\ *) echo noecho;; esac; echo ready; while [ "$(stty size)" != "40 120" ]; do sleep 0.05; done; echo resized`))
+		if err != nil {
+			_, _ = io.WriteString(s, err.Error()+"\n")
+			_ = s.Exit(1)
+			return
+		}
+		defer func() { _ = sess.Close() }()
+		_, _ = io.Copy(s, sess)
+		_ = sess.Wait()
+		_ = s.Exit(0)
+	}}
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Close() }()
+
+	client, err := gossh.Dial("tcp", ln.Addr().String(), &gossh.ClientConfig{User: "user", HostKeyCallback: gossh.InsecureIgnoreHostKey()})
+	if err != nil {
+		t.Fatalf("Unexpected error from Dial: %s", err)
+	}
+	defer func() { _ = client.Close() }()
+	s, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("Unexpected error from NewSession: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	if err := s.RequestPty("vt100", 24, 80, gossh.TerminalModes{gossh.ECHO: 0}); err != nil {
+		t.Fatalf("Unexpected error from RequestPty: %s", err)
+	}
+	stdout, err := s.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Unexpected error from StdoutPipe: %s", err)
+	}
+	if err := s.Shell(); err != nil {
+		t.Fatalf("Unexpected error from Shell: %s", err)
+	}
+
+	outc := make(chan string)
+	go func() {
+		var out bytes.Buffer
+		buf := make([]byte, 1024)
+		resized := false
+		for {
+			n, err := stdout.Read(buf)
+			out.Write(buf[:n])
+			if !resized && strings.Contains(out.String(), "ready") {
+				_ = s.WindowChange(40, 120) // Checked by the command.
+				resized = true
+			}
+			if err != nil {
+				outc <- out.String()
+				return
+			}
+		}
+	}()
+	select {
+	case out := <-outc:
+		for _, expect := range []string{"term vt100", "24 80", "noecho", "resized"} {
+			if !strings.Contains(out, expect) {
+				t.Errorf("Unexpected output %q, expected %q in it", out, expect)
+			}
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timeout waiting for the command")
+	}
+}
@@ -0,0 +1,19 @@
+module github.com/creack/pty/charmssh
+
+go 1.19
+
+require (
+	github.com/charmbracelet/ssh v0.0.0-20240725163421-eb71b85b27aa
+	github.com/creack/pty v1.1.21
+	golang.org/x/crypto v0.25.0
+)
+
+require (
+	github.com/anmitsu/go-shlex v0.0.0-20200514113438-38f4b401e2be // indirect
+	github.com/charmbracelet/x/conpty v0.1.0 // indirect
+	github.com/charmbracelet/x/errors v0.0.0-20240508181413-e8d8b6e2de86 // indirect
+	github.com/charmbracelet/x/termios v0.1.0 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+)
+
+replace github.com/creack/pty => ../
@@ -0,0 +1,41 @@
+package pty
+
+// ProcessInfo describes a process.
+type ProcessInfo struct {
+	Pid     int
+	PPid    int      // The pid of its parent.
+	Command []string // Its arguments, or its name alone when they can not be read.
+}
+
+// Children returns the processes descending from the command of the
+// session, parents first, for example for hosts to display what runs in
+// it, such as "vim file.txt", and to warn before closing sessions with
+// active jobs. The command itself is not included. Processes which were
+// reparented, as daemons are, are not found.
+//
+// Processes are listed from /proc on Linux, and with ps(1) on the other
+// Unix platforms, where arguments containing spaces are split.
+func (s *Session) Children() ([]ProcessInfo, error) {
+	procs, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return descendants(procs, s.cmd.Process.Pid), nil
+}
+
+// descendants returns the processes of procs descending from pid, parents
+// first.
+func descendants(procs []ProcessInfo, pid int) []ProcessInfo {
+	children := map[int][]ProcessInfo{}
+	for _, p := range procs {
+		children[p.PPid] = append(children[p.PPid], p)
+	}
+	var out []ProcessInfo
+	for queue := []int{pid}; len(queue) > 0; queue = queue[1:] {
+		for _, c := range children[queue[0]] {
+			out = append(out, c)
+			queue = append(queue, c.Pid)
+		}
+	}
+	return out
+}
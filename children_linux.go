@@ -0,0 +1,48 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// listProcesses lists the processes in /proc.
+func listProcesses() ([]ProcessInfo, error) {
+	entries, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var procs []ProcessInfo
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		// Processes exiting meanwhile are skipped.
+		stat, err := ioutil.ReadFile("/proc/" + e.Name() + "/stat")
+		if err != nil {
+			continue
+		}
+		// The name, in parentheses, may contain anything. The state and
+		// the parent pid follow it.
+		i, j := bytes.IndexByte(stat, '('), bytes.LastIndexByte(stat, ')')
+		if i < 0 || j < i {
+			continue
+		}
+		fields := strings.Fields(string(stat[j+1:]))
+		if len(fields) < 2 {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		p := ProcessInfo{Pid: pid, PPid: ppid, Command: []string{string(stat[i+1 : j])}}
+		if cmdline, err := ioutil.ReadFile("/proc/" + e.Name() + "/cmdline"); err == nil && len(cmdline) > 0 {
+			p.Command = strings.Split(strings.TrimSuffix(string(cmdline), "\x00"), "\x00")
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
@@ -0,0 +1,35 @@
+//go:build !linux && !windows && !js && !plan9 && !wasip1
+// +build !linux,!windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// listProcesses lists the processes with ps.
+func listProcesses() ([]ProcessInfo, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=", "-o", "ppid=", "-o", "args=").Output()
+	if err != nil {
+		return nil, err
+	}
+	var procs []ProcessInfo
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		procs = append(procs, ProcessInfo{Pid: pid, PPid: ppid, Command: fields[2:]})
+	}
+	return procs, s.Err()
+}
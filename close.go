@@ -0,0 +1,43 @@
+package pty
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// CloseTty closes tty, as returned by Open, typically once the command was
+// started with it: the command keeps its own copies, so reads of the pty
+// keep returning its output until it closes them too, typically on exit,
+// then fail with EIO on Linux or io.EOF elsewhere.
+func CloseTty(tty *os.File) error {
+	return tty.Close()
+}
+
+// ClosePty closes pty, as returned by Open, hanging up the tty: a command
+// using it as its controlling terminal gets SIGHUP, and reads of the tty
+// fail. The output still buffered in the pty is lost, see CloseAll to keep
+// it.
+func ClosePty(pty *os.File) error {
+	return pty.Close()
+}
+
+// CloseAll closes pty and tty, as returned by Open, without losing the
+// output still buffered in the pty: it closes the tty first and, if w is
+// not nil, copies that output to w before closing the pty. The copy ends
+// once every copy of the tty is closed, or after a second at most, as a
+// command still running may keep it open indefinitely, and is skipped if
+// the pty does not support deadlines. It returns the first error closing
+// either.
+func CloseAll(pty, tty *os.File, w io.Writer) error {
+	err := CloseTty(tty)
+	if w != nil {
+		if derr := pty.SetReadDeadline(time.Now().Add(drainTimeout)); derr == nil {
+			_, _ = CopyPooled(w, EOFReader(pty)) // Ends with io.EOF or a timeout.
+		}
+	}
+	if perr := ClosePty(pty); err == nil {
+		err = perr
+	}
+	return err
+}
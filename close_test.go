@@ -0,0 +1,74 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCloseAll(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	if _, err := tty.Write([]byte("bye")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+
+	var out bytes.Buffer
+	if err := CloseAll(pty, tty, &out); err != nil {
+		t.Fatalf("Unexpected error from CloseAll: %s", err)
+	}
+	if got := out.String(); got != "bye" {
+		t.Errorf("Unexpected output drained, got %q expected %q", got, "bye")
+	}
+	if err := pty.Close(); err == nil {
+		t.Error("Expected an error closing the pty again")
+	}
+}
+
+func TestCloseTty(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+	if _, err := tty.Write([]byte("bye")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	if err := CloseTty(tty); err != nil {
+		t.Fatalf("Unexpected error from CloseTty: %s", err)
+	}
+
+	// The output written before is still read, then the pty reports the end.
+	buf := make([]byte, 3)
+	if err := readBytes(pty, buf); err != nil || string(buf) != "bye" {
+		t.Fatalf("Unexpected output after CloseTty, got %q, %v", buf, err)
+	}
+	if _, err := EOFReader(pty).Read(buf); err != io.EOF {
+		t.Errorf("Unexpected error from Read after CloseTty, got %v expected %v", err, io.EOF)
+	}
+}
+
+func TestClosePty(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = tty.Close() }()
+	if err := ClosePty(pty); err != nil {
+		t.Fatalf("Unexpected error from ClosePty: %s", err)
+	}
+	if _, err := tty.Read(make([]byte, 1)); err == nil {
+		t.Error("Expected an error reading the hung up tty")
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestClosedPty(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	_ = tty.Close()
+	_ = pty.Close()
+
+	if err := Setsize(pty, &Winsize{Rows: 1, Cols: 1}); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Unexpected error from Setsize, got %v expected %v", err, os.ErrClosed)
+	}
+	if _, err := GetsizeFull(pty); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Unexpected error from GetsizeFull, got %v expected %v", err, os.ErrClosed)
+	}
+	if _, err := pty.Read(make([]byte, 1)); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Unexpected error from Read, got %v expected %v", err, os.ErrClosed)
+	}
+}
@@ -0,0 +1,71 @@
+// Package compat is the API of github.com/creack/pty v1, frozen: the same
+// functions with the same signatures, implemented by the pty package.
+// Projects written against that API can import compat instead and keep
+// building as the pty package grows, then move to the pty package one call
+// at a time.
+package compat
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+// Winsize describes the terminal size. It is the pty.Winsize type, with
+// the Rows, Cols, X and Y fields in this order.
+type Winsize = pty.Winsize
+
+// ErrUnsupported is returned if a function is not available on the current
+// platform.
+var ErrUnsupported = pty.ErrUnsupported
+
+// Open a pty and its corresponding tty.
+func Open() (pty, tty *os.File, err error) {
+	return ptyOpen()
+}
+
+// Start assigns a pseudo-terminal tty os.File to c.Stdin, c.Stdout, and
+// c.Stderr, calls c.Start, and returns the File of the tty's corresponding
+// pty.
+func Start(cmd *exec.Cmd) (*os.File, error) {
+	return pty.Start(cmd)
+}
+
+// StartWithSize is Start, resizing the pty to ws first.
+func StartWithSize(cmd *exec.Cmd, ws *Winsize) (*os.File, error) {
+	return pty.StartWithSize(cmd, ws)
+}
+
+// StartWithAttrs is StartWithSize, with attrs overriding c.SysProcAttr.
+func StartWithAttrs(c *exec.Cmd, sz *Winsize, attrs *syscall.SysProcAttr) (*os.File, error) {
+	return pty.StartWithAttrs(c, sz, attrs)
+}
+
+// InheritSize applies the terminal size of pty to tty.
+func InheritSize(pty, tty *os.File) error {
+	return ptyInheritSize(pty, tty)
+}
+
+// Getsize returns the number of rows (lines) and cols (positions in each
+// line) in terminal t.
+func Getsize(t *os.File) (rows, cols int, err error) {
+	return pty.Getsize(t)
+}
+
+// GetsizeFull returns the full terminal size description.
+func GetsizeFull(t *os.File) (size *Winsize, err error) {
+	return pty.GetsizeFull(t)
+}
+
+// Setsize resizes t to s.
+func Setsize(t *os.File, ws *Winsize) error {
+	return pty.Setsize(t, ws)
+}
+
+// The parameters named pty, as in the original API, shadow the package.
+var (
+	ptyOpen        = pty.Open
+	ptyInheritSize = pty.InheritSize
+)
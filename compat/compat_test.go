@@ -0,0 +1,27 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package compat
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestStartWithSize(t *testing.T) {
+	t.Parallel()
+
+	c := exec.Command("stty", "size")
+	p, err := StartWithSize(c, &Winsize{Rows: 12, Cols: 34})
+	if err != nil {
+		t.Fatalf("Unexpected error from StartWithSize: %s", err)
+	}
+	defer func() { _ = p.Close() }()
+	out, _ := ioutil.ReadAll(p)
+	_ = c.Wait()
+	if !strings.Contains(string(out), "12 34") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
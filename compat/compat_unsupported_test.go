@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !netbsd && !openbsd && !solaris
+// +build !linux,!darwin,!freebsd,!dragonfly,!netbsd,!openbsd,!solaris
+
+package compat
+
+import "testing"
+
+func TestOpenUnsupported(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := Open(); err != ErrUnsupported {
+		t.Errorf("Unexpected error from Open, got %v expected %v", err, ErrUnsupported)
+	}
+}
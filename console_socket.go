@@ -0,0 +1,46 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"net"
+	"os"
+)
+
+// ConsoleSocket is a unix socket to give as --console-socket to runc, or
+// to other OCI runtimes, which send the pty of the container over it once
+// created, as SendFd does.
+type ConsoleSocket struct {
+	l *net.UnixListener
+}
+
+// NewConsoleSocket listens on a unix socket created at path.
+func NewConsoleSocket(path string) (*ConsoleSocket, error) {
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	return &ConsoleSocket{l: l}, nil
+}
+
+// Path returns the path of the socket.
+func (c *ConsoleSocket) Path() string {
+	return c.l.Addr().String()
+}
+
+// Accept waits for the runtime to connect and returns the pty it sends,
+// which can be resized and used like the ones Open returns.
+func (c *ConsoleSocket) Accept() (*os.File, error) {
+	conn, err := c.l.AcceptUnix()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	return RecvFd(conn)
+}
+
+// Close closes and removes the socket, unblocking Accept.
+func (c *ConsoleSocket) Close() error {
+	return c.l.Close()
+}
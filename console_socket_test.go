@@ -0,0 +1,57 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConsoleSocket(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "pty")
+	if err != nil {
+		t.Fatalf("Unexpected error from TempDir: %s", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	cs, err := NewConsoleSocket(filepath.Join(dir, "console.sock"))
+	if err != nil {
+		t.Fatalf("Unexpected error from NewConsoleSocket: %s", err)
+	}
+	defer func() { _ = cs.Close() }()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	// Act as the runtime.
+	go func() {
+		defer func() { _ = pty.Close() }()
+		conn, err := net.Dial("unix", cs.Path())
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = SendFd(conn.(*net.UnixConn), pty)
+	}()
+
+	console, err := cs.Accept()
+	if err != nil {
+		t.Fatalf("Unexpected error from Accept: %s", err)
+	}
+	defer func() { _ = console.Close() }()
+	if err := Setsize(console, &Winsize{Rows: 33, Cols: 77}); err != nil {
+		t.Fatalf("Unexpected error from Setsize: %s", err)
+	}
+	if rows, cols, err := Getsize(tty); err != nil || rows != 33 || cols != 77 {
+		t.Errorf("Unexpected size of the tty, got %dx%d, %v", rows, cols, err)
+	}
+}
@@ -0,0 +1,153 @@
+package pty
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// chunkSize keeps each write within the size of a tty input queue.
+const chunkSize = 4096
+
+// aLongTimeAgo is a deadline in the past, used to abort pending I/O.
+var aLongTimeAgo = time.Unix(1, 0)
+
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// ReadContext reads from r into p like r.Read, but gives up as soon as ctx
+// is done, returning ctx.Err(). r must support read deadlines, as a pty does
+// on most platforms, for a pending read to be aborted, otherwise ctx is only
+// checked before reading.
+func ReadContext(ctx context.Context, r io.Reader, p []byte) (n int, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if d, ok := r.(readDeadliner); ok {
+		defer watchContext(ctx, func(t time.Time) { logErr("pty: set read deadline", d.SetReadDeadline(t)) })()
+	}
+
+	n, err = r.Read(p)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return n, ctxErr
+		}
+	}
+	return n, err
+}
+
+// CopyContext copies from src to dst like io.Copy until either EOF is
+// reached on src, an error occurs or ctx is done, in which case the error
+// is ctx.Err(). Pending reads from src and writes to dst are aborted when
+// they support deadlines, so request-scoped handlers don't leak blocked
+// goroutines.
+func CopyContext(ctx context.Context, dst io.Writer, src io.Reader) (written int64, err error) {
+	rd, _ := src.(readDeadliner)
+	wd, _ := dst.(writeDeadliner)
+	if rd != nil || wd != nil {
+		defer watchContext(ctx, func(t time.Time) {
+			if rd != nil {
+				logErr("pty: set read deadline", rd.SetReadDeadline(t))
+			}
+			if wd != nil {
+				logErr("pty: set write deadline", wd.SetWriteDeadline(t))
+			}
+		})()
+	}
+
+	buf := bufPool.Get().(*[]byte)
+	defer bufPool.Put(buf)
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, rerr := src.Read(*buf)
+		if n > 0 {
+			m, werr := writeChunks(ctx, dst, (*buf)[:n])
+			written += int64(m)
+			if werr != nil {
+				return written, werr
+			}
+		}
+		if rerr != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return written, ctxErr
+			}
+			if rerr == io.EOF {
+				return written, nil
+			}
+			return written, rerr
+		}
+	}
+}
+
+// WriteContext writes p to w in chunks, so very large writes, such as a
+// multi-megabyte paste, make steady progress and can be canceled. It
+// returns the number of bytes written before an error or the cancellation
+// of ctx, in which case the error is ctx.Err().
+//
+// When w supports write deadlines, as a pty does on most platforms, a
+// pending write is aborted as soon as ctx is done. Otherwise ctx is only
+// checked between chunks.
+func WriteContext(ctx context.Context, w io.Writer, p []byte) (n int, err error) {
+	if d, ok := w.(writeDeadliner); ok {
+		defer watchContext(ctx, func(t time.Time) { logErr("pty: set write deadline", d.SetWriteDeadline(t)) })()
+	}
+	return writeChunks(ctx, w, p)
+}
+
+// writeChunks writes p to w in chunks, checking ctx between them. The
+// caller aborts a pending write with a deadline.
+func writeChunks(ctx context.Context, w io.Writer, p []byte) (n int, err error) {
+	for len(p) > 0 {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		chunk := p
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+		m, err := w.Write(chunk)
+		n += m
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return n, ctxErr
+			}
+			return n, err
+		}
+		p = p[m:]
+	}
+	return n, nil
+}
+
+// watchContext calls setDeadline with a deadline in the past when ctx is
+// done, to abort pending I/O. The returned function stops watching and
+// clears the deadline if it was set.
+func watchContext(ctx context.Context, setDeadline func(time.Time)) (stop func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	stopc := make(chan struct{})
+	done := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			setDeadline(aLongTimeAgo)
+			done <- true
+		case <-stopc:
+			done <- false
+		}
+	}()
+	return func() {
+		close(stopc)
+		if <-done {
+			setDeadline(time.Time{})
+		}
+	}
+}
@@ -0,0 +1,147 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteContext(t *testing.T) {
+	t.Parallel()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unexpected error from Pipe: %s", err)
+	}
+	defer func() { _ = r.Close() }()
+	defer func() { _ = w.Close() }()
+
+	// Nothing reads the pipe, so the write stalls once its buffer is full.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	text := bytes.Repeat([]byte("x"), 1<<20)
+	n, err := WriteContext(ctx, w, text)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Unexpected error from WriteContext, got %v expected %v", err, context.DeadlineExceeded)
+	}
+	if n == 0 || n >= len(text) {
+		t.Errorf("Unexpected count returned from WriteContext, got %d", n)
+	}
+}
+
+// cancelWriter cancels a context when written to and records the deadlines
+// set on it.
+type cancelWriter struct {
+	cancel    context.CancelFunc
+	mu        sync.Mutex
+	deadlines []time.Time
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	w.cancel()
+	time.Sleep(10 * time.Millisecond) // Let the deadline be set.
+	return len(p), nil
+}
+
+func (w *cancelWriter) SetWriteDeadline(t time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deadlines = append(w.deadlines, t)
+	return nil
+}
+
+func TestWriteContextClearsDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &cancelWriter{cancel: cancel}
+	n, err := WriteContext(ctx, w, bytes.Repeat([]byte("x"), 2*chunkSize))
+	if err != context.Canceled {
+		t.Errorf("Unexpected error from WriteContext, got %v expected %v", err, context.Canceled)
+	}
+	if n != chunkSize {
+		t.Errorf("Unexpected count returned from WriteContext, got %d expected %d", n, chunkSize)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.deadlines) != 2 || !w.deadlines[1].IsZero() {
+		t.Errorf("Unexpected deadlines, got %v expected a deadline then a reset", w.deadlines)
+	}
+}
+
+func TestReadContext(t *testing.T) {
+	t.Parallel()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unexpected error from Pipe: %s", err)
+	}
+	defer func() { _ = r.Close() }()
+	defer func() { _ = w.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	buf := make([]byte, 4)
+	if _, err := ReadContext(ctx, r, buf); err != context.DeadlineExceeded {
+		t.Errorf("Unexpected error from ReadContext, got %v expected %v", err, context.DeadlineExceeded)
+	}
+
+	// The deadline is cleared afterwards.
+	if _, err := w.Write([]byte("ping")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	n, err := ReadContext(context.Background(), r, buf)
+	if err != nil {
+		t.Errorf("Unexpected error from ReadContext: %s", err)
+	}
+	if string(buf[:n]) != "ping" {
+		t.Errorf("Unexpected result returned from ReadContext, got %q expected %q", buf[:n], "ping")
+	}
+}
+
+func TestCopyContext(t *testing.T) {
+	t.Parallel()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unexpected error from Pipe: %s", err)
+	}
+	defer func() { _ = r.Close() }()
+	defer func() { _ = w.Close() }()
+
+	if _, err := w.Write([]byte("ping")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var out bytes.Buffer
+	n, err := CopyContext(ctx, &out, r)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Unexpected error from CopyContext, got %v expected %v", err, context.DeadlineExceeded)
+	}
+	if n != 4 || out.String() != "ping" {
+		t.Errorf("Unexpected result returned from CopyContext, got %d, %q", n, out.String())
+	}
+}
+
+func TestCopyContextBlockedWrite(t *testing.T) {
+	t.Parallel()
+
+	pty, tty := OpenPipe()
+	defer func() { _ = pty.Close() }()
+	defer func() { _ = tty.Close() }()
+
+	// Nothing reads tty, so the write to pty blocks until ctx is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := CopyContext(ctx, pty, bytes.NewReader([]byte("ping"))); err != context.DeadlineExceeded {
+		t.Errorf("Unexpected error from CopyContext, got %v expected %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,41 @@
+package pty
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// bufPool holds the buffers used by the copy helpers so sustained copies
+// don't allocate one per call.
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 32*1024)
+		return &b
+	},
+}
+
+// Copy copies from pty to dst until either EOF is reached on pty or an
+// error occurs, like io.Copy.
+//
+// On Linux, when dst is backed by a file descriptor, such as a *net.TCPConn
+// or a *net.UnixConn, the data is moved with splice(2) without going
+// through user space. Copy falls back to io.Copy when splice is not
+// available.
+func Copy(dst io.Writer, pty *os.File) (written int64, err error) {
+	written, handled, err := splice(dst, pty)
+	if handled {
+		return written, err
+	}
+	return CopyPooled(dst, pty)
+}
+
+// CopyPooled is like io.Copy, but when neither dst nor src provide a fast
+// path, it uses a buffer from a pool shared by the helpers of this package
+// instead of allocating one per call. This keeps sustained relays in both
+// directions from churning the garbage collector.
+func CopyPooled(dst io.Writer, src io.Reader) (written int64, err error) {
+	buf := bufPool.Get().(*[]byte)
+	defer bufPool.Put(buf)
+	return io.CopyBuffer(dst, src, *buf)
+}
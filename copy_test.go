@@ -0,0 +1,80 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error from Listen: %s", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	received := make(chan []byte, 1)
+	go func() {
+		defer close(received)
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = c.Close() }()
+		b, _ := ioutil.ReadAll(c)
+		received <- b
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Unexpected error from Dial: %s", err)
+	}
+
+	text := []byte("ping")
+	if _, err := tty.Write(text); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	_ = tty.Close()
+
+	// Linux reports EIO once the tty is closed, other platforms EOF.
+	n, _ := Copy(conn, pty)
+	_ = conn.Close()
+
+	if n != int64(len(text)) {
+		t.Errorf("Unexpected count returned from Copy, got %d expected %d", n, len(text))
+	}
+	if b := <-received; !bytes.Equal(text, b) {
+		t.Errorf("Unexpected result received, got %v expected %v", b, text)
+	}
+}
+
+func BenchmarkCopyPooled(b *testing.B) {
+	src := bytes.Repeat([]byte("x"), 1<<20)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := CopyPooled(onlyWriter{ioutil.Discard}, onlyReader{bytes.NewReader(src)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// onlyReader and onlyWriter hide the io.WriterTo and io.ReaderFrom fast paths
+// of the underlying reader and writer.
+type (
+	onlyReader struct{ io.Reader }
+	onlyWriter struct{ io.Writer }
+)
@@ -0,0 +1,241 @@
+package pty
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrInputHeld is returned when writing the input of a DetachedSession from
+// an Attachment not holding it, with the InputExclusive policy.
+var ErrInputHeld = errors.New("input held by another client")
+
+// ErrNotAttached is returned when writing to a DetachedSession, or taking
+// its input, from a client which detached, and when handing its input to a
+// client which is not attached to it.
+var ErrNotAttached = errors.New("client not attached")
+
+// InputPolicy tells which attached clients may write to a DetachedSession.
+type InputPolicy int
+
+// Input policies.
+const (
+	InputShared    InputPolicy = iota // All the clients write, interleaved.
+	InputExclusive                    // Only the client holding the input writes.
+)
+
+// DetachedSession keeps reading the output of a Session in the background,
+// retaining its end in a Scrollback, so that clients can attach to it and
+// detach while the command keeps running, as in tmux or screen.
+type DetachedSession struct {
+	s  *Session
+	sb *Scrollback
+
+	mu      sync.Mutex // Orders the output sent to clients with Attach.
+	clients []*Attachment
+	policy  InputPolicy
+	holder  *Attachment // Of the input, with InputExclusive.
+	done    chan struct{}
+	err     error
+}
+
+// Attachment is a client attached to a DetachedSession.
+type Attachment struct {
+	d *DetachedSession
+	w io.Writer
+}
+
+// Detach starts reading s in the background, retaining up to maxLines
+// lines and maxBytes bytes of output for the clients attaching later. The
+// session must not be read from elsewhere.
+func (s *Session) Detach(maxLines, maxBytes int) *DetachedSession {
+	d := &DetachedSession{s: s, sb: NewScrollback(maxLines, maxBytes), done: make(chan struct{})}
+	go d.read()
+	return d
+}
+
+func (d *DetachedSession) read() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := d.s.Read(buf)
+		var failed []*Attachment
+		d.mu.Lock()
+		if n > 0 {
+			_, _ = d.sb.Write(buf[:n])
+			for _, a := range d.clients {
+				if _, werr := a.w.Write(buf[:n]); werr != nil && d.remove(a) {
+					failed = append(failed, a)
+				}
+			}
+		}
+		if err != nil {
+			if err != io.EOF && !isEIO(err) {
+				d.err = err
+			}
+			close(d.done)
+		}
+		d.mu.Unlock()
+		for range failed {
+			d.s.emit(LifecycleEvent{Kind: LifecycleDetach})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Attach writes the retained output to w, to redraw the screen, then the
+// output as it is read, until Detach is called or writing w fails. Slow
+// clients delay the others, so w should buffer or drop output rather than
+// block for long.
+func (d *DetachedSession) Attach(w io.Writer) (*Attachment, error) {
+	d.mu.Lock()
+	if _, err := w.Write(d.sb.Snapshot()); err != nil {
+		d.mu.Unlock()
+		return nil, err
+	}
+	a := &Attachment{d: d, w: w}
+	d.clients = append(d.clients[:len(d.clients):len(d.clients)], a)
+	d.mu.Unlock()
+	d.s.emit(LifecycleEvent{Kind: LifecycleAttach})
+	return a, nil
+}
+
+// remove detaches a, if still attached, passing the input it holds to the
+// client attached first, if any, and reports whether it was attached. d.mu
+// must be held.
+func (d *DetachedSession) remove(a *Attachment) bool {
+	i := d.index(a)
+	if i < 0 {
+		return false
+	}
+	clients := make([]*Attachment, 0, len(d.clients)-1)
+	d.clients = append(append(clients, d.clients[:i]...), d.clients[i+1:]...)
+	if d.holder == a {
+		d.holder = nil
+		if len(d.clients) > 0 {
+			d.holder = d.clients[0]
+		}
+	}
+	return true
+}
+
+// index returns the index of a in the attached clients, -1 if it is not
+// attached. d.mu must be held.
+func (d *DetachedSession) index(a *Attachment) int {
+	for i, c := range d.clients {
+		if c == a {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetInputPolicy sets which clients may write, InputShared by default.
+// Switching to InputExclusive leaves the input free for a client to take.
+func (d *DetachedSession) SetInputPolicy(p InputPolicy) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.policy, d.holder = p, nil
+}
+
+// Detach stops sending the output to the client. The input it holds, with
+// InputExclusive, passes to the client attached first, if any.
+func (a *Attachment) Detach() {
+	a.d.mu.Lock()
+	removed := a.d.remove(a)
+	a.d.mu.Unlock()
+	if removed {
+		a.d.s.emit(LifecycleEvent{Kind: LifecycleDetach})
+	}
+}
+
+// Write sends input to the command. It fails with ErrNotAttached once the
+// client detached, and with InputExclusive, with ErrInputHeld unless the
+// client holds the input.
+func (a *Attachment) Write(p []byte) (int, error) {
+	a.d.mu.Lock()
+	attached := a.d.index(a) >= 0
+	held := a.d.policy == InputExclusive && a.d.holder != a
+	a.d.mu.Unlock()
+	if !attached {
+		return 0, ErrNotAttached
+	}
+	if held {
+		return 0, ErrInputHeld
+	}
+	return a.d.s.Write(p)
+}
+
+// TakeInput makes the client hold the input with InputExclusive, if no
+// other client holds it. It fails with ErrInputHeld otherwise, and with
+// ErrNotAttached once the client detached.
+func (a *Attachment) TakeInput() error {
+	a.d.mu.Lock()
+	defer a.d.mu.Unlock()
+	if a.d.index(a) < 0 {
+		return ErrNotAttached
+	}
+	if a.d.holder != nil && a.d.holder != a {
+		return ErrInputHeld
+	}
+	a.d.holder = a
+	return nil
+}
+
+// HandInput passes the input held by the client to another, attached to
+// the same session. It fails with ErrInputHeld if the client does not hold
+// the input, and with ErrNotAttached if the other is not attached.
+func (a *Attachment) HandInput(to *Attachment) error {
+	a.d.mu.Lock()
+	defer a.d.mu.Unlock()
+	if a.d.holder != a {
+		return ErrInputHeld
+	}
+	if a.d.index(to) < 0 {
+		return ErrNotAttached
+	}
+	a.d.holder = to
+	return nil
+}
+
+// ReleaseInput releases the input held by the client, if any.
+func (a *Attachment) ReleaseInput() {
+	a.d.mu.Lock()
+	defer a.d.mu.Unlock()
+	if a.d.holder == a {
+		a.d.holder = nil
+	}
+}
+
+// Clients returns the number of attached clients.
+func (d *DetachedSession) Clients() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.clients)
+}
+
+// Write sends input to the command, whatever the input policy, as the
+// owner of the session.
+func (d *DetachedSession) Write(p []byte) (int, error) {
+	return d.s.Write(p)
+}
+
+// Resize resizes the pty of the session.
+func (d *DetachedSession) Resize(ws *Winsize) error {
+	return d.s.Resize(ws)
+}
+
+// Session returns the detached session.
+func (d *DetachedSession) Session() *Session { return d.s }
+
+// Done is closed once the output of the command ended.
+func (d *DetachedSession) Done() <-chan struct{} { return d.done }
+
+// Err returns the error which ended the output, nil if it ended normally.
+// It is only valid once Done is closed.
+func (d *DetachedSession) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
@@ -0,0 +1,136 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// chanWriter sends what is written to it on a channel.
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
+// readUntil reads c until it got substr.
+func (c chanWriter) readUntil(t *testing.T, substr string) string {
+	t.Helper()
+	var got string
+	for !strings.Contains(got, substr) {
+		select {
+		case s := <-c:
+			got += s
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timeout waiting for %q, got %q", substr, got)
+		}
+	}
+	return got
+}
+
+func TestDetachedSession(t *testing.T) {
+	t.Parallel()
+
+	s := startCat(t)
+	d := s.Detach(100, 0)
+
+	first := make(chanWriter, 16)
+	a, err := d.Attach(first)
+	if err != nil {
+		t.Fatalf("Unexpected error from Attach: %s", err)
+	}
+	if _, err := d.Write([]byte("one\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	first.readUntil(t, "one\r\none\r\n")
+	a.Detach()
+	if n := d.Clients(); n != 0 {
+		t.Errorf("Unexpected clients after detach: %d", n)
+	}
+
+	// The output while detached is retained.
+	if _, err := d.Write([]byte("two\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	second := make(chanWriter, 16)
+	for !strings.Contains(string(d.sb.Snapshot()), "two\r\ntwo\r\n") {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := d.Attach(second); err != nil {
+		t.Fatalf("Unexpected error from Attach: %s", err)
+	}
+	if got := second.readUntil(t, "two\r\ntwo\r\n"); !strings.HasPrefix(got, "one\r\none\r\n") {
+		t.Errorf("Unexpected replay: %q", got)
+	}
+
+	_ = s.Close()
+	select {
+	case <-d.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for the end of the output")
+	}
+}
+
+func TestDetachedSessionInput(t *testing.T) {
+	t.Parallel()
+
+	s := startCat(t)
+	d := s.Detach(100, 0)
+	out := make(chanWriter, 64)
+	a, _ := d.Attach(out)
+	b, _ := d.Attach(make(chanWriter, 64))
+
+	d.SetInputPolicy(InputExclusive)
+	if _, err := a.Write([]byte("x")); err != ErrInputHeld {
+		t.Errorf("Unexpected error from Write without the input, got %v", err)
+	}
+	if err := a.TakeInput(); err != nil {
+		t.Fatalf("Unexpected error from TakeInput: %s", err)
+	}
+	if err := b.TakeInput(); err != ErrInputHeld {
+		t.Errorf("Unexpected error from TakeInput of held input, got %v", err)
+	}
+	if _, err := a.Write([]byte("from a\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	out.readUntil(t, "from a\r\nfrom a\r\n")
+	if err := a.HandInput(b); err != nil {
+		t.Fatalf("Unexpected error from HandInput: %s", err)
+	}
+	if _, err := a.Write([]byte("x")); err != ErrInputHeld {
+		t.Errorf("Unexpected error from Write after handing the input, got %v", err)
+	}
+	if _, err := b.Write([]byte("from b\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	out.readUntil(t, "from b\r\nfrom b\r\n")
+
+	// Detaching passes the input to the client attached first.
+	b.Detach()
+	if _, err := a.Write([]byte("from a\n")); err != nil {
+		t.Fatalf("Unexpected error from Write after the holder detached: %s", err)
+	}
+	out.readUntil(t, "from a\r\nfrom a\r\n")
+	if err := a.HandInput(b); err != ErrNotAttached {
+		t.Errorf("Unexpected error from HandInput to a detached client, got %v expected %v", err, ErrNotAttached)
+	}
+	if _, err := a.Write([]byte("x")); err != nil {
+		t.Errorf("Unexpected error from Write after failing to hand the input: %s", err)
+	}
+
+	// A detached client can neither take the input nor write.
+	a.ReleaseInput()
+	if err := b.TakeInput(); err != ErrNotAttached {
+		t.Errorf("Unexpected error from TakeInput of a detached client, got %v expected %v", err, ErrNotAttached)
+	}
+	if _, err := b.Write([]byte("x")); err != ErrNotAttached {
+		t.Errorf("Unexpected error from Write of a detached client, got %v expected %v", err, ErrNotAttached)
+	}
+	if err := a.TakeInput(); err != nil {
+		t.Errorf("Unexpected error from TakeInput: %s", err)
+	}
+}
@@ -0,0 +1,30 @@
+package pty
+
+import "os"
+
+// DeviceInfo describes the device of a tty, for auditing, for the device
+// rules of cgroups or for bind mounting it into sandboxes.
+type DeviceInfo struct {
+	Path  string      // The path of the device, e.g. /dev/pts/3.
+	Major uint32      // The major device number.
+	Minor uint32      // The minor device number.
+	Inode uint64      // The inode of the device file.
+	UID   uint32      // The owner of the device file.
+	GID   uint32      // The group of the device file.
+	Mode  os.FileMode // The type and permissions of the device file.
+}
+
+// GetDeviceInfo describes the device of f, a tty as returned by Open, or a
+// pty, whose path is then the one of the multiplexer on some platforms,
+// e.g. /dev/ptmx.
+func GetDeviceInfo(f *os.File) (*DeviceInfo, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	info := &DeviceInfo{Path: f.Name(), Mode: fi.Mode()}
+	if err := statDevice(fi, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || dragonfly || netbsd || openbsd || solaris
+// +build linux darwin freebsd dragonfly netbsd openbsd solaris
+
+package pty
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGetDeviceInfo(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close(); _ = tty.Close() }()
+
+	info, err := GetDeviceInfo(tty)
+	if err != nil {
+		t.Fatalf("Unexpected error from GetDeviceInfo: %s", err)
+	}
+	if info.Path != tty.Name() || info.Mode&os.ModeCharDevice == 0 || info.Inode == 0 {
+		t.Errorf("Unexpected device info: %+v", info)
+	}
+	if runtime.GOOS == "linux" {
+		n, _ := strconv.Atoi(strings.TrimPrefix(tty.Name(), "/dev/pts/"))
+		// The ttys are numbered from UNIX98_PTY_SLAVE_MAJOR, 256 per major.
+		if info.Major < 136 || (info.Major-136)*256+info.Minor != uint32(n) {
+			t.Errorf("Unexpected device numbers %d:%d for %s", info.Major, info.Minor, tty.Name())
+		}
+	}
+}
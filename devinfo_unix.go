@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd || dragonfly || netbsd || openbsd || solaris
+// +build linux darwin freebsd dragonfly netbsd openbsd solaris
+
+package pty
+
+import (
+	"os"
+	"syscall"
+)
+
+func statDevice(fi os.FileInfo, info *DeviceInfo) error {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ErrUnsupported
+	}
+	info.Major, info.Minor = devNumbers(st)
+	info.Inode = uint64(st.Ino)
+	info.UID, info.GID = st.Uid, st.Gid
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build darwin
+// +build darwin
+
+package pty
+
+import "syscall"
+
+// devNumbers splits the device number of st, as major() and minor() do.
+func devNumbers(st *syscall.Stat_t) (major, minor uint32) {
+	dev := uint64(uint32(st.Rdev))
+	return uint32((dev >> 24) & 0xff), uint32(dev & 0xffffff)
+}
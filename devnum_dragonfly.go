@@ -0,0 +1,12 @@
+//go:build dragonfly
+// +build dragonfly
+
+package pty
+
+import "syscall"
+
+// devNumbers splits the device number of st, as major() and minor() do.
+func devNumbers(st *syscall.Stat_t) (major, minor uint32) {
+	dev := uint64(st.Rdev)
+	return uint32((dev >> 8) & 0xff), uint32(dev & 0xffff00ff)
+}
@@ -0,0 +1,12 @@
+//go:build freebsd
+// +build freebsd
+
+package pty
+
+import "syscall"
+
+// devNumbers splits the device number of st, as major() and minor() do.
+func devNumbers(st *syscall.Stat_t) (major, minor uint32) {
+	dev := uint64(st.Rdev)
+	return uint32((dev>>32)&0xffffff00 | (dev>>8)&0xff), uint32((dev>>24)&0xff00 | dev&0xffff00ff)
+}
@@ -0,0 +1,12 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import "syscall"
+
+// devNumbers splits the device number of st, as major() and minor() do.
+func devNumbers(st *syscall.Stat_t) (major, minor uint32) {
+	dev := uint64(st.Rdev)
+	return uint32((dev>>8)&0xfff | (dev>>32)&0xfffff000), uint32(dev&0xff | (dev>>12)&0xffffff00)
+}
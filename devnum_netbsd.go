@@ -0,0 +1,12 @@
+//go:build netbsd
+// +build netbsd
+
+package pty
+
+import "syscall"
+
+// devNumbers splits the device number of st, as major() and minor() do.
+func devNumbers(st *syscall.Stat_t) (major, minor uint32) {
+	dev := uint64(st.Rdev)
+	return uint32((dev & 0x000fff00) >> 8), uint32((dev&0xfff00000)>>12 | dev&0xff)
+}
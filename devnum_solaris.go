@@ -0,0 +1,12 @@
+//go:build solaris
+// +build solaris
+
+package pty
+
+import "syscall"
+
+// devNumbers splits the device number of st, as major() and minor() do.
+func devNumbers(st *syscall.Stat_t) (major, minor uint32) {
+	dev := uint64(st.Rdev)
+	return uint32(dev >> 32), uint32(dev & 0xffffffff)
+}
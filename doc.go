@@ -1,16 +1,58 @@
 // Package pty provides functions for working with Unix terminals.
 package pty
 
-import (
-	"errors"
-	"os"
-)
+import "os"
 
 // ErrUnsupported is returned if a function is not
-// available on the current platform.
-var ErrUnsupported = errors.New("unsupported")
+// available on the current platform. From Go 1.21, it also matches
+// errors.ErrUnsupported with errors.Is.
+var ErrUnsupported error = unsupportedError{}
+
+type unsupportedError struct{}
+
+func (unsupportedError) Error() string { return "unsupported" }
 
 // Open a pty and its corresponding tty.
+//
+// Both must be closed, in either order, see CloseAll. Closing the pty hangs
+// up the tty: the command using it gets SIGHUP if it is its controlling
+// terminal, and reads of the tty fail. Closing the tty only closes this
+// process' copy: once the command closed its own copies too, typically on
+// exit, reads of the pty return the output still buffered, then EIO on
+// Linux or io.EOF elsewhere.
+//
+// On Linux, when the tty can't be opened by path, as in some containers, it
+// is opened with TIOCGPTPEER instead, in blocking mode: it does not support
+// deadlines then.
 func Open() (pty, tty *os.File, err error) {
-	return open()
+	pty, tty, err = open()
+	if err != nil {
+		return nil, nil, wrapErr(OpOpen, "", err)
+	}
+	trackLeak(pty)
+	trackLeak(tty)
+	return pty, tty, nil
+}
+
+// OpenFull opens a pty and its corresponding tty like openpty(3): the tty
+// gets the attributes term and the window size ws, those not nil, before
+// they are returned, so that nothing using them sees the default settings
+// first. See GetTermios to start from the attributes of another terminal.
+func OpenFull(term *Termios, ws *Winsize) (pty, tty *os.File, err error) {
+	pty, tty, err = Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	if ws != nil {
+		err = Setsize(pty, ws)
+	}
+	if err == nil && term != nil {
+		err = wrapErr(OpSetModes, tty.Name(), setTermios(tty, term))
+	}
+	if err != nil {
+		logErr("pty: close tty", tty.Close()) // Best effort.
+		logErr("pty: close pty", pty.Close()) // Best effort.
+		return nil, nil, err
+	}
+	return pty, tty, nil
 }
@@ -0,0 +1,65 @@
+package pty
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrPtyDone is matched, with errors.Is, by the DoneError returned by the
+// reads of a Session started with WithDoneError once its command exited.
+var ErrPtyDone = errors.New("pty done")
+
+// DoneError is returned by the reads of a Session started with
+// WithDoneError once every tty is closed, typically when the command
+// exited, instead of the EIO of Linux or the io.EOF of other platforms.
+// Copy loops can so tell the normal end of a session from I/O failures.
+// The read does not wait for the command, which may still run after
+// closing its tty: Wait does.
+type DoneError struct {
+	// State is the state of the command if it was already waited for when
+	// the read ended, nil otherwise.
+	State *os.ProcessState
+
+	s *Session
+}
+
+func (e *DoneError) Error() string {
+	if e.State == nil {
+		return ErrPtyDone.Error()
+	}
+	return ErrPtyDone.Error() + ": " + e.State.String()
+}
+
+// Wait waits for the command, as Session.Wait does, and returns its state,
+// nil if waiting for it failed.
+func (e *DoneError) Wait() *os.ProcessState {
+	if e.State != nil || e.s == nil {
+		return e.State
+	}
+	_ = e.s.Wait()
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	return e.s.state
+}
+
+// Is makes DoneError match ErrPtyDone.
+func (e *DoneError) Is(target error) bool { return target == ErrPtyDone }
+
+// WithDoneError makes reading a Session report the end of its output as a
+// DoneError. It takes precedence over WithEIOAsEOF.
+func WithDoneError() StartOption {
+	return func(o *startOptions) { o.doneError = true }
+}
+
+// toDoneError returns the error to report for err, returned by a read of the
+// pty of s.
+func (s *Session) toDoneError(err error) error {
+	if err == io.EOF || isEIO(err) {
+		s.mu.Lock()
+		state := s.state // Only once reaped: EIO does not mean it exited.
+		s.mu.Unlock()
+		return &DoneError{State: state, s: s}
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package pty
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errReadStopped is returned by the reads of WaitAndDrain once aborted.
+var errReadStopped = errors.New("pty: read stopped")
+
+// drainTimeout bounds how long WaitAndDrain keeps reading once the command
+// exited, as background processes may hold the tty open indefinitely.
+var drainTimeout = time.Second
+
+// WaitAndDrain waits for the command to exit while reading its output, then
+// keeps reading the output still buffered in the pty until EOF, or EIO on
+// Linux, for at most a second. It returns everything read along with the
+// error from Wait, or ctx.Err() if ctx is done first, in which case the
+// command is left running.
+//
+// Reading the output while waiting keeps a chatty command from blocking on
+// a full pty, and reading after it exited collects the tail of its output,
+// which is easily lost when closing the pty as soon as Wait returns.
+func (s *Session) WaitAndDrain(ctx context.Context) ([]byte, error) {
+	var (
+		mu  sync.Mutex
+		out []byte
+	)
+	snapshot := func() []byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]byte(nil), out...)
+	}
+
+	drained := make(chan struct{})
+	stop := make(chan struct{})
+	go func() {
+		defer close(drained)
+		buf := make([]byte, chunkSize)
+		for {
+			n, err := s.read(buf, stop)
+			mu.Lock()
+			out = append(out, buf[:n]...)
+			mu.Unlock()
+			if err != nil {
+				return // EOF, EIO or aborted.
+			}
+		}
+	}()
+	// Best effort, the read loop is leaked if the pty doesn't support
+	// deadlines, but never reports past the returned output. Otherwise, the
+	// deadline set with SetReadDeadline is restored once it returned, or
+	// once it did not in time, for the session to be read again either way.
+	abort := func() {
+		s.mu.Lock()
+		close(stop)
+		s.cond.Broadcast() // Wakes it up while the output is paused.
+		s.mu.Unlock()
+		if err := s.pty.SetReadDeadline(aLongTimeAgo); err != nil {
+			logErr("pty: abort drain", err)
+			return
+		}
+		select {
+		case <-drained:
+		case <-time.After(drainTimeout):
+		}
+		s.mu.Lock()
+		logErr("pty: restore read deadline", s.pty.SetReadDeadline(s.deadline)) // Best effort.
+		s.mu.Unlock()
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- s.Wait() }()
+	var err error
+	select {
+	case err = <-exited:
+	case <-ctx.Done():
+		abort()
+		return snapshot(), ctx.Err()
+	}
+
+	t := time.NewTimer(drainTimeout)
+	defer t.Stop()
+	select {
+	case <-drained:
+	case <-t.C:
+		abort()
+	case <-ctx.Done():
+		abort()
+		return snapshot(), ctx.Err()
+	}
+	return snapshot(), err
+}
+
+// isDone reports whether c is closed, false for a nil c.
+func isDone(c <-chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
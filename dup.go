@@ -0,0 +1,39 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"os"
+	"syscall"
+)
+
+// Dup returns a second handle to the same pty, or tty, as f, which can be
+// read, written and closed independently of f, e.g. by a recorder next to
+// the interactive relay. Both handles share the pty: each read gets a part
+// of the output, the one the other handle did not.
+func Dup(f *os.File) (*os.File, error) {
+	sc, err := f.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	fd := -1
+	var derr error
+	// Control keeps f from being closed under our feet, and the fork lock
+	// the new descriptor from leaking to a command started meanwhile.
+	if err := sc.Control(func(v uintptr) {
+		syscall.ForkLock.RLock()
+		defer syscall.ForkLock.RUnlock()
+		if fd, derr = syscall.Dup(int(v)); derr == nil {
+			syscall.CloseOnExec(fd)
+		}
+	}); err != nil {
+		return nil, os.ErrClosed // Control only fails once f is closed.
+	}
+	if derr != nil {
+		return nil, os.NewSyscallError("dup", derr)
+	}
+	d := os.NewFile(uintptr(fd), f.Name())
+	trackLeak(d)
+	return d, nil
+}
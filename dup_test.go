@@ -0,0 +1,46 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDup(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	d, err := Dup(pty)
+	if err != nil {
+		t.Fatalf("Unexpected error from Dup: %s", err)
+	}
+	defer func() { _ = d.Close() }()
+	if err := pty.Close(); err != nil {
+		t.Fatalf("Unexpected error from Close: %s", err)
+	}
+
+	if _, err := tty.Write([]byte("hello")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	buf := make([]byte, 5)
+	n, err := ReadContext(ctx, d, buf)
+	if err != nil {
+		t.Fatalf("Unexpected error from Read of the duplicate: %s", err)
+	}
+	if got := string(buf[:n]); got != "hello" {
+		t.Errorf("Unexpected output, got %q expected %q", got, "hello")
+	}
+	if _, err := Dup(pty); err == nil {
+		t.Error("Expected an error from Dup of a closed pty")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build windows || js || plan9 || wasip1
+// +build windows js plan9 wasip1
+
+package pty
+
+import "os"
+
+// Dup returns a second handle to the same pty, or tty, as f.
+func Dup(*os.File) (*os.File, error) {
+	return nil, ErrUnsupported
+}
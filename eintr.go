@@ -0,0 +1,17 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import "syscall"
+
+// ignoringEINTR calls fn, retrying as long as it is interrupted by a signal.
+// SIGWINCH storms and profiling signals would otherwise leak spurious EINTR
+// errors to callers.
+func ignoringEINTR(fn func() error) error {
+	for {
+		if err := fn(); err != syscall.EINTR {
+			return err
+		}
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestIgnoringEINTR(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := ignoringEINTR(func() error {
+		calls++
+		if calls < 3 {
+			return syscall.EINTR
+		}
+		return syscall.EBADF
+	})
+	if err != syscall.EBADF {
+		t.Errorf("Unexpected error from ignoringEINTR, got %v expected %v", err, syscall.EBADF)
+	}
+	if calls != 3 {
+		t.Errorf("Unexpected number of calls, got %d expected %d", calls, 3)
+	}
+}
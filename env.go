@@ -0,0 +1,89 @@
+package pty
+
+import (
+	"os"
+	"strings"
+)
+
+// DefaultEnvDeny lists the variables SanitizeEnv removes by default: those
+// making the dynamic loader or the shell run code of the caller's choosing.
+// A trailing * matches any suffix.
+var DefaultEnvDeny = []string{
+	"LD_*",        // LD_PRELOAD, LD_LIBRARY_PATH, LD_AUDIT...
+	"DYLD_*",      // Their macOS counterparts.
+	"BASH_ENV",    // Sourced by non-interactive bash.
+	"ENV",         // Sourced by interactive sh.
+	"BASH_FUNC_*", // Exported bash functions.
+	"PROMPT_COMMAND",
+	"PS0",
+	"PS4",
+	"SHELLOPTS",
+	"BASHOPTS",
+	"IFS",
+	"GLOBIGNORE",
+	"CDPATH",
+}
+
+// EnvPolicy configures SanitizeEnv. The patterns are variable names, a
+// trailing * matching any suffix.
+type EnvPolicy struct {
+	Allow []string // If set, only the variables matching it are kept.
+	Deny  []string // The variables removed, DefaultEnvDeny if nil.
+}
+
+// SanitizeEnv returns env, or the environment of the current process if
+// nil, without the variables p denies, for starting a shell on behalf of a
+// remote user with c.Env. Malformed entries, without =, are removed too.
+func SanitizeEnv(env []string, p EnvPolicy) []string {
+	if env == nil {
+		env = os.Environ()
+	}
+	deny := p.Deny
+	if deny == nil {
+		deny = DefaultEnvDeny
+	}
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 {
+			continue
+		}
+		key := kv[:i]
+		if matchEnv(deny, key) || (p.Allow != nil && !matchEnv(p.Allow, key)) {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
+}
+
+func matchEnv(patterns []string, key string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") && strings.HasPrefix(key, p[:len(p)-1]) || p == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setEnv returns env, or the environment of the current process if nil,
+// with the KEY=value pairs of vars replacing the variables they set.
+func setEnv(env, vars []string) []string {
+	if env == nil {
+		env = os.Environ()
+	}
+	out := make([]string, 0, len(env)+len(vars))
+	for _, kv := range env {
+		keep := true
+		for _, v := range vars {
+			if i := strings.IndexByte(v, '='); i >= 0 && strings.HasPrefix(kv, v[:i+1]) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			out = append(out, kv)
+		}
+	}
+	return append(out, vars...)
+}
@@ -0,0 +1,27 @@
+package pty
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSanitizeEnv(t *testing.T) {
+	t.Parallel()
+
+	env := []string{
+		"PATH=/bin", "LD_PRELOAD=/tmp/x.so", "DYLD_INSERT_LIBRARIES=/tmp/x.dylib",
+		"BASH_FUNC_ls%%=() { :; }", "LANG=C", "HOME=/root", "garbage",
+	}
+	for _, tc := range []struct {
+		p    EnvPolicy
+		want []string
+	}{
+		{EnvPolicy{}, []string{"PATH=/bin", "LANG=C", "HOME=/root"}},
+		{EnvPolicy{Allow: []string{"PATH", "LD_*"}}, []string{"PATH=/bin"}},
+		{EnvPolicy{Deny: []string{"HOME"}}, []string{"PATH=/bin", "LD_PRELOAD=/tmp/x.so", "DYLD_INSERT_LIBRARIES=/tmp/x.dylib", "BASH_FUNC_ls%%=() { :; }", "LANG=C"}},
+	} {
+		if got := SanitizeEnv(env, tc.p); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Unexpected environment for %+v, got %q expected %q", tc.p, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package pty
+
+import "io"
+
+// EOFReader returns a Reader reading from r, the pty, which reports the
+// EIO returned on Linux once every tty is closed, typically when the
+// command exits, as io.EOF. This matches macOS and the BSDs and lets
+// generic code such as io.Copy end cleanly.
+func EOFReader(r io.Reader) io.Reader {
+	return eofReader{r: r}
+}
+
+type eofReader struct{ r io.Reader }
+
+func (e eofReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil && isEIO(err) {
+		err = io.EOF
+	}
+	return n, err
+}
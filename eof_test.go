@@ -0,0 +1,32 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSessionEIOAsEOF(t *testing.T) {
+	t.Parallel()
+
+	s, err := StartSession(exec.Command("echo", "hello"), WithEIOAsEOF())
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	out, err := ioutil.ReadAll(s)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadAll: %s", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "hello" {
+		t.Errorf("Unexpected output, got %q expected %q", got, "hello")
+	}
+	if err := s.Wait(); err != nil {
+		t.Errorf("Unexpected error from Wait: %s", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"errors"
+	"syscall"
+)
+
+func isEIO(err error) bool {
+	return errors.Is(err, syscall.EIO)
+}
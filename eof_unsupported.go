@@ -0,0 +1,8 @@
+//go:build windows || js || plan9 || wasip1
+// +build windows js plan9 wasip1
+
+package pty
+
+func isEIO(error) bool {
+	return false
+}
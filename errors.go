@@ -0,0 +1,30 @@
+package pty
+
+// PtyError records an error and the operation and file that caused it, as
+// os.PathError does: Open, Setsize, GetsizeFull, ReadV, WriteV and the
+// Start functions return their errors wrapped in one, but ErrUnsupported
+// and io.EOF. Use errors.Is and errors.As to check for the underlying
+// error, e.g. syscall.EACCES or exec.ErrNotFound.
+type PtyError struct {
+	Op   string // One of the Op constants.
+	Path string // The tty or the command, if any.
+	Err  error
+}
+
+func (e *PtyError) Error() string {
+	if e.Path == "" {
+		return e.Op + ": " + e.Err.Error()
+	}
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *PtyError) Unwrap() error { return e.Err }
+
+// wrapErr wraps err, unless nil or ErrUnsupported, in a PtyError.
+// ErrUnsupported is returned as is, for callers comparing it with ==.
+func wrapErr(op, path string, err error) error {
+	if err == nil || err == ErrUnsupported {
+		return err
+	}
+	return &PtyError{Op: op, Path: path, Err: err}
+}
@@ -0,0 +1,178 @@
+package pty
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultExpectBuffer bounds the output an Expecter retains while looking
+// for a match.
+const defaultExpectBuffer = 1 << 20
+
+// Match is a match found by an Expecter.
+type Match struct {
+	Before string   // Output preceding the match, since the previous one.
+	Groups []string // The match, followed by its capturing groups.
+}
+
+// Expecter waits for patterns in the output of a command, typically a
+// Session, to automate interactive programs. Output is read in the
+// background from the first call to Expect, and matches may span any
+// number of reads. It is safe for concurrent use, although concurrent
+// calls to Expect compete for the output.
+type Expecter struct {
+	r       io.Reader
+	maxBuf  int
+	timeout time.Duration
+
+	mu      sync.Mutex
+	buf     []byte
+	err     error         // Of the reads, once they ended.
+	changed chan struct{} // Closed and replaced when buf or err change.
+	started bool
+}
+
+// NewExpecter returns an Expecter reading from r, which it owns from then
+// on.
+func NewExpecter(r io.Reader) *Expecter {
+	return &Expecter{r: r, maxBuf: defaultExpectBuffer, changed: make(chan struct{})}
+}
+
+// SetTimeout sets the timeout of the calls to Expect whose context has no
+// deadline. Zero, the default, means no timeout.
+func (e *Expecter) SetTimeout(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timeout = d
+}
+
+// SetMaxBuffer sets how much unmatched output is retained, 1MiB by
+// default. The oldest output beyond it is discarded.
+func (e *Expecter) SetMaxBuffer(n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maxBuf = n
+}
+
+func (e *Expecter) read() {
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := e.r.Read(buf)
+		e.mu.Lock()
+		e.buf = append(e.buf, buf[:n]...)
+		if over := len(e.buf) - e.maxBuf; e.maxBuf > 0 && over > 0 {
+			e.buf = append(e.buf[:0], e.buf[over:]...)
+		}
+		if err != nil {
+			if isEIO(err) {
+				err = io.EOF
+			}
+			e.err = err
+		}
+		close(e.changed)
+		e.changed = make(chan struct{})
+		e.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Expect waits until the output matches re and returns the match, the
+// output up to the end of the match being consumed. It returns ctx.Err()
+// if ctx is done first, or the error which ended the output, io.EOF when
+// the command exited, if it never matches.
+func (e *Expecter) Expect(ctx context.Context, re *regexp.Regexp) (*Match, error) {
+	_, m, err := e.expect(ctx, re, []int{re.NumSubexp() + 1})
+	return m, err
+}
+
+// ExpectAny waits until the output matches one of res, and returns the
+// index of the pattern matching first in the output along with the match.
+// See Expect.
+func (e *Expecter) ExpectAny(ctx context.Context, res ...*regexp.Regexp) (int, *Match, error) {
+	if len(res) == 0 {
+		return -1, nil, errors.New("no pattern to expect")
+	}
+	// Each pattern is wrapped in a group, to tell which one matched.
+	alts := make([]string, len(res))
+	groups := make([]int, len(res))
+	for i, re := range res {
+		alts[i] = "(" + re.String() + ")"
+		groups[i] = re.NumSubexp() + 1
+	}
+	return e.expect(ctx, regexp.MustCompile(strings.Join(alts, "|")), groups)
+}
+
+// expect waits until the output matches re, made of alternatives with
+// groups[i] groups each, and returns the index of the alternative matching
+// along with its groups.
+func (e *Expecter) expect(ctx context.Context, re *regexp.Regexp, groups []int) (int, *Match, error) {
+	e.mu.Lock()
+	if !e.started {
+		e.started = true
+		go e.read()
+	}
+	if _, ok := ctx.Deadline(); !ok && e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	for {
+		if loc := re.FindSubmatchIndex(e.buf); loc != nil {
+			i, m := e.match(loc, groups)
+			e.mu.Unlock()
+			return i, m, nil
+		}
+		if e.err != nil {
+			err := e.err
+			e.mu.Unlock()
+			return -1, nil, err
+		}
+		changed := e.changed
+		e.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return -1, nil, ctx.Err()
+		}
+		e.mu.Lock()
+	}
+}
+
+// match consumes the match at loc and returns it. When there is more than
+// one alternative, each is wrapped in a group.
+func (e *Expecter) match(loc []int, groups []int) (int, *Match) {
+	alt, first := 0, 0
+	if len(groups) > 1 {
+		for first = 1; loc[2*first] < 0; first += groups[alt] {
+			alt++
+		}
+	}
+	m := &Match{Before: string(e.buf[:loc[0]]), Groups: make([]string, groups[alt])}
+	for i := range m.Groups {
+		if start := loc[2*(first+i)]; start >= 0 {
+			m.Groups[i] = string(e.buf[start:loc[2*(first+i)+1]])
+		}
+	}
+	e.buf = append(e.buf[:0], e.buf[loc[1]:]...)
+	return alt, m
+}
+
+// ExpectString waits until the output contains s. See Expect.
+func (e *Expecter) ExpectString(ctx context.Context, s string) (*Match, error) {
+	return e.Expect(ctx, regexp.MustCompile(regexp.QuoteMeta(s)))
+}
+
+// Buffered returns a copy of the output read but not consumed by a match.
+func (e *Expecter) Buffered() []byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]byte(nil), e.buf...)
+}
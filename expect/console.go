@@ -0,0 +1,159 @@
+// Package expect mirrors the Console API of github.com/Netflix/go-expect on
+// top of the ptys and the Expecter of github.com/creack/pty, to ease the
+// migration of test suites written for it.
+package expect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// ConsoleOpt configures a Console.
+type ConsoleOpt func(*Console)
+
+// WithDefaultTimeout sets the timeout of the Expect calls without their
+// own.
+func WithDefaultTimeout(d time.Duration) ConsoleOpt {
+	return func(c *Console) { c.timeout = d }
+}
+
+// WithStdout copies the output read by the Console to writers, for
+// example os.Stdout to watch a test run.
+func WithStdout(writers ...io.Writer) ConsoleOpt {
+	return func(c *Console) { c.stdouts = append(c.stdouts, writers...) }
+}
+
+// Console is a pty whose tty is given to the program under test, and whose
+// output is matched with the Expect methods.
+type Console struct {
+	ptm, tty *os.File
+	e        *pty.Expecter
+	timeout  time.Duration
+	stdouts  []io.Writer
+}
+
+// NewConsole opens a Console.
+func NewConsole(opts ...ConsoleOpt) (*Console, error) {
+	ptm, tty, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+	c := &Console{ptm: ptm, tty: tty}
+	for _, opt := range opts {
+		opt(c)
+	}
+	var r io.Reader = ptm
+	if len(c.stdouts) > 0 {
+		r = io.TeeReader(ptm, io.MultiWriter(c.stdouts...))
+	}
+	c.e = pty.NewExpecter(r)
+	return c, nil
+}
+
+// Tty returns the tty to give to the program under test, as its stdin,
+// stdout and stderr.
+func (c *Console) Tty() *os.File { return c.tty }
+
+// Fd returns the file descriptor of the pty.
+func (c *Console) Fd() uintptr { return c.ptm.Fd() }
+
+// Write writes input for the program.
+func (c *Console) Write(b []byte) (int, error) { return c.ptm.Write(b) }
+
+// Send sends s as input for the program.
+func (c *Console) Send(s string) (int, error) { return c.ptm.WriteString(s) }
+
+// SendLine sends s followed by a newline as input for the program.
+func (c *Console) SendLine(s string) (int, error) { return c.Send(s + "\n") }
+
+// Close closes both the pty and the tty.
+func (c *Console) Close() error {
+	_ = c.tty.Close() // Best effort.
+	return c.ptm.Close()
+}
+
+// neverRe never matches, to read until EOF.
+var neverRe = regexp.MustCompile(`[^\x00-\x{10FFFF}]`)
+
+// ExpectOpt configures a call to Expect.
+type ExpectOpt func(*expectOpts)
+
+type expectOpts struct {
+	res     []*regexp.Regexp
+	eof     bool
+	timeout time.Duration
+}
+
+// String matches any of strs.
+func String(strs ...string) ExpectOpt {
+	return func(o *expectOpts) {
+		for _, s := range strs {
+			o.res = append(o.res, regexp.MustCompile(regexp.QuoteMeta(s)))
+		}
+	}
+}
+
+// Regexp matches any of res.
+func Regexp(res ...*regexp.Regexp) ExpectOpt {
+	return func(o *expectOpts) { o.res = append(o.res, res...) }
+}
+
+// EOF matches the end of the output, once the tty is closed.
+func EOF(o *expectOpts) { o.eof = true }
+
+// WithTimeout sets the timeout of a call to Expect.
+func WithTimeout(d time.Duration) ExpectOpt {
+	return func(o *expectOpts) { o.timeout = d }
+}
+
+// Expect reads the output until it matches the options, and returns the
+// output read up to the end of the match.
+func (c *Console) Expect(opts ...ExpectOpt) (string, error) {
+	o := &expectOpts{timeout: c.timeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+	ctx := context.Background()
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	if len(o.res) == 0 {
+		if !o.eof {
+			return "", errors.New("nothing to expect")
+		}
+		o.res = []*regexp.Regexp{neverRe}
+	}
+	_, m, err := c.e.ExpectAny(ctx, o.res...)
+	if err == io.EOF && o.eof {
+		return string(c.e.Buffered()), nil
+	}
+	if err != nil {
+		return string(c.e.Buffered()), err
+	}
+	return m.Before + m.Groups[0], nil
+}
+
+// ExpectString reads the output until it contains s.
+func (c *Console) ExpectString(s string) (string, error) {
+	return c.Expect(String(s))
+}
+
+// Expectf reads the output until it contains the formatted string.
+func (c *Console) Expectf(format string, args ...interface{}) (string, error) {
+	return c.ExpectString(fmt.Sprintf(format, args...))
+}
+
+// ExpectEOF reads the output until its end, once the tty is closed.
+func (c *Console) ExpectEOF() (string, error) {
+	return c.Expect(EOF)
+}
@@ -0,0 +1,41 @@
+package expect
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsole(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConsole(WithDefaultTimeout(5 * time.Second))
+	if err != nil {
+		t.Skipf("Unexpected error from NewConsole: %s", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	cmd := exec.Command("sh", "-c", `printf "name? "; read n; echo "hi $n"`)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = c.Tty(), c.Tty(), c.Tty()
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Unexpected error from Start: %s", err)
+	}
+	if _, err := c.ExpectString("name? "); err != nil {
+		t.Fatalf("Unexpected error from ExpectString: %s", err)
+	}
+	if _, err := c.SendLine("bob"); err != nil {
+		t.Fatalf("Unexpected error from SendLine: %s", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("Unexpected error from Wait: %s", err)
+	}
+	_ = c.Tty().Close()
+	out, err := c.ExpectEOF()
+	if err != nil {
+		t.Fatalf("Unexpected error from ExpectEOF: %s", err)
+	}
+	if !strings.Contains(out, "hi bob") {
+		t.Errorf("Unexpected output, got %q", out)
+	}
+}
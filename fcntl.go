@@ -0,0 +1,15 @@
+//go:build !windows && !solaris && !aix && !js && !plan9 && !wasip1
+// +build !windows,!solaris,!aix,!js,!plan9,!wasip1
+
+package pty
+
+import "syscall"
+
+// getfl returns the file status flags of fd, such as O_NONBLOCK.
+func getfl(fd uintptr) (int, error) {
+	r, _, e := syscall.Syscall(syscall.SYS_FCNTL, fd, syscall.F_GETFL, 0)
+	if e != 0 {
+		return 0, e
+	}
+	return int(r), nil
+}
@@ -0,0 +1,22 @@
+//go:build solaris
+// +build solaris
+
+package pty
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+//go:cgo_import_dynamic libc_fcntl fcntl "libc.so"
+//go:linkname procfcntl libc_fcntl
+var procfcntl uintptr
+
+// getfl returns the file status flags of fd, such as O_NONBLOCK.
+func getfl(fd uintptr) (int, error) {
+	r, _, errno := sysvicall6(uintptr(unsafe.Pointer(&procfcntl)), 3, fd, syscall.F_GETFL, 0, 0, 0, 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(r), nil
+}
@@ -0,0 +1,21 @@
+//go:build go1.12
+// +build go1.12
+
+package pty
+
+import "os"
+
+// controlFd calls fn with the descriptor of f, without switching it to
+// blocking mode as f.Fd() would. The descriptor is only valid during fn,
+// which holds a reference on f: a concurrent Close cannot let it be
+// recycled meanwhile.
+func controlFd(f *os.File, fn func(fd int)) error {
+	sc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	if err := sc.Control(func(v uintptr) { fn(int(v)) }); err != nil {
+		return os.ErrClosed // Control only fails once f is closed.
+	}
+	return nil
+}
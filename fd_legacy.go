@@ -0,0 +1,15 @@
+//go:build !go1.12
+// +build !go1.12
+
+package pty
+
+import "os"
+
+func controlFd(f *os.File, fn func(fd int)) error {
+	fd := f.Fd() // Blocking io (old behavior).
+	if fd == ^uintptr(0) {
+		return os.ErrClosed
+	}
+	fn(int(fd))
+	return nil
+}
@@ -0,0 +1,67 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// SendFd sends f, along with its name, over conn as SCM_RIGHTS ancillary
+// data, for the process at the other end to get it with RecvFd. This lets
+// a privileged broker open ptys and hand them to unprivileged workers.
+// f remains open and can be closed once sent.
+func SendFd(conn *net.UnixConn, f *os.File) error {
+	sc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	// Send from Control, so that f cannot be closed and its descriptor
+	// reused meanwhile. The name doubles as the data which must accompany
+	// the descriptor.
+	if cerr := sc.Control(func(fd uintptr) {
+		_, _, err = conn.WriteMsgUnix([]byte(f.Name()), syscall.UnixRights(int(fd)), nil)
+	}); cerr != nil {
+		return os.ErrClosed // Control only fails once f is closed.
+	}
+	return err
+}
+
+// RecvFd receives a file sent with SendFd over conn, such as a pty, usable
+// as if it was opened by this process.
+func RecvFd(conn *net.UnixConn) (*os.File, error) {
+	buf := make([]byte, 4096)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, flags, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, err
+	}
+	var fds []int
+	for _, m := range msgs {
+		rights, err := syscall.ParseUnixRights(&m)
+		if err == nil {
+			fds = append(fds, rights...)
+		}
+	}
+	if flags&syscall.MSG_CTRUNC != 0 {
+		for _, fd := range fds {
+			_ = syscall.Close(fd) // Best effort.
+		}
+		return nil, errors.New("control message truncated")
+	}
+	if len(fds) == 0 {
+		return nil, errors.New("no file descriptor received")
+	}
+	for _, fd := range fds[1:] {
+		_ = syscall.Close(fd) // Best effort.
+	}
+	syscall.CloseOnExec(fds[0])
+	return os.NewFile(uintptr(fds[0]), string(buf[:n])), nil
+}
@@ -0,0 +1,74 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestSendFd(t *testing.T) {
+	t.Parallel()
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error from Socketpair: %s", err)
+	}
+	conns := make([]*net.UnixConn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "socket")
+		c, err := net.FileConn(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error from FileConn: %s", err)
+		}
+		defer func() { _ = c.Close() }()
+		conns[i] = c.(*net.UnixConn)
+	}
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	if err := SendFd(conns[0], pty); err != nil {
+		t.Fatalf("Unexpected error from SendFd: %s", err)
+	}
+	name := pty.Name()
+	_ = pty.Close()
+	got, err := RecvFd(conns[1])
+	if err != nil {
+		t.Fatalf("Unexpected error from RecvFd: %s", err)
+	}
+	defer func() { _ = got.Close() }()
+	if got.Name() != name {
+		t.Errorf("Unexpected name, got %q, want %q", got.Name(), name)
+	}
+
+	// The received pty still drives the tty.
+	if _, err := got.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	buf := make([]byte, 6)
+	if n, err := tty.Read(buf); err != nil || string(buf[:n]) != "hello\n" {
+		t.Errorf("Unexpected read from the tty, got %q, %v", buf[:n], err)
+	}
+
+	// More descriptors than RecvFd takes are not silently dropped.
+	var werr error
+	if err := controlFd(tty, func(fd int) {
+		_, _, werr = conns[0].WriteMsgUnix([]byte("tty"), syscall.UnixRights(fd, fd, fd), nil)
+	}); err != nil {
+		t.Fatalf("Unexpected error from controlFd: %s", err)
+	}
+	if werr != nil {
+		t.Fatalf("Unexpected error from WriteMsgUnix: %s", werr)
+	}
+	if _, err := RecvFd(conns[1]); err == nil {
+		t.Error("Unexpected success from RecvFd of a truncated control message")
+	}
+}
@@ -0,0 +1,90 @@
+//go:build go1.18
+// +build go1.18
+
+package pty
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+)
+
+func FuzzStripANSI(f *testing.F) {
+	f.Add([]byte("\x1b[1;31mred\x1b[0m \x1b]0;title\x07plain\x1b(B\x1bPdcs\x1b\\"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		got, err := ioutil.ReadAll(StripANSI(iotest.OneByteReader(bytes.NewReader(b))))
+		if err != nil {
+			t.Fatalf("Unexpected error from ReadAll: %s", err)
+		}
+		if bytes.IndexByte(got, 0x1b) >= 0 {
+			t.Errorf("Unexpected escape left in %q", got)
+		}
+		var buf bytes.Buffer
+		_, _ = StripANSIWriter(&buf).Write(b)
+		if !bytes.Equal(got, buf.Bytes()) {
+			t.Errorf("Unexpected difference between the reader and the writer, %q and %q", got, buf.Bytes())
+		}
+	})
+}
+
+func FuzzLoadAsciicast(f *testing.F) {
+	f.Add([]byte("{\"version\": 2, \"width\": 80, \"height\": 24}\n[0.1, \"o\", \"hi\"]\n[0.2, \"r\", \"100x30\"]\n"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		_, _ = LoadAsciicast(bytes.NewReader(b))
+	})
+}
+
+func FuzzLoadTtyrec(f *testing.F) {
+	f.Add([]byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 'h', 'i'})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		_, _ = LoadTtyrec(bytes.NewReader(b))
+	})
+}
+
+func FuzzLoadTypescript(f *testing.F) {
+	f.Add([]byte("Script started\nhi"), []byte("0.1 2\n"))
+	f.Fuzz(func(t *testing.T, ts, timing []byte) {
+		_, _ = LoadTypescript(bytes.NewReader(ts), bytes.NewReader(timing))
+	})
+}
+
+func FuzzCaptureReader(f *testing.F) {
+	f.Add([]byte(captureMagic + "\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x02hi"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r := NewCaptureReader(bytes.NewReader(b))
+		for {
+			if _, err := r.Next(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func FuzzLineReader(f *testing.F) {
+	f.Add([]byte("a\r\nb\nc\r\r\n10%\r50%\rdone"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		l := NewLineReader(iotest.OneByteReader(bytes.NewReader(b)))
+		for {
+			line, err := l.ReadLine(context.Background())
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error from ReadLine: %s", err)
+			}
+			if bytes.ContainsAny([]byte(line), "\n") {
+				t.Fatalf("Unexpected end of line in %q", line)
+			}
+		}
+	})
+}
+
+func FuzzEncodeKeys(f *testing.F) {
+	f.Add("ctrl+alt+shift+up", true)
+	f.Fuzz(func(t *testing.T, key string, appCursor bool) {
+		_, _ = EncodeKeys(appCursor, key)
+	})
+}
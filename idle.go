@@ -0,0 +1,78 @@
+package pty
+
+import "time"
+
+type idleWatcher struct {
+	d    time.Duration
+	fn   func(idle bool)
+	kick chan struct{}
+	stop chan struct{}
+}
+
+// WatchIdle calls fn(true) from a new goroutine once the session produced no
+// output and received no input for d, and fn(false) when activity resumes.
+// This is the building block for idle timeouts and activity notifications.
+// Calling the returned function stops watching.
+func (s *Session) WatchIdle(d time.Duration, fn func(idle bool)) (stop func()) {
+	w := &idleWatcher{d: d, fn: fn, kick: make(chan struct{}, 1), stop: make(chan struct{})}
+
+	s.mu.Lock()
+	s.watchers = append(s.watchers[:len(s.watchers):len(s.watchers)], w)
+	s.mu.Unlock()
+	go w.run()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, v := range s.watchers {
+			if v == w {
+				s.watchers = append(s.watchers[:i:i], s.watchers[i+1:]...)
+				close(w.stop)
+				return
+			}
+		}
+	}
+}
+
+// touch records activity on the session.
+func (s *Session) touch() {
+	s.mu.Lock()
+	watchers := s.watchers
+	s.mu.Unlock()
+	for _, w := range watchers {
+		select {
+		case w.kick <- struct{}{}:
+		default: // Already kicked.
+		}
+	}
+}
+
+func (w *idleWatcher) run() {
+	timer := time.NewTimer(w.d)
+	defer timer.Stop()
+
+	idle := false
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.kick:
+			if idle {
+				idle = false
+				w.fn(false)
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(w.d)
+		case <-timer.C:
+			if !idle {
+				idle = true
+				w.fn(true)
+			}
+		}
+	}
+}
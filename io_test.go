@@ -70,6 +70,33 @@ func TestReadClose(t *testing.T) {
 	}
 }
 
+// Check that both ends of a pty, as returned by Open, implement the
+// deadlines of the File interface.
+func TestReadDeadlineBothEnds(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("creack/pty uses blocking i/o on darwin intentionally")
+	}
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("error: open: %v\n", err)
+	}
+	defer func() { _ = pty.Close() }()
+	defer func() { _ = tty.Close() }()
+
+	for _, f := range []File{pty, tty} {
+		err := f.SetReadDeadline(time.Now().Add(timeout / 100))
+		if errors.Is(err, os.ErrNoDeadline) && runtime.GOOS != "linux" {
+			t.Skipf("deadline is not supported on %s/%s", runtime.GOOS, runtime.GOARCH)
+		}
+		if err != nil {
+			t.Fatalf("error: set read deadline of %s: %v", f.Name(), err)
+		}
+		if _, err := f.Read(make([]byte, 1)); !os.IsTimeout(err) {
+			t.Errorf("unexpected error reading %s past the deadline, got %v expected a timeout", f.Name(), err)
+		}
+	}
+}
+
 // Open pty and setup watchdogs for graceful and not so graceful failure modes
 func prepare(t *testing.T) (ptmx *os.File, done func()) {
 	if runtime.GOOS == "darwin" {
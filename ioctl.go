@@ -1,5 +1,5 @@
-//go:build !windows && go1.12
-// +build !windows,go1.12
+//go:build !windows && !js && !plan9 && !wasip1 && go1.12
+// +build !windows,!js,!plan9,!wasip1,go1.12
 
 package pty
 
@@ -8,15 +8,18 @@ import "os"
 func ioctl(f *os.File, cmd, ptr uintptr) error {
 	sc, e := f.SyscallConn()
 	if e != nil {
-		return ioctl_inner(f.Fd(), cmd, ptr) // fall back to blocking io (old behavior)
+		return sysIoctl(f.Fd(), cmd, ptr) // fall back to blocking io (old behavior)
 	}
 
 	ch := make(chan error, 1)
 	defer close(ch)
 
-	e = sc.Control(func(fd uintptr) { ch <- ioctl_inner(fd, cmd, ptr) })
+	// Control holds a reference on the descriptor until it returns, so a
+	// concurrent Close can't get it recycled under our feet. It only fails
+	// once f is closed.
+	e = sc.Control(func(fd uintptr) { ch <- sysIoctl(fd, cmd, ptr) })
 	if e != nil {
-		return e
+		return os.ErrClosed
 	}
 	e = <-ch
 	return e
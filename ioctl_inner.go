@@ -1,5 +1,5 @@
-//go:build !windows && !solaris && !aix
-// +build !windows,!solaris,!aix
+//go:build !windows && !solaris && !aix && !js && !plan9 && !wasip1
+// +build !windows,!solaris,!aix,!js,!plan9,!wasip1
 
 package pty
 
@@ -11,9 +11,16 @@ const (
 )
 
 func ioctl_inner(fd, cmd, ptr uintptr) error {
-	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, cmd, ptr)
+	_, err := ioctl_ret(fd, cmd, ptr)
+	return err
+}
+
+// ioctl_ret is ioctl_inner for the requests returning a value, such as the
+// descriptor opened by TIOCGPTPEER.
+func ioctl_ret(fd, cmd, arg uintptr) (uintptr, error) {
+	r, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, cmd, arg)
 	if e != 0 {
-		return e
+		return 0, e
 	}
-	return nil
+	return r, nil
 }
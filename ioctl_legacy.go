@@ -1,10 +1,10 @@
-//go:build !windows && !go1.12
-// +build !windows,!go1.12
+//go:build !windows && !js && !plan9 && !wasip1 && !go1.12
+// +build !windows,!js,!plan9,!wasip1,!go1.12
 
 package pty
 
 import "os"
 
 func ioctl(f *os.File, cmd, ptr uintptr) error {
-	return ioctl_inner(f.Fd(), cmd, ptr) // fall back to blocking io (old behavior)
+	return sysIoctl(f.Fd(), cmd, ptr) // fall back to blocking io (old behavior)
 }
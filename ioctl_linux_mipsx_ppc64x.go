@@ -0,0 +1,8 @@
+//go:build linux && (mips || mipsle || mips64 || mips64le || ppc64 || ppc64le)
+// +build linux
+// +build mips mipsle mips64 mips64le ppc64 ppc64le
+
+package pty
+
+// from <asm/ioctls.h>, _IO('T', 0x41) with _IOC_NONE = 1.
+const _TIOCGPTPEER = 0x20005441
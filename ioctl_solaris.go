@@ -41,8 +41,15 @@ type strioctl struct {
 func sysvicall6(trap, nargs, a1, a2, a3, a4, a5, a6 uintptr) (r1, r2 uintptr, err syscall.Errno)
 
 func ioctl_inner(fd, cmd, ptr uintptr) error {
-	if _, _, errno := sysvicall6(uintptr(unsafe.Pointer(&procioctl)), 3, fd, cmd, ptr, 0, 0, 0); errno != 0 {
-		return errno
+	_, err := ioctl_ret(fd, cmd, ptr)
+	return err
+}
+
+// ioctl_ret is ioctl_inner for the requests returning a value.
+func ioctl_ret(fd, cmd, arg uintptr) (uintptr, error) {
+	r, _, errno := sysvicall6(uintptr(unsafe.Pointer(&procioctl)), 3, fd, cmd, arg, 0, 0, 0)
+	if errno != 0 {
+		return 0, errno
 	}
-	return nil
+	return r, nil
 }
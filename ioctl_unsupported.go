@@ -11,3 +11,7 @@ const (
 func ioctl_inner(fd, cmd, ptr uintptr) error {
 	return ErrUnsupported
 }
+
+func ioctl_ret(fd, cmd, arg uintptr) (uintptr, error) {
+	return 0, ErrUnsupported
+}
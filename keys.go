@@ -0,0 +1,150 @@
+package pty
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Keys sending CSI sequences, with their final byte or their number when
+// they end with ~.
+var (
+	cursorKeys = map[string]byte{
+		"up": 'A', "down": 'B', "right": 'C', "left": 'D', "home": 'H', "end": 'F',
+		"↑": 'A', "↓": 'B', "→": 'C', "←": 'D',
+	}
+	tildeKeys = map[string]int{
+		"insert": 2, "delete": 3, "pgup": 5, "pgdown": 6,
+		"f5": 15, "f6": 17, "f7": 18, "f8": 19, "f9": 20, "f10": 21, "f11": 23, "f12": 24,
+	}
+	functionKeys = map[string]byte{"f1": 'P', "f2": 'Q', "f3": 'R', "f4": 'S'}
+	plainKeys    = map[string]string{
+		"enter": "\r", "tab": "\t", "esc": "\x1b", "escape": "\x1b",
+		"backspace": "\x7f", "space": " ",
+	}
+)
+
+// EncodeKeys returns the bytes a terminal sends for keys, each named like
+// "a", "enter", "ctrl+c", "alt+f", "shift+tab", "ctrl+up", "f5" or "↑".
+// appCursor selects the application cursor keys mode, set by programs such
+// as full screen editors with DECCKM.
+//
+// The named keys are enter, tab, esc, backspace, space, up, down, left,
+// right, home, end, insert, delete, pgup, pgdown and f1 to f12. The
+// modifiers are ctrl, alt and shift. Any other single character stands for
+// itself.
+func EncodeKeys(appCursor bool, keys ...string) ([]byte, error) {
+	var out []byte
+	for _, k := range keys {
+		b, err := encodeKey(k, appCursor)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
+
+func encodeKey(key string, appCursor bool) ([]byte, error) {
+	name := key
+	var ctrl, alt, shift bool
+	for {
+		i := strings.IndexByte(name, '+')
+		if i <= 0 || i == len(name)-1 {
+			break
+		}
+		switch strings.ToLower(name[:i]) {
+		case "ctrl", "control":
+			ctrl = true
+		case "alt", "meta", "opt", "option":
+			alt = true
+		case "shift":
+			shift = true
+		default:
+			return nil, fmt.Errorf("unknown modifier in key %q", key)
+		}
+		name = name[i+1:]
+	}
+	if utf8.RuneCountInString(name) != 1 {
+		name = strings.ToLower(name)
+	}
+
+	// xterm encodes modifiers of special keys as a parameter.
+	mod := 1
+	if shift {
+		mod++
+	}
+	if alt {
+		mod += 2
+	}
+	if ctrl {
+		mod += 4
+	}
+
+	if final, ok := cursorKeys[name]; ok {
+		switch {
+		case mod > 1:
+			return []byte("\x1b[1;" + strconv.Itoa(mod) + string(final)), nil
+		case appCursor:
+			return []byte{0x1b, 'O', final}, nil
+		}
+		return []byte{0x1b, '[', final}, nil
+	}
+	if n, ok := tildeKeys[name]; ok {
+		if mod > 1 {
+			return []byte("\x1b[" + strconv.Itoa(n) + ";" + strconv.Itoa(mod) + "~"), nil
+		}
+		return []byte("\x1b[" + strconv.Itoa(n) + "~"), nil
+	}
+	if final, ok := functionKeys[name]; ok {
+		if mod > 1 {
+			return []byte("\x1b[1;" + strconv.Itoa(mod) + string(final)), nil
+		}
+		return []byte{0x1b, 'O', final}, nil
+	}
+	if name == "tab" && shift {
+		return []byte("\x1b[Z"), nil
+	}
+
+	var b []byte
+	if s, ok := plainKeys[name]; ok {
+		b = []byte(s)
+	} else if utf8.RuneCountInString(name) == 1 {
+		b = []byte(name)
+		if shift {
+			b = []byte(strings.ToUpper(name))
+		}
+	} else {
+		return nil, fmt.Errorf("unknown key %q", key)
+	}
+	if ctrl {
+		if len(b) != 1 {
+			return nil, fmt.Errorf("no control code for key %q", key)
+		}
+		c, ok := controlCode(b[0])
+		if !ok {
+			return nil, fmt.Errorf("no control code for key %q", key)
+		}
+		b = []byte{c}
+	}
+	if alt {
+		b = append([]byte{0x1b}, b...)
+	}
+	return b, nil
+}
+
+// controlCode returns the code sent for c with the control key.
+func controlCode(c byte) (byte, bool) {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return c - 'a' + 1, true
+	case c >= '@' && c <= '_': // Upper case letters and @[\]^_.
+		return c - '@', true
+	case c == ' ':
+		return 0, true
+	case c == '?':
+		return 0x7f, true
+	}
+	return 0, false
+}
@@ -0,0 +1,55 @@
+package pty
+
+import "testing"
+
+func TestEncodeKeys(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		keys      []string
+		appCursor bool
+		want      string
+	}{
+		{[]string{"h", "i", "enter"}, false, "hi\r"},
+		{[]string{"ctrl+c", "ctrl+D", "ctrl+space", "ctrl+["}, false, "\x03\x04\x00\x1b"},
+		{[]string{"up", "↓", "home"}, false, "\x1b[A\x1b[B\x1b[H"},
+		{[]string{"up", "left"}, true, "\x1bOA\x1bOD"},
+		{[]string{"ctrl+up", "shift+f5", "f1", "f12", "delete"}, true, "\x1b[1;5A\x1b[15;2~\x1bOP\x1b[24~\x1b[3~"},
+		{[]string{"alt+f", "alt+enter", "shift+tab", "shift+a", "+"}, false, "\x1bf\x1b\r\x1b[ZA+"},
+	} {
+		got, err := EncodeKeys(tc.appCursor, tc.keys...)
+		if err != nil {
+			t.Errorf("Unexpected error from EncodeKeys(%q): %s", tc.keys, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("Unexpected encoding of %q, got %q expected %q", tc.keys, got, tc.want)
+		}
+	}
+	for _, k := range []string{"nope", "hyper+a", "ctrl+1"} {
+		if _, err := EncodeKeys(false, k); err == nil {
+			t.Errorf("Expected an error encoding %q", k)
+		}
+	}
+}
+
+func TestModeTracker(t *testing.T) {
+	t.Parallel()
+
+	var m modeTracker
+	for _, tc := range []struct {
+		output string
+		want   bool
+	}{
+		{"\x1b[?1h", true},
+		{"\x1b[?25l\x1b[1l", true}, // Other modes, and not a private one.
+		{"\x1b[?1049;1", true},     // Split across writes.
+		{"l", false},
+		{"\x1b[?1\x1b[?1h", true}, // An ESC restarts the sequence.
+	} {
+		_, _ = m.Write([]byte(tc.output))
+		if got := m.AppCursor(); got != tc.want {
+			t.Errorf("Unexpected mode after %q, got %v expected %v", tc.output, got, tc.want)
+		}
+	}
+}
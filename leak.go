@@ -0,0 +1,47 @@
+package pty
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// leakCheck is set when ptys are tracked for leaks. It is enabled by
+// SetLeakDetection or by setting PTY_LEAKCHECK=1 in the environment.
+var leakCheck uint32
+
+func init() {
+	if os.Getenv("PTY_LEAKCHECK") == "1" {
+		leakCheck = 1
+	}
+}
+
+// SetLeakDetection enables or disables leak detection. While enabled, each
+// pty and tty returned by Open records the stack that allocated it, and
+// files garbage collected without having been closed are reported to the
+// Logger, along with that stack. This is costly and meant for debugging.
+func SetLeakDetection(enabled bool) {
+	var v uint32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreUint32(&leakCheck, v)
+}
+
+// trackLeak registers a finalizer on f reporting it if it is collected
+// while still open.
+func trackLeak(f *os.File) {
+	if f == nil || atomic.LoadUint32(&leakCheck) == 0 {
+		return
+	}
+	stack := debug.Stack()
+	runtime.SetFinalizer(f, func(f *os.File) {
+		if err := controlFd(f, func(int) {}); err != nil {
+			return // Closed.
+		}
+		if l := getLogger(); l != nil {
+			l.Warn("pty: leaked file", "name", f.Name(), "stack", string(stack))
+		}
+	})
+}
@@ -0,0 +1,58 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type chanLogger chan string
+
+func (c chanLogger) Debug(string, ...interface{}) {}
+
+func (c chanLogger) Warn(msg string, args ...interface{}) {
+	if msg == "pty: leaked file" {
+		c <- args[3].(string)
+	}
+}
+
+func TestLeakDetection(t *testing.T) {
+	l := make(chanLogger, 2)
+	SetLogger(l)
+	defer SetLogger(nil)
+	SetLeakDetection(true)
+	defer SetLeakDetection(false)
+
+	func() {
+		pty, tty, err := Open()
+		if err != nil {
+			t.Fatalf("Unexpected error from Open: %s", err)
+		}
+		_ = pty.Close()
+		_ = tty // Leaked.
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case stack := <-l:
+			if !strings.Contains(stack, "TestLeakDetection") {
+				t.Errorf("Unexpected stack reported: %s", stack)
+			}
+			select {
+			case <-l:
+				t.Error("Unexpected leak reported for a closed pty")
+			case <-time.After(100 * time.Millisecond):
+			}
+			return
+		case <-deadline:
+			t.Fatal("Timed out waiting for the leak report")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
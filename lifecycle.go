@@ -0,0 +1,66 @@
+package pty
+
+import (
+	"os"
+	"time"
+)
+
+// LifecycleKind is the kind of a LifecycleEvent.
+type LifecycleKind int
+
+// Lifecycle event kinds.
+const (
+	LifecycleStart       LifecycleKind = iota // The command started.
+	LifecycleFirstOutput                      // The first output was read.
+	LifecycleResize                           // The pty was resized to Size.
+	LifecycleAttach                           // A client attached, see DetachedSession.
+	LifecycleDetach                           // A client detached, or failed.
+	LifecycleExit                             // The command exited, State and Err tell how.
+)
+
+func (k LifecycleKind) String() string {
+	switch k {
+	case LifecycleStart:
+		return "start"
+	case LifecycleFirstOutput:
+		return "first output"
+	case LifecycleResize:
+		return "resize"
+	case LifecycleAttach:
+		return "attach"
+	case LifecycleDetach:
+		return "detach"
+	case LifecycleExit:
+		return "exit"
+	}
+	return "unknown"
+}
+
+// LifecycleEvent is an event in the life of a Session.
+type LifecycleEvent struct {
+	Kind  LifecycleKind
+	Time  time.Time
+	Size  *Winsize         // For LifecycleResize.
+	State *os.ProcessState // For LifecycleExit, nil if the command could not be waited for.
+	Err   error            // The result of Wait, for LifecycleExit.
+}
+
+// WithLifecycleHook calls fn on the lifecycle events of the session started
+// with StartSession, from its start to the exit of its command, giving
+// audit trails a single integration point. It may be given more than once,
+// the hooks being called in order. They are called synchronously, from the
+// goroutine causing the event, and must hence return quickly.
+func WithLifecycleHook(fn func(s *Session, e LifecycleEvent)) StartOption {
+	return func(o *startOptions) { o.hooks = append(o.hooks, fn) }
+}
+
+// emit calls the lifecycle hooks of the session with e.
+func (s *Session) emit(e LifecycleEvent) {
+	if len(s.hooks) == 0 {
+		return
+	}
+	e.Time = time.Now()
+	for _, fn := range s.hooks {
+		fn(s, e)
+	}
+}
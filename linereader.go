@@ -0,0 +1,156 @@
+package pty
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrLineTimeout is returned by LineReader.ReadLine when no line is
+// completed within the timeout.
+var ErrLineTimeout = errors.New("timed out waiting for a line")
+
+// LineReader reads the output of a command, typically a Session, line by
+// line. Lines may end with \n, \r\n, or a bare \r as used to redraw
+// progress lines; the \r\r\n a pty makes of \r\n counts as one end of line.
+type LineReader struct {
+	r       io.Reader
+	timeout time.Duration
+	chunks  chan chunk
+	done    chan struct{} // Closed by Close.
+	once    sync.Once
+	buf     []byte
+	scanned int   // The length of buf known to hold no end of line.
+	err     error // Ending the output, once all chunks are consumed.
+}
+
+type chunk struct {
+	b   []byte
+	err error
+}
+
+// NewLineReader returns a LineReader reading from r, which it owns from
+// then on. Reads happen in the background, one chunk ahead, until the
+// output ends or Close is called.
+func NewLineReader(r io.Reader) *LineReader {
+	l := &LineReader{r: r, chunks: make(chan chunk, 1), done: make(chan struct{})}
+	go func() {
+		for {
+			b := make([]byte, chunkSize)
+			n, err := r.Read(b)
+			if err != nil && isEIO(err) {
+				err = io.EOF
+			}
+			select {
+			case l.chunks <- chunk{b: b[:n], err: err}:
+			case <-l.done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return l
+}
+
+// Close stops the background reads and closes the reader, if it is an
+// io.Closer. Otherwise, the read pending, if any, ends the background reads
+// once it returns. ReadLine returns os.ErrClosed from then on.
+func (l *LineReader) Close() error {
+	var err error
+	l.once.Do(func() {
+		close(l.done)
+		if c, ok := l.r.(io.Closer); ok {
+			err = c.Close()
+		}
+	})
+	return err
+}
+
+// SetTimeout sets how long ReadLine waits for a line to be completed. Zero,
+// the default, means no timeout.
+func (l *LineReader) SetTimeout(d time.Duration) {
+	l.timeout = d
+}
+
+// ReadLine returns the next line, without its end of line. At the end of
+// the output, it returns the last unterminated line if any, then io.EOF or
+// the error ending the output. It returns ErrLineTimeout if the line is not
+// completed within the timeout, or ctx.Err(), in which case the partial
+// line is kept for the next call.
+func (l *LineReader) ReadLine(ctx context.Context) (string, error) {
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		t := time.NewTimer(l.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	for {
+		select {
+		case <-l.done:
+			return "", os.ErrClosed
+		default:
+		}
+		if line, ok := l.line(); ok {
+			return line, nil
+		}
+		if l.err != nil {
+			if len(l.buf) > 0 {
+				line := string(bytes.TrimRight(l.buf, "\r"))
+				l.buf, l.scanned = nil, 0
+				return line, nil
+			}
+			return "", l.err
+		}
+		select {
+		case c := <-l.chunks:
+			l.buf = append(l.buf, c.b...)
+			l.err = c.err
+		case <-timeout:
+			return "", ErrLineTimeout
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-l.done:
+			return "", os.ErrClosed
+		}
+	}
+}
+
+// line consumes and returns the first complete line of buf, scanning it
+// from where the previous call stopped.
+func (l *LineReader) line() (string, bool) {
+	for i := l.scanned; i < len(l.buf); i++ {
+		switch l.buf[i] {
+		case '\n':
+			line := string(bytes.TrimRight(l.buf[:i], "\r"))
+			l.buf, l.scanned = l.buf[i+1:], 0
+			return line, true
+		case '\r':
+			// A bare \r ends a line, unless a \n follows, maybe after
+			// other \r, which requires the next byte to tell.
+			j := i
+			for j < len(l.buf) && l.buf[j] == '\r' {
+				j++
+			}
+			if j == len(l.buf) {
+				if l.err == nil {
+					l.scanned = i
+					return "", false
+				}
+				continue
+			}
+			if l.buf[j] != '\n' {
+				line := string(l.buf[:i])
+				l.buf, l.scanned = l.buf[j:], 0
+				return line, true
+			}
+		}
+	}
+	l.scanned = len(l.buf)
+	return "", false
+}
@@ -0,0 +1,62 @@
+package pty
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestLineReader(t *testing.T) {
+	t.Parallel()
+
+	l := NewLineReader(iotest.OneByteReader(strings.NewReader("a\r\nb\nc\r\r\n10%\r50%\rdone\r\n\nlast")))
+	ctx := context.Background()
+	for _, want := range []string{"a", "b", "c", "10%", "50%", "done", "", "last"} {
+		line, err := l.ReadLine(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error from ReadLine: %s", err)
+		}
+		if line != want {
+			t.Errorf("Unexpected line, got %q expected %q", line, want)
+		}
+	}
+	if _, err := l.ReadLine(ctx); err != io.EOF {
+		t.Errorf("Unexpected error from ReadLine, got %v expected %v", err, io.EOF)
+	}
+
+	r, w := io.Pipe()
+	defer func() { _ = w.Close() }()
+	l = NewLineReader(r)
+	l.SetTimeout(20 * time.Millisecond)
+	go func() { _, _ = w.Write([]byte("partial")) }()
+	if _, err := l.ReadLine(ctx); err != ErrLineTimeout {
+		t.Errorf("Unexpected error from ReadLine, got %v expected %v", err, ErrLineTimeout)
+	}
+	go func() { _, _ = w.Write([]byte(" line\n")) }()
+	if line, err := l.ReadLine(ctx); err != nil || line != "partial line" {
+		t.Errorf("Unexpected result from ReadLine, got %q, %v", line, err)
+	}
+}
+
+func TestLineReaderClose(t *testing.T) {
+	t.Parallel()
+
+	r, w := io.Pipe()
+	l := NewLineReader(r)
+	if err := l.Close(); err != nil {
+		t.Fatalf("Unexpected error from Close: %s", err)
+	}
+	if _, err := w.Write([]byte("line\n")); err != io.ErrClosedPipe {
+		t.Errorf("Unexpected error from Write after Close, got %v expected %v", err, io.ErrClosedPipe)
+	}
+	if _, err := l.ReadLine(context.Background()); err != os.ErrClosed {
+		t.Errorf("Unexpected error from ReadLine, got %v expected %v", err, os.ErrClosed)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("Unexpected error from the second Close: %s", err)
+	}
+}
@@ -0,0 +1,43 @@
+package pty
+
+import "sync/atomic"
+
+// Logger receives the non-fatal conditions the package otherwise swallows,
+// such as errors from best effort cleanups or fallback paths being taken.
+// *slog.Logger implements it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+type loggerHolder struct{ l Logger }
+
+var logger atomic.Value
+
+// SetLogger sets the Logger used by the package. A nil Logger, the
+// default, discards everything.
+func SetLogger(l Logger) {
+	logger.Store(loggerHolder{l: l})
+}
+
+func getLogger() Logger {
+	h, _ := logger.Load().(loggerHolder)
+	return h.l
+}
+
+// logDebug reports a noteworthy but expected condition.
+func logDebug(msg string, args ...interface{}) {
+	if l := getLogger(); l != nil {
+		l.Debug(msg, args...)
+	}
+}
+
+// logErr reports err, if any, from a best effort operation.
+func logErr(msg string, err error) {
+	if err == nil {
+		return
+	}
+	if l := getLogger(); l != nil {
+		l.Warn(msg, "err", err)
+	}
+}
@@ -0,0 +1,29 @@
+package pty
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type recordLogger struct{ logs []string }
+
+func (r *recordLogger) Debug(msg string, args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprintf("DEBUG %s %v", msg, args))
+}
+
+func (r *recordLogger) Warn(msg string, args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprintf("WARN %s %v", msg, args))
+}
+
+func TestLogErr(t *testing.T) {
+	l := &recordLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+
+	logErr("ok", nil)
+	logErr("failed", errors.New("boom"))
+	if len(l.logs) != 1 || l.logs[0] != "WARN failed [err boom]" {
+		t.Errorf("Unexpected logs, got %q", l.logs)
+	}
+}
@@ -0,0 +1,208 @@
+package pty
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionInfo describes a session tracked by a Manager.
+type SessionInfo struct {
+	ID      string
+	User    string   // As given to the Manager, for bookkeeping only.
+	Command []string // The arguments of the command.
+	Started time.Time
+	Size    Winsize // The current size, zero if it can not be read.
+}
+
+// ManagerEventKind is the kind of a ManagerEvent.
+type ManagerEventKind int
+
+// Manager event kinds.
+const (
+	SessionAdded   ManagerEventKind = iota // The session was added.
+	SessionExited                          // The command exited, Err tells how.
+	SessionRemoved                         // The session was removed.
+)
+
+func (k ManagerEventKind) String() string {
+	switch k {
+	case SessionAdded:
+		return "added"
+	case SessionExited:
+		return "exited"
+	case SessionRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// ManagerEvent is a change of a session tracked by a Manager.
+type ManagerEvent struct {
+	Kind ManagerEventKind
+	Info SessionInfo
+	Err  error // The result of Wait, for SessionExited.
+}
+
+// Manager tracks sessions by ID, for servers running many of them. It is
+// safe for concurrent use.
+type Manager struct {
+	mu       sync.Mutex
+	sessions map[string]*managed
+	watchers []*func(ManagerEvent)
+}
+
+type managed struct {
+	s    *Session
+	info SessionInfo
+}
+
+// NewManager returns an empty Manager.
+func NewManager() *Manager {
+	return &Manager{sessions: map[string]*managed{}}
+}
+
+// Add tracks s on behalf of user and returns its ID.
+func (m *Manager) Add(s *Session, user string) string {
+	id := newSessionID()
+	ms := &managed{s: s, info: SessionInfo{ID: id, User: user, Command: s.Cmd().Args, Started: time.Now()}}
+
+	m.mu.Lock()
+	m.sessions[id] = ms
+	m.mu.Unlock()
+	m.emit(ManagerEvent{Kind: SessionAdded, Info: ms.info})
+	return id
+}
+
+// Start starts c in a new session tracked on behalf of user, and returns
+// its ID. The Manager waits for the command: the session is removed once
+// it exited, but left open for the rest of its output to be read. Closing
+// it is then up to the caller.
+func (m *Manager) Start(c *exec.Cmd, user string, opts ...StartOption) (string, *Session, error) {
+	s, err := StartSession(c, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	id := m.Add(s, user)
+	go func() {
+		err := s.Wait()
+		info, _ := m.Info(id)
+		m.emit(ManagerEvent{Kind: SessionExited, Info: info, Err: err})
+		m.forget(id)
+	}()
+	return id, s, nil
+}
+
+// Get returns the session with the given ID, nil if there is none.
+func (m *Manager) Get(id string) *Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ms, ok := m.sessions[id]; ok {
+		return ms.s
+	}
+	return nil
+}
+
+// Info describes the session with the given ID.
+func (m *Manager) Info(id string) (SessionInfo, bool) {
+	m.mu.Lock()
+	ms, ok := m.sessions[id]
+	m.mu.Unlock()
+	if !ok {
+		return SessionInfo{ID: id}, false
+	}
+	return ms.describe(), true
+}
+
+func (ms *managed) describe() SessionInfo {
+	info := ms.info
+	if ws, err := GetsizeFull(ms.s.Pty()); err == nil {
+		info.Size = *ws
+	}
+	return info
+}
+
+// List describes the sessions tracked, oldest first.
+func (m *Manager) List() []SessionInfo {
+	m.mu.Lock()
+	all := make([]*managed, 0, len(m.sessions))
+	for _, ms := range m.sessions {
+		all = append(all, ms)
+	}
+	m.mu.Unlock()
+
+	infos := make([]SessionInfo, len(all))
+	for i, ms := range all {
+		infos[i] = ms.describe()
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Started.Before(infos[j].Started) })
+	return infos
+}
+
+// Remove stops tracking the session with the given ID and closes it.
+func (m *Manager) Remove(id string) {
+	if ms := m.forget(id); ms != nil {
+		logErr("pty: close session", ms.s.Close()) // Best effort.
+	}
+}
+
+// forget stops tracking the session with the given ID, leaving it open,
+// and returns it, nil if it was not tracked.
+func (m *Manager) forget(id string) *managed {
+	m.mu.Lock()
+	ms, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	m.emit(ManagerEvent{Kind: SessionRemoved, Info: ms.info})
+	return ms
+}
+
+// CloseAll removes and closes all the sessions.
+func (m *Manager) CloseAll() {
+	for _, info := range m.List() {
+		m.Remove(info.ID)
+	}
+}
+
+// Watch calls fn with the events of the sessions, synchronously, until
+// stop is called.
+func (m *Manager) Watch(fn func(ManagerEvent)) (stop func()) {
+	p := &fn
+	m.mu.Lock()
+	m.watchers = append(m.watchers[:len(m.watchers):len(m.watchers)], p)
+	m.mu.Unlock()
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, w := range m.watchers {
+			if w == p {
+				watchers := make([]*func(ManagerEvent), 0, len(m.watchers)-1)
+				m.watchers = append(append(watchers, m.watchers[:i]...), m.watchers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (m *Manager) emit(ev ManagerEvent) {
+	m.mu.Lock()
+	watchers := m.watchers
+	m.mu.Unlock()
+	for _, w := range watchers {
+		(*w)(ev)
+	}
+}
+
+func newSessionID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err) // crypto/rand does not fail on supported platforms.
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,91 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+	events := make(chan ManagerEvent, 16)
+	stop := m.Watch(func(ev ManagerEvent) { events <- ev })
+	defer stop()
+
+	id, _, err := m.Start(exec.Command("sh", "-c", "exit 2"), "alice")
+	if err != nil {
+		t.Fatalf("Unexpected error from Start: %s", err)
+	}
+	cat := startCat(t)
+	catID := m.Add(cat, "bob")
+	if m.Get(catID) != cat {
+		t.Error("Unexpected session from Get")
+	}
+	if err := cat.Resize(&Winsize{Rows: 12, Cols: 34}); err != nil {
+		t.Fatalf("Unexpected error from Resize: %s", err)
+	}
+
+	var kinds []ManagerEventKind
+	for len(kinds) < 3 {
+		select {
+		case ev := <-events:
+			if ev.Info.ID == id {
+				kinds = append(kinds, ev.Kind)
+			}
+			if ev.Kind == SessionExited && ev.Err == nil {
+				t.Error("Expected the exit status in the exited event")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timeout waiting for events, got %v", kinds)
+		}
+	}
+	if kinds[0] != SessionAdded || kinds[1] != SessionExited || kinds[2] != SessionRemoved {
+		t.Errorf("Unexpected events: %v", kinds)
+	}
+
+	list := m.List()
+	if len(list) != 1 || list[0].ID != catID || list[0].User != "bob" || list[0].Command[0] != "cat" || list[0].Size.Cols != 34 {
+		t.Errorf("Unexpected sessions listed: %+v", list)
+	}
+	m.CloseAll()
+	if len(m.List()) != 0 {
+		t.Error("Unexpected sessions after CloseAll")
+	}
+}
+
+func TestManagerExitedOutput(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+	removed := make(chan struct{})
+	stop := m.Watch(func(ev ManagerEvent) {
+		if ev.Kind == SessionRemoved {
+			close(removed)
+		}
+	})
+	defer stop()
+
+	_, s, err := m.Start(exec.Command("sh", "-c", "echo hello-tail"), "alice")
+	if err != nil {
+		t.Fatalf("Unexpected error from Start: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+	select {
+	case <-removed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for the session to be removed")
+	}
+
+	// The output is still there to be read once the command exited.
+	out, _ := ioutil.ReadAll(s) // Linux returns EIO once the child exits.
+	if !strings.Contains(string(out), "hello-tail") {
+		t.Errorf("Unexpected output, got %q expected %q", out, "hello-tail")
+	}
+}
@@ -0,0 +1,78 @@
+//go:build !windows && !js && !plan9 && !wasip1 && !aix
+// +build !windows,!js,!plan9,!wasip1,!aix
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var errNotPollable = errors.New("file not pollable")
+
+// madeBlocking holds the descriptors of the files SetNonblock put in
+// blocking mode, which the runtime polls, as they were non-blocking. They
+// map to the address of their file, to tell it from a later file reusing
+// the descriptor, without keeping it from being collected. Keyed by
+// descriptor, the entries of closed files are bounded, and replaced or
+// pruned once their descriptor is reused.
+var madeBlocking sync.Map // Of uintptr to uintptr.
+
+// SetNonblock sets or clears O_NONBLOCK on f, for event loops of their own
+// polling its descriptor, without going through f.Fd(), which puts it in
+// blocking mode for good.
+//
+// The runtime poller keeps handling the reads and writes of f, as those of
+// the ptys returned by Open, in either mode: they only lose their
+// deadlines while f is blocking. Files the runtime does not poll, such as
+// those made by os.NewFile from a blocking descriptor, can not be made
+// non-blocking, as their reads and writes would fail with EAGAIN: only the
+// files already non-blocking, as the runtime makes those it polls, or made
+// blocking by SetNonblock can.
+func SetNonblock(f *os.File, nonblocking bool) error {
+	sc, err := f.SyscallConn()
+	if err != nil {
+		return wrapErr(OpSetNonblock, f.Name(), err)
+	}
+	var serr error
+	if err := sc.Control(func(fd uintptr) {
+		if nonblocking {
+			flags, err := getfl(fd)
+			if err != nil {
+				serr = wrapErr(OpSetNonblock, f.Name(), err)
+				return
+			}
+			addr, ok := madeBlocking.Load(fd)
+			if ok && addr != fileAddr(f) {
+				madeBlocking.Delete(fd) // Of a file closed since.
+				ok = false
+			}
+			if !ok && flags&syscall.O_NONBLOCK == 0 {
+				serr = wrapErr(OpSetNonblock, f.Name(), errNotPollable)
+				return
+			}
+		}
+		if err := syscall.SetNonblock(int(fd), nonblocking); err != nil {
+			serr = wrapErr(OpSetNonblock, f.Name(), err)
+			return
+		}
+		// Within Control, so that f can not be closed and its descriptor
+		// reused meanwhile.
+		if nonblocking {
+			madeBlocking.Delete(fd)
+		} else {
+			madeBlocking.Store(fd, fileAddr(f))
+		}
+	}); err != nil {
+		return wrapErr(OpSetNonblock, f.Name(), os.ErrClosed) // Control only fails once f is closed.
+	}
+	return serr
+}
+
+// fileAddr returns the address of f, which does not keep it alive.
+func fileAddr(f *os.File) uintptr {
+	return uintptr(unsafe.Pointer(f)) //nolint:gosec // Only compared.
+}
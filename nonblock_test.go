@@ -0,0 +1,76 @@
+//go:build !windows && !js && !plan9 && !wasip1 && !aix
+// +build !windows,!js,!plan9,!wasip1,!aix
+
+package pty
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSetNonblock(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close(); _ = tty.Close() }()
+
+	for _, nb := range []bool{false, true} {
+		if err := SetNonblock(pty, nb); err != nil {
+			t.Fatalf("Unexpected error from SetNonblock(%v): %s", nb, err)
+		}
+		if _, err := tty.Write([]byte("x")); err != nil {
+			t.Fatalf("Unexpected error from Write: %s", err)
+		}
+		buf := make([]byte, 1)
+		if _, err := io.ReadFull(pty, buf); err != nil {
+			t.Errorf("Unexpected error from Read with nonblocking %v: %s", nb, err)
+		}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Unexpected error from Pipe: %s", err)
+	}
+	defer func() { _ = r.Close(); _ = w.Close() }()
+	fd, err := syscall.Dup(int(r.Fd())) // Fd makes r blocking.
+	if err != nil {
+		t.Fatalf("Unexpected error from Dup: %s", err)
+	}
+	blocking := os.NewFile(uintptr(fd), "blocking")
+	defer func() { _ = blocking.Close() }()
+	var perr *PtyError
+	if err := SetNonblock(blocking, true); !errors.As(err, &perr) || perr.Op != OpSetNonblock {
+		t.Errorf("Unexpected error from SetNonblock of a file the runtime does not poll: %v", err)
+	}
+	_ = blocking.Close()
+	if err := SetNonblock(blocking, true); !errors.As(err, &perr) || !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Unexpected error from SetNonblock of a closed file: %v", err)
+	}
+}
+
+func TestSetNonblockKeepsDeadline(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close(); _ = tty.Close() }()
+
+	if err := pty.SetReadDeadline(time.Now().Add(20 * time.Millisecond)); err != nil {
+		t.Fatalf("Unexpected error from SetReadDeadline: %s", err)
+	}
+	if err := SetNonblock(pty, true); err != nil {
+		t.Fatalf("Unexpected error from SetNonblock: %s", err)
+	}
+	if _, err := pty.Read(make([]byte, 1)); !os.IsTimeout(err) {
+		t.Errorf("Unexpected error from Read, got %v expected a timeout", err)
+	}
+}
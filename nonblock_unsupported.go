@@ -0,0 +1,11 @@
+//go:build windows || js || plan9 || wasip1 || aix
+// +build windows js plan9 wasip1 aix
+
+package pty
+
+import "os"
+
+// SetNonblock sets or clears O_NONBLOCK on f.
+func SetNonblock(*os.File, bool) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,213 @@
+package pty
+
+import "os"
+
+// StartOption configures how StartWithOptions starts a command.
+type StartOption func(*startOptions)
+
+type startOptions struct {
+	size       *Winsize
+	credential *credential
+	chroot     string
+	tracer     Tracer
+	hooks      []func(*Session, LifecycleEvent)
+	eioAsEOF   bool
+	doneError  bool
+	inLimit    *Throttle
+	outLimit   *Throttle
+	name       string
+	priority   *int
+	cgroup     string
+	seccomp    []byte
+	umask      *os.FileMode
+	dropCaps   []uintptr
+	ambient    []uintptr
+	modes      map[uint8]uint32
+	noCtty     bool
+	attrsAsIs  bool
+	stderrMode stderrMode
+	env        []string // KEY=value pairs set in the environment of the command.
+}
+
+type credential struct {
+	uid    uint32
+	gid    uint32
+	groups []uint32
+}
+
+// WithSize resizes the pty to ws before starting the command.
+func WithSize(ws *Winsize) StartOption {
+	return func(o *startOptions) { o.size = ws }
+}
+
+// WithTerminalModes applies modes to the tty before starting the command.
+// They are encoded as in SSH, see RFC 4254 section 8, the opcode of each
+// mode mapping to its value. Unknown modes are ignored.
+//
+// Only supported on Linux, macOS and the BSDs.
+func WithTerminalModes(modes map[uint8]uint32) StartOption {
+	return func(o *startOptions) { o.modes = modes }
+}
+
+// WithNoCtty starts the command in a new session without making the tty its
+// controlling terminal, for daemons and other commands which must not get
+// the signals of the terminal, such as SIGHUP when the pty is closed. The
+// tty is still its standard input and outputs, which fail once the pty is
+// closed, and the command is still a child of this process, to be waited
+// for, e.g. with Session.Done.
+//
+// Not supported on Windows.
+func WithNoCtty() StartOption {
+	return func(o *startOptions) { o.noCtty = true }
+}
+
+// WithPriority runs the command with the nice value nice, from -20, the
+// highest priority, to 19, the lowest, e.g. to deprioritize automation
+// shells relative to the service hosting them. Raising the priority
+// requires privileges. On Linux, it is set before the command starts. On
+// the other platforms, it is set right after, racing with the command: it
+// runs with the priority of this process until then, and the processes it
+// forks meanwhile keep that priority.
+//
+// Not supported on Windows.
+func WithPriority(nice int) StartOption {
+	return func(o *startOptions) { o.priority = &nice }
+}
+
+// WithCgroup starts the command in the cgroup v2 at path, a directory such
+// as /sys/fs/cgroup/sessions/42, so that the limits of the session apply
+// from its first instruction. With Go 1.20 and later, it is started right
+// into it, which requires Linux 5.7. With older Go or Linux, it is moved
+// into it right after starting.
+//
+// Only supported on Linux.
+func WithCgroup(path string) StartOption {
+	return func(o *startOptions) { o.cgroup = path }
+}
+
+// WithSeccomp runs the command under the seccomp filter, a classic BPF
+// program given as its sock_filter instructions in native byte order, as
+// exported by seccomp_export_bpf(3). The filter is installed, along with
+// no_new_privs, before the command starts, for restricted shells to need no
+// sandbox wrapper.
+//
+// The filter is installed on a thread of this process dedicated to
+// starting the command, and inherited by the command from it. That thread
+// ends right after, but it runs Go runtime code under the filter until
+// then: besides the system calls the command needs, the filter must allow
+// those to fork and exec, and those of the runtime, such as futex, mmap,
+// munmap, madvise, sigaltstack, rt_sigprocmask, rt_sigreturn, tgkill,
+// getpid, sched_yield, nanosleep, read, write, close and exit. Denying any
+// of them may kill or hang this process rather than the command. Stricter
+// filters must be installed in the command instead, e.g. by a program
+// started with StartSelf, which installs it and then executes the command.
+//
+// Only supported on Linux.
+func WithSeccomp(filter []byte) StartOption {
+	return func(o *startOptions) { o.seccomp = filter }
+}
+
+// WithUmask runs the command with the file mode creation mask mask, e.g.
+// 022 for interactive shells started by services running with 077,
+// instead of the mask of this process. The mask of this process is left
+// alone.
+//
+// Only supported on Linux.
+func WithUmask(mask os.FileMode) StartOption {
+	return func(o *startOptions) { o.umask = &mask }
+}
+
+// WithDroppedCapabilities drops the capabilities caps, e.g.
+// unix.CAP_SYS_ADMIN, from the bounding and inheritable sets of the
+// command, for root-run terminal servers to hand out shells which can not
+// gain them back. Dropping capabilities requires CAP_SETPCAP.
+//
+// Only supported on Linux.
+func WithDroppedCapabilities(caps ...uintptr) StartOption {
+	return func(o *startOptions) { o.dropCaps = append(o.dropCaps, caps...) }
+}
+
+// WithAmbientCaps raises the capabilities caps in the ambient set of the
+// command, for it to keep them without being root, e.g. with
+// WithCredential. They must be in the permitted set of this process.
+//
+// Only supported on Linux.
+func WithAmbientCaps(caps ...uintptr) StartOption {
+	return func(o *startOptions) { o.ambient = append(o.ambient, caps...) }
+}
+
+// WithSysProcAttrAsIs leaves Setsid and Setctty as set in c.SysProcAttr,
+// for callers managing the session of the command themselves. By default,
+// they are set on c.SysProcAttr, or on a new one if it is nil, and its
+// other fields are kept.
+//
+// Not supported on Windows.
+func WithSysProcAttrAsIs() StartOption {
+	return func(o *startOptions) { o.attrsAsIs = true }
+}
+
+// WithStderrPty connects the standard error of the command to a pty of its
+// own, so that it can be told apart from the standard output while both
+// are terminals. It overrides WithStderrPipe. The pty is sized as the main
+// one, and returned by Session.Stderr or StartWithStderr.
+//
+// Not supported on Windows.
+func WithStderrPty() StartOption {
+	return func(o *startOptions) { o.stderrMode = stderrPty }
+}
+
+// WithStderrPipe connects the standard error of the command to a pipe,
+// while its standard input and output stay on the tty, for tools capturing
+// diagnostics separately. It overrides WithStderrPty. The read end of the
+// pipe is returned by Session.Stderr or StartWithStderr.
+func WithStderrPipe() StartOption {
+	return func(o *startOptions) { o.stderrMode = stderrPipe }
+}
+
+// DefaultTerm is the TERM set by WithTerm when given none.
+const DefaultTerm = "xterm-256color"
+
+// WithTerm sets TERM to term in the environment of the command, or to
+// DefaultTerm if term is empty, e.g. when an SSH client sent none, so that
+// the command does not run with the TERM of the server, or TERM=dumb. The
+// optional env are more KEY=value pairs to set, such as
+// "COLORTERM=truecolor" or "TERM_PROGRAM=myterm". They override the
+// variables of c.Env, or of the current process if c.Env is nil.
+func WithTerm(term string, env ...string) StartOption {
+	if term == "" {
+		term = DefaultTerm
+	}
+	return func(o *startOptions) { o.env = append([]string{"TERM=" + term}, env...) }
+}
+
+// WithName labels the session, e.g. "session-42", for Session.Name to return
+// instead of the name of its pty, such as /dev/pts/3, which is reused by
+// later sessions once closed.
+func WithName(name string) StartOption {
+	return func(o *startOptions) { o.name = name }
+}
+
+// WithCredential runs the command as uid and gid with the given
+// supplementary groups. A nil groups clears the supplementary groups.
+// The tty is chowned to uid and gid so the command owns its terminal.
+//
+// Not supported on Windows.
+func WithCredential(uid, gid uint32, groups []uint32) StartOption {
+	return func(o *startOptions) { o.credential = &credential{uid: uid, gid: gid, groups: groups} }
+}
+
+// WithChroot changes the root directory of the command to dir before
+// executing it. c.Dir is resolved inside dir, but exec.Command looks c.Path
+// up in the PATH of the calling process, outside dir: use an absolute path,
+// such as "/bin/sh", naming the command inside dir.
+//
+// Not supported on Windows.
+func WithChroot(dir string) StartOption {
+	return func(o *startOptions) { o.chroot = dir }
+}
+
+// WithEIOAsEOF makes reading a Session report the EIO returned on Linux
+// once the command exited as io.EOF. See EOFReader.
+func WithEIOAsEOF() StartOption {
+	return func(o *startOptions) { o.eioAsEOF = true }
+}
@@ -0,0 +1,97 @@
+package pty
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// File is the interface implemented by both ends of a pty, *os.File, and
+// of a pipe-backed pair, *Pipe, for code working with either. The
+// deadlines are as those of net.Conn: they make pending and future reads
+// or writes fail with an error for which os.IsTimeout is true. Both ends
+// returned by Open support them on Linux. Where Open returns a descriptor
+// the runtime does not poll, as the pty on darwin or a tty opened with
+// TIOCGPTPEER, they return os.ErrNoDeadline.
+type File interface {
+	io.ReadWriteCloser
+	Name() string
+	SetDeadline(t time.Time) error
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+// Pipe is one end of a pipe-backed pair returned by OpenPipe.
+type Pipe struct {
+	name string
+	c    net.Conn  // An end of a net.Pipe, for its deadlines.
+	size *pipeSize // Shared by both ends.
+}
+
+type pipeSize struct {
+	mu sync.Mutex
+	ws Winsize
+}
+
+// OpenPipe returns a pty and its corresponding tty backed by in-memory
+// pipes instead of a terminal device. There is no line discipline: no echo
+// and no line editing, and the window size is only recorded. It is meant
+// as a degraded mode for platforms where Open returns ErrUnsupported, and
+// as a fake in unit tests.
+func OpenPipe() (pty, tty *Pipe) {
+	ptyC, ttyC := net.Pipe()
+	size := &pipeSize{ws: Winsize{Rows: 24, Cols: 80}}
+	return &Pipe{name: "pipe-pty", c: ptyC, size: size}, &Pipe{name: "pipe-tty", c: ttyC, size: size}
+}
+
+// Setsize sets the window size shared by both ends, initially 24 rows of
+// 80 columns.
+func (p *Pipe) Setsize(ws *Winsize) error {
+	p.size.mu.Lock()
+	defer p.size.mu.Unlock()
+	p.size.ws = *ws
+	return nil
+}
+
+// GetsizeFull returns the window size shared by both ends.
+func (p *Pipe) GetsizeFull() (*Winsize, error) {
+	p.size.mu.Lock()
+	defer p.size.mu.Unlock()
+	ws := p.size.ws
+	return &ws, nil
+}
+
+// Name returns the name of the pipe end, "pipe-pty" or "pipe-tty".
+func (p *Pipe) Name() string { return p.name }
+
+// Read reads what was written to the other end.
+func (p *Pipe) Read(b []byte) (int, error) { return p.c.Read(b) }
+
+// Write writes to the other end. It blocks until the data is read.
+func (p *Pipe) Write(b []byte) (int, error) { return p.c.Write(b) }
+
+// ReadFrom implements io.ReaderFrom. It writes everything read from r to the
+// other end using a pooled buffer.
+func (p *Pipe) ReadFrom(r io.Reader) (int64, error) {
+	return CopyPooled(p.c, r)
+}
+
+// WriteTo implements io.WriterTo. It writes everything written to the other
+// end to w using a pooled buffer, until the other end is closed.
+func (p *Pipe) WriteTo(w io.Writer) (int64, error) {
+	return CopyPooled(w, p.c)
+}
+
+// Close closes both directions. Reads from the other end then return io.EOF
+// and writes return io.ErrClosedPipe.
+func (p *Pipe) Close() error { return p.c.Close() }
+
+// SetDeadline sets the read and write deadlines.
+func (p *Pipe) SetDeadline(t time.Time) error { return p.c.SetDeadline(t) }
+
+// SetReadDeadline sets the deadline of pending and future reads.
+func (p *Pipe) SetReadDeadline(t time.Time) error { return p.c.SetReadDeadline(t) }
+
+// SetWriteDeadline sets the deadline of pending and future writes.
+func (p *Pipe) SetWriteDeadline(t time.Time) error { return p.c.SetWriteDeadline(t) }
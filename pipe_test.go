@@ -0,0 +1,102 @@
+package pty
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestOpenPipe(t *testing.T) {
+	t.Parallel()
+
+	pty, tty := OpenPipe()
+
+	text := []byte("ping")
+	go func() { _, _ = tty.Write(text) }()
+	buffer := make([]byte, len(text))
+	if err := readBytes(pty, buffer); err != nil {
+		t.Errorf("Unexpected error from readBytes: %s", err)
+	}
+	if !bytes.Equal(text, buffer) {
+		t.Errorf("Unexpected result returned from Read, got %v expected %v", buffer, text)
+	}
+
+	if err := pty.Close(); err != nil {
+		t.Errorf("Unexpected error from pty Close: %s", err)
+	}
+	if _, err := tty.Read(buffer); err != io.EOF {
+		t.Errorf("Unexpected error from tty Read after pty Close, got %v expected %v", err, io.EOF)
+	}
+	if _, err := tty.Write(text); err != io.ErrClosedPipe {
+		t.Errorf("Unexpected error from tty Write after pty Close, got %v expected %v", err, io.ErrClosedPipe)
+	}
+}
+
+func TestPipeReadFromWriteTo(t *testing.T) {
+	t.Parallel()
+
+	pty, tty := OpenPipe()
+
+	text := bytes.Repeat([]byte("ping"), 1<<14)
+	go func() {
+		_, _ = tty.ReadFrom(bytes.NewReader(text))
+		_ = tty.Close()
+	}()
+
+	var out bytes.Buffer
+	n, err := pty.WriteTo(&out)
+	if err != nil {
+		t.Errorf("Unexpected error from WriteTo: %s", err)
+	}
+	if n != int64(len(text)) {
+		t.Errorf("Unexpected count returned from WriteTo, got %d expected %d", n, len(text))
+	}
+	if !bytes.Equal(text, out.Bytes()) {
+		t.Error("Unexpected result returned from WriteTo")
+	}
+}
+
+func TestPipeSize(t *testing.T) {
+	t.Parallel()
+
+	pty, tty := OpenPipe()
+	if err := pty.Setsize(&Winsize{Rows: 40, Cols: 120}); err != nil {
+		t.Fatalf("Unexpected error from Setsize: %s", err)
+	}
+	ws, err := tty.GetsizeFull()
+	if err != nil {
+		t.Fatalf("Unexpected error from GetsizeFull: %s", err)
+	}
+	if ws.Rows != 40 || ws.Cols != 120 {
+		t.Errorf("Unexpected size, got %dx%d expected 120x40", ws.Cols, ws.Rows)
+	}
+}
+
+var (
+	_ File = (*os.File)(nil)
+	_ File = (*Pipe)(nil)
+	_ File = (*Stream)(nil)
+)
+
+func TestPipeDeadline(t *testing.T) {
+	t.Parallel()
+
+	pty, tty := OpenPipe()
+	defer func() { _ = pty.Close(); _ = tty.Close() }()
+
+	if err := pty.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatalf("Unexpected error from SetReadDeadline: %s", err)
+	}
+	if _, err := pty.Read(make([]byte, 1)); !os.IsTimeout(err) {
+		t.Errorf("Unexpected error from Read past the deadline, got %v expected a timeout", err)
+	}
+	if err := pty.SetReadDeadline(time.Time{}); err != nil {
+		t.Fatalf("Unexpected error from SetReadDeadline: %s", err)
+	}
+	go func() { _, _ = tty.Write([]byte("x")) }()
+	if err := readBytes(pty, make([]byte, 1)); err != nil {
+		t.Errorf("Unexpected error from Read after clearing the deadline: %s", err)
+	}
+}
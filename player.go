@@ -0,0 +1,210 @@
+package pty
+
+import (
+	"context"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PlayerOption configures a Player.
+type PlayerOption func(*Player)
+
+// WithPlaySpeed plays recordings speed times faster. Defaults to 1.
+func WithPlaySpeed(speed float64) PlayerOption {
+	return func(p *Player) {
+		if speed > 0 {
+			p.speed = speed
+		}
+	}
+}
+
+// PlayInput plays the input events of the recording instead of the output
+// ones, to feed them to a command. See Replay.
+func PlayInput() PlayerOption {
+	return func(p *Player) { p.kind = EventInput }
+}
+
+// WithPlayMaxIdle shortens the pauses of a recording to at most d, skipping
+// over idle periods. Zero, the default, keeps the pauses as recorded.
+func WithPlayMaxIdle(d time.Duration) PlayerOption {
+	return func(p *Player) { p.maxIdle = d }
+}
+
+// Player plays back the output of a Recording to a writer, honoring its
+// timing. Resizes are applied when the writer is a pty or has a
+// Resize(*Winsize) error method, such as a Session. Input events are
+// ignored, unless PlayInput is used. Pause, Resume and Seek may be called
+// while playing.
+type Player struct {
+	rec     *Recording
+	w       io.Writer
+	speed   float64
+	maxIdle time.Duration
+	kind    EventKind // Of the events written.
+
+	mu     sync.Mutex
+	pos    int           // Index of the next event to play.
+	at     time.Duration // Position in the recording.
+	seekTo int           // Index of the event to seek to, -1 if none.
+	seekAt time.Duration
+	paused bool
+	wake   chan struct{}
+}
+
+// NewPlayer returns a Player playing rec to w.
+func NewPlayer(rec *Recording, w io.Writer, opts ...PlayerOption) *Player {
+	p := &Player{rec: rec, w: w, speed: 1, seekTo: -1, wake: make(chan struct{}, 1)}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Play plays the recording from the current position until its end, ctx is
+// done or writing fails. Playing an ended recording again requires seeking
+// back.
+func (p *Player) Play(ctx context.Context) error {
+	p.mu.Lock()
+	start := p.pos == 0 && p.seekTo == -1
+	p.mu.Unlock()
+	if start && p.rec.Size != nil {
+		if err := p.resize(p.rec.Size); err != nil {
+			return err
+		}
+	}
+
+	for {
+		p.mu.Lock()
+		if p.seekTo >= 0 {
+			from, to := p.pos, p.seekTo
+			if to < from {
+				from = 0 // Replay from the start to rebuild the screen.
+			}
+			p.pos, p.at, p.seekTo = to, p.seekAt, -1
+			p.mu.Unlock()
+			for _, ev := range p.rec.Events[from:to] {
+				if err := p.play(ev); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if p.paused {
+			p.mu.Unlock()
+			select {
+			case <-p.wake:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+		if p.pos >= len(p.rec.Events) {
+			p.mu.Unlock()
+			return nil
+		}
+		pos, at := p.pos, p.at
+		p.mu.Unlock()
+
+		t := time.NewTimer(p.delay(p.rec.Events[pos].Time - at))
+		select {
+		case <-t.C:
+		case <-p.wake:
+			t.Stop()
+			continue
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+
+		p.mu.Lock()
+		if p.pos != pos || p.seekTo >= 0 || p.paused {
+			p.mu.Unlock()
+			continue
+		}
+		p.pos++
+		p.at = p.rec.Events[pos].Time
+		p.mu.Unlock()
+		if err := p.play(p.rec.Events[pos]); err != nil {
+			return err
+		}
+	}
+}
+
+// delay returns how long to wait for d of the recording.
+func (p *Player) delay(d time.Duration) time.Duration {
+	if p.maxIdle > 0 && d > p.maxIdle {
+		d = p.maxIdle
+	}
+	return time.Duration(float64(d) / p.speed)
+}
+
+func (p *Player) play(ev Event) error {
+	switch ev.Kind {
+	case p.kind:
+		_, err := p.w.Write(ev.Data)
+		return err
+	case EventResize:
+		return p.resize(ev.Size)
+	}
+	return nil
+}
+
+type resizer interface {
+	Resize(ws *Winsize) error
+}
+
+func (p *Player) resize(ws *Winsize) error {
+	switch w := p.w.(type) {
+	case resizer:
+		return w.Resize(ws)
+	case *os.File:
+		return Setsize(w, ws)
+	}
+	return nil
+}
+
+// Pause pauses the playback.
+func (p *Player) Pause() {
+	p.mu.Lock()
+	p.paused = true
+	p.mu.Unlock()
+	p.signal()
+}
+
+// Resume resumes a paused playback. The pause in progress when the
+// playback was paused starts over.
+func (p *Player) Resume() {
+	p.mu.Lock()
+	p.paused = false
+	p.mu.Unlock()
+	p.signal()
+}
+
+// Seek moves the playback to t in the recording. The output between the
+// current position and t is written at once, or the output since the start
+// of the recording when seeking backward, so the screen catches up.
+func (p *Player) Seek(t time.Duration) {
+	events := p.rec.Events
+	i := sort.Search(len(events), func(i int) bool { return events[i].Time >= t })
+	p.mu.Lock()
+	p.seekTo, p.seekAt = i, t
+	p.mu.Unlock()
+	p.signal()
+}
+
+// Position returns the position of the playback in the recording.
+func (p *Player) Position() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.at
+}
+
+func (p *Player) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
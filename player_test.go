@@ -0,0 +1,123 @@
+package pty
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type resizeRecorder struct {
+	bytes.Buffer
+	sizes []Winsize
+}
+
+func (r *resizeRecorder) Resize(ws *Winsize) error {
+	r.sizes = append(r.sizes, *ws)
+	return nil
+}
+
+func TestPlayer(t *testing.T) {
+	t.Parallel()
+
+	rec, err := LoadAsciicast(strings.NewReader(`{"version": 2, "width": 80, "height": 24}
+[0.1, "o", "hello "]
+[0.2, "i", "ignored"]
+[0.3, "r", "100x30"]
+[10.4, "o", "world"]
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error from LoadAsciicast: %s", err)
+	}
+
+	w := &resizeRecorder{}
+	p := NewPlayer(rec, w, WithPlaySpeed(2), WithPlayMaxIdle(100*time.Millisecond))
+	start := time.Now()
+	if err := p.Play(context.Background()); err != nil {
+		t.Fatalf("Unexpected error from Play: %s", err)
+	}
+	if d := time.Since(start); d < 150*time.Millisecond || d > 2*time.Second {
+		t.Errorf("Unexpected playback duration: %s", d)
+	}
+	if w.String() != "hello world" {
+		t.Errorf("Unexpected output played, got %q", w.String())
+	}
+	if len(w.sizes) != 2 || w.sizes[0] != (Winsize{Rows: 24, Cols: 80}) || w.sizes[1] != (Winsize{Rows: 30, Cols: 100}) {
+		t.Errorf("Unexpected resizes played, got %v", w.sizes)
+	}
+	if p.Position() != 10400*time.Millisecond {
+		t.Errorf("Unexpected position, got %s", p.Position())
+	}
+}
+
+func TestPlayerSeek(t *testing.T) {
+	t.Parallel()
+
+	var ts, timing bytes.Buffer
+	ts.WriteString("Script started on 2024-01-01 00:00:00+00:00 [COLUMNS=\"80\" LINES=\"24\"]\nabc")
+	timing.WriteString("0.05 1\n3600 1\n0.05 1\n")
+	rec, err := LoadTypescript(&ts, &timing)
+	if err != nil {
+		t.Fatalf("Unexpected error from LoadTypescript: %s", err)
+	}
+
+	var out bytes.Buffer
+	p := NewPlayer(rec, &out)
+	p.Seek(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Play(ctx); err != nil {
+		t.Fatalf("Unexpected error from Play: %s", err)
+	}
+	if out.String() != "abc" {
+		t.Errorf("Unexpected output played, got %q", out.String())
+	}
+}
+
+func TestLoadTtyrecMalformed(t *testing.T) {
+	t.Parallel()
+
+	// A header announcing 4 GiB of data.
+	hdr := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}
+	if _, err := LoadTtyrec(bytes.NewReader(hdr)); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Unexpected error from LoadTtyrec, got %v expected a too large frame", err)
+	}
+	if _, err := LoadTtyrec(bytes.NewReader(append(hdr[:8:8], 4, 0, 0, 0, 'h'))); err != io.ErrUnexpectedEOF {
+		t.Errorf("Unexpected error from LoadTtyrec, got %v expected %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestLoadTypescriptMalformed(t *testing.T) {
+	t.Parallel()
+
+	ts := strings.NewReader("Script started\nhi")
+	if _, err := LoadTypescript(ts, strings.NewReader("0.1 134217728\n")); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Unexpected error from LoadTypescript, got %v expected a too large chunk", err)
+	}
+}
+
+func TestCaptureReaderError(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read failed")
+	frame := append([]byte(captureMagic), byte(EventOutput), 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 'h')
+	for _, r := range []io.Reader{
+		&errAfter{err: errRead}, // In the magic.
+		io.MultiReader(bytes.NewReader(frame), &errAfter{err: errRead}), // In a payload.
+	} {
+		if _, err := NewCaptureReader(r).Next(); err != errRead {
+			t.Errorf("Unexpected error from Next, got %v expected %v", err, errRead)
+		}
+	}
+	if _, err := NewCaptureReader(bytes.NewReader(frame)).Next(); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("Unexpected error from Next, got %v expected a truncated capture", err)
+	}
+}
+
+// errAfter fails its reads with err.
+type errAfter struct{ err error }
+
+func (r *errAfter) Read([]byte) (int, error) { return 0, r.err }
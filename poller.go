@@ -0,0 +1,184 @@
+package pty
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// errPollerRegistered is returned by Poller.Add for a pty already registered.
+var errPollerRegistered = errors.New("pty already registered with the poller")
+
+// Poller waits for many ptys to become readable and dispatches the events to
+// a fixed number of worker goroutines, so a server with thousands of sessions
+// doesn't need a blocked reader goroutine for each of them.
+//
+// It uses epoll on Linux and kqueue on Darwin and the BSDs. NewPoller returns
+// ErrUnsupported elsewhere.
+type Poller struct {
+	mu      sync.Mutex
+	entries map[int]*pollEntry
+	byPty   map[*os.File]*pollEntry
+	closed  bool
+
+	ready chan *pollEntry
+	wg    sync.WaitGroup
+
+	pfd  int    // epoll or kqueue descriptor.
+	wake [2]int // Pipe used to interrupt the wait on Close.
+}
+
+type pollEntry struct {
+	pty    *os.File
+	fd     int
+	fn     func(pty *os.File)
+	hangup bool // Reported hung up, not to be re-armed. Guarded by mu.
+}
+
+// pollEvent is a descriptor reported readable by wait.
+type pollEvent struct {
+	fd     int
+	hangup bool // The other end hung up or the descriptor failed.
+}
+
+// NewPoller starts a Poller dispatching events to the given number of
+// workers. A value of zero or less uses one worker per CPU.
+func NewPoller(workers int) (*Poller, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	p := &Poller{
+		entries: map[int]*pollEntry{},
+		byPty:   map[*os.File]*pollEntry{},
+		ready:   make(chan *pollEntry, workers),
+	}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	p.wg.Add(workers + 1)
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	go p.loop()
+	return p, nil
+}
+
+// Add registers pty with the poller. fn is called from a worker whenever pty
+// is readable, and is expected to read from it. The pty is not reported again
+// until fn returns. Once its tty hung up or it failed, it is reported a last
+// time, fn then being expected to read it until the EOF or EIO, and no
+// more. pty should be removed from the poller before being closed.
+func (p *Poller) Add(pty *os.File, fn func(pty *os.File)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return os.ErrClosed
+	}
+	if _, ok := p.byPty[pty]; ok {
+		return errPollerRegistered
+	}
+
+	// Register the descriptor while holding a reference on pty, so it
+	// cannot be closed and recycled meanwhile.
+	var aerr error
+	if err := controlFd(pty, func(fd int) {
+		if e, ok := p.entries[fd]; ok {
+			// The descriptor was recycled after a pty was closed without
+			// being removed. The kernel already forgot about it.
+			delete(p.byPty, e.pty)
+			delete(p.entries, fd)
+		}
+		if aerr = p.add(fd); aerr != nil {
+			return
+		}
+		e := &pollEntry{pty: pty, fd: fd, fn: fn}
+		p.entries[fd] = e
+		p.byPty[pty] = e
+	}); err != nil {
+		return err
+	}
+	return aerr
+}
+
+// Remove unregisters pty from the poller. A handler already running for
+// pty is not interrupted.
+func (p *Poller) Remove(pty *os.File) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return os.ErrClosed
+	}
+	e, ok := p.byPty[pty]
+	if !ok {
+		return nil
+	}
+	delete(p.byPty, pty)
+	delete(p.entries, e.fd)
+
+	// Once pty is closed, its descriptor may have been recycled: only
+	// touch it while holding a reference on pty.
+	var derr error
+	if err := controlFd(pty, func(fd int) { derr = p.del(fd) }); err != nil {
+		return nil // Closed, the kernel already forgot about it.
+	}
+	return derr
+}
+
+// Close stops the poller and waits for running handlers to return. It must
+// not be called from a handler. The registered ptys are left open.
+func (p *Poller) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return os.ErrClosed
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	p.interrupt()
+	p.wg.Wait()
+	return p.release()
+}
+
+func (p *Poller) loop() {
+	defer p.wg.Done()
+	defer close(p.ready)
+
+	for {
+		evs, err := p.wait()
+		if err != nil {
+			return // Closed.
+		}
+		for _, ev := range evs {
+			p.mu.Lock()
+			e := p.entries[ev.fd]
+			if e != nil && ev.hangup {
+				e.hangup = true
+			}
+			p.mu.Unlock()
+			if e != nil {
+				p.ready <- e
+			}
+		}
+	}
+}
+
+func (p *Poller) worker() {
+	defer p.wg.Done()
+
+	for e := range p.ready {
+		e.fn(e.pty)
+
+		p.mu.Lock()
+		// Re-arming a hung up pty would report it again right away, for
+		// good.
+		if !p.closed && p.entries[e.fd] == e && !e.hangup {
+			// Closed without being removed otherwise: nothing to re-arm.
+			_ = controlFd(e.pty, func(fd int) {
+				logErr("pty: rearm poller", p.rearm(fd)) // Best effort.
+			})
+		}
+		p.mu.Unlock()
+	}
+}
@@ -0,0 +1,66 @@
+//go:build linux && go1.12
+// +build linux,go1.12
+
+package pty
+
+import (
+	"os"
+	"syscall"
+)
+
+func (p *Poller) init() error {
+	pfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return os.NewSyscallError("epoll_create1", err)
+	}
+	if err := syscall.Pipe2(p.wake[:], syscall.O_CLOEXEC|syscall.O_NONBLOCK); err != nil {
+		_ = syscall.Close(pfd) // Best effort.
+		return os.NewSyscallError("pipe2", err)
+	}
+	p.pfd = pfd
+	ev := syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(p.wake[0])}
+	if err := syscall.EpollCtl(p.pfd, syscall.EPOLL_CTL_ADD, p.wake[0], &ev); err != nil {
+		_ = p.release() // Best effort.
+		return os.NewSyscallError("epoll_ctl", err)
+	}
+	return nil
+}
+
+func (p *Poller) ctl(op, fd int) error {
+	ev := syscall.EpollEvent{Events: syscall.EPOLLIN | syscall.EPOLLONESHOT, Fd: int32(fd)}
+	return os.NewSyscallError("epoll_ctl", syscall.EpollCtl(p.pfd, op, fd, &ev))
+}
+
+func (p *Poller) add(fd int) error   { return p.ctl(syscall.EPOLL_CTL_ADD, fd) }
+func (p *Poller) rearm(fd int) error { return p.ctl(syscall.EPOLL_CTL_MOD, fd) }
+func (p *Poller) del(fd int) error   { return p.ctl(syscall.EPOLL_CTL_DEL, fd) }
+
+func (p *Poller) wait() ([]pollEvent, error) {
+	var events [128]syscall.EpollEvent
+	var n int
+	if err := ignoringEINTR(func() (err error) {
+		n, err = syscall.EpollWait(p.pfd, events[:], -1)
+		return err
+	}); err != nil {
+		return nil, os.NewSyscallError("epoll_wait", err)
+	}
+
+	evs := make([]pollEvent, 0, n)
+	for _, ev := range events[:n] {
+		if int(ev.Fd) == p.wake[0] {
+			return nil, os.ErrClosed
+		}
+		evs = append(evs, pollEvent{fd: int(ev.Fd), hangup: ev.Events&(syscall.EPOLLHUP|syscall.EPOLLERR) != 0})
+	}
+	return evs, nil
+}
+
+func (p *Poller) interrupt() {
+	_, _ = syscall.Write(p.wake[1], []byte{0}) // Best effort.
+}
+
+func (p *Poller) release() error {
+	_ = syscall.Close(p.wake[0]) // Best effort.
+	_ = syscall.Close(p.wake[1]) // Best effort.
+	return syscall.Close(p.pfd)
+}
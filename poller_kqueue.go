@@ -0,0 +1,80 @@
+//go:build (darwin || dragonfly || freebsd || netbsd || openbsd) && go1.12
+// +build darwin dragonfly freebsd netbsd openbsd
+// +build go1.12
+
+package pty
+
+import (
+	"os"
+	"syscall"
+)
+
+func (p *Poller) init() error {
+	pfd, err := syscall.Kqueue()
+	if err != nil {
+		return os.NewSyscallError("kqueue", err)
+	}
+	syscall.CloseOnExec(pfd)
+	if err := syscall.Pipe(p.wake[:]); err != nil {
+		_ = syscall.Close(pfd) // Best effort.
+		return os.NewSyscallError("pipe", err)
+	}
+	syscall.CloseOnExec(p.wake[0])
+	syscall.CloseOnExec(p.wake[1])
+	p.pfd = pfd
+	if err := p.ctl(p.wake[0], syscall.EV_ADD); err != nil {
+		_ = p.release() // Best effort.
+		return err
+	}
+	return nil
+}
+
+func (p *Poller) ctl(fd, flags int) error {
+	var ev [1]syscall.Kevent_t
+	syscall.SetKevent(&ev[0], fd, syscall.EVFILT_READ, flags)
+	return os.NewSyscallError("kevent", ignoringEINTR(func() error {
+		_, err := syscall.Kevent(p.pfd, ev[:], nil, nil)
+		return err
+	}))
+}
+
+func (p *Poller) add(fd int) error   { return p.ctl(fd, syscall.EV_ADD|syscall.EV_ONESHOT) }
+func (p *Poller) rearm(fd int) error { return p.add(fd) }
+
+func (p *Poller) del(fd int) error {
+	// A oneshot event that already fired is gone from the kqueue.
+	if err := p.ctl(fd, syscall.EV_DELETE); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (p *Poller) wait() ([]pollEvent, error) {
+	var events [128]syscall.Kevent_t
+	var n int
+	if err := ignoringEINTR(func() (err error) {
+		n, err = syscall.Kevent(p.pfd, nil, events[:], nil)
+		return err
+	}); err != nil {
+		return nil, os.NewSyscallError("kevent", err)
+	}
+
+	evs := make([]pollEvent, 0, n)
+	for _, ev := range events[:n] {
+		if int(ev.Ident) == p.wake[0] {
+			return nil, os.ErrClosed
+		}
+		evs = append(evs, pollEvent{fd: int(ev.Ident), hangup: ev.Flags&(syscall.EV_EOF|syscall.EV_ERROR) != 0})
+	}
+	return evs, nil
+}
+
+func (p *Poller) interrupt() {
+	_, _ = syscall.Write(p.wake[1], []byte{0}) // Best effort.
+}
+
+func (p *Poller) release() error {
+	_ = syscall.Close(p.wake[0]) // Best effort.
+	_ = syscall.Close(p.wake[1]) // Best effort.
+	return syscall.Close(p.pfd)
+}
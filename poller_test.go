@@ -0,0 +1,143 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoller(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPoller(2)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewPoller: %s", err)
+	}
+
+	const n = 16
+	var (
+		mu       sync.Mutex
+		received = map[*os.File]string{}
+		done     = make(chan struct{}, n)
+	)
+	ttys := make([]*os.File, 0, n)
+	for i := 0; i < n; i++ {
+		pty, tty, err := Open()
+		if err != nil {
+			t.Fatalf("Unexpected error from Open: %s", err)
+		}
+		defer func() { _ = pty.Close() }()
+		defer func() { _ = tty.Close() }()
+		ttys = append(ttys, tty)
+
+		if err := p.Add(pty, func(pty *os.File) {
+			buf := make([]byte, 16)
+			n, _ := pty.Read(buf)
+			mu.Lock()
+			received[pty] += string(buf[:n])
+			mu.Unlock()
+			done <- struct{}{}
+		}); err != nil {
+			t.Fatalf("Unexpected error from Add: %s", err)
+		}
+		if err := p.Add(pty, nil); err == nil {
+			t.Error("Expected an error when adding a pty twice")
+		}
+	}
+
+	for _, tty := range ttys {
+		if _, err := tty.Write([]byte("ping")); err != nil {
+			t.Fatalf("Unexpected error from Write: %s", err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the poller")
+		}
+	}
+
+	mu.Lock()
+	if len(received) != n {
+		t.Errorf("Unexpected number of ptys reported, got %d expected %d", len(received), n)
+	}
+	for pty, s := range received {
+		if s != "ping" {
+			t.Errorf("Unexpected data read from %s, got %q expected %q", pty.Name(), s, "ping")
+		}
+	}
+	mu.Unlock()
+
+	if err := p.Close(); err != nil {
+		t.Errorf("Unexpected error from Close: %s", err)
+	}
+}
+
+func TestPollerRemoveAfterClose(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPoller(1)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewPoller: %s", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	if err := p.Add(pty, func(*os.File) {}); err != nil {
+		t.Fatalf("Unexpected error from Add: %s", err)
+	}
+	_ = pty.Close()
+	if err := p.Remove(pty); err != nil {
+		t.Errorf("Unexpected error from Remove: %s", err)
+	}
+	if err := p.Add(pty, nil); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Unexpected error from Add, got %v expected %v", err, os.ErrClosed)
+	}
+}
+
+func TestPollerHangup(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPoller(1)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewPoller: %s", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+
+	var calls int32
+	if err := p.Add(pty, func(pty *os.File) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = pty.Read(make([]byte, 16)) // EIO on Linux, EOF elsewhere.
+	}); err != nil {
+		t.Fatalf("Unexpected error from Add: %s", err)
+	}
+	_ = tty.Close()
+
+	// A hung up pty stays readable: re-armed, it would be reported again
+	// and again.
+	time.Sleep(100 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Unexpected number of calls after the hangup, got %d expected 1", n)
+	}
+	if err := p.Remove(pty); err != nil {
+		t.Errorf("Unexpected error from Remove: %s", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build (!linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd) || !go1.12
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd !go1.12
+
+package pty
+
+func (p *Poller) init() error                { return ErrUnsupported }
+func (p *Poller) add(int) error              { return ErrUnsupported }
+func (p *Poller) rearm(int) error            { return ErrUnsupported }
+func (p *Poller) del(int) error              { return ErrUnsupported }
+func (p *Poller) wait() ([]pollEvent, error) { return nil, ErrUnsupported }
+func (p *Poller) interrupt()                 {}
+func (p *Poller) release() error             { return ErrUnsupported }
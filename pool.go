@@ -0,0 +1,174 @@
+package pty
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+var errPoolClosed = errors.New("pool closed")
+
+// poolRetry is the delay before retrying to fill a Pool after a failure.
+const poolRetry = time.Second
+
+// PoolOption configures a Pool.
+type PoolOption func(*Pool)
+
+// WithPrewarm makes the Pool keep sessions running the commands returned by
+// newCmd, started with opts, for Session to hand out, instead of pty and
+// tty pairs.
+func WithPrewarm(newCmd func() *exec.Cmd, opts ...StartOption) PoolOption {
+	return func(p *Pool) {
+		p.newCmd, p.opts = newCmd, opts
+	}
+}
+
+// Pool keeps ptys opened, or sessions started, ahead of time, to hand them
+// out without the latency of opening a pty and starting a shell. It is
+// refilled in the background. It is safe for concurrent use.
+type Pool struct {
+	newCmd func() *exec.Cmd
+	opts   []StartOption
+
+	items  chan poolItem
+	closed chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+type poolItem struct {
+	pty, tty *os.File
+	s        *Session
+}
+
+// NewPool returns a Pool keeping size ptys or sessions ready.
+func NewPool(size int, opts ...PoolOption) *Pool {
+	p := &Pool{items: make(chan poolItem, size), closed: make(chan struct{})}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.wg.Add(1)
+	go p.fill()
+	return p
+}
+
+// fill keeps the pool full until it is closed.
+func (p *Pool) fill() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.closed:
+			return
+		default:
+		}
+		it, err := p.create()
+		if errors.Is(err, ErrUnsupported) {
+			return
+		}
+		if err != nil {
+			logErr("pty: fill pool", err)
+			select {
+			case <-p.closed:
+				return
+			case <-time.After(poolRetry):
+			}
+			continue
+		}
+		select {
+		case p.items <- it:
+		case <-p.closed:
+			it.close()
+			return
+		}
+	}
+}
+
+func (p *Pool) create() (poolItem, error) {
+	if p.newCmd != nil {
+		s, err := StartSession(p.newCmd(), p.opts...)
+		return poolItem{s: s}, err
+	}
+	pty, tty, err := Open()
+	return poolItem{pty: pty, tty: tty}, err
+}
+
+// healthy reports whether it can still be handed out: its pty is open, and
+// its command, if any, is alive. An exited command which was not waited
+// for goes unnoticed outside Linux, see processAlive.
+func (it poolItem) healthy() bool {
+	if it.s != nil {
+		if _, err := GetsizeFull(it.s.Pty()); err != nil {
+			return false
+		}
+		return processAlive(it.s.Cmd().Process)
+	}
+	_, err := GetsizeFull(it.tty)
+	return err == nil
+}
+
+func (it poolItem) close() {
+	if it.s != nil {
+		logErr("pty: close session", it.s.Close()) // Best effort.
+		_ = it.s.Cmd().Process.Kill()
+		_ = it.s.Wait()
+		return
+	}
+	logErr("pty: close pty", it.pty.Close()) // Best effort.
+	logErr("pty: close tty", it.tty.Close()) // Best effort.
+}
+
+// get returns a ready item, or creates one if there is none.
+func (p *Pool) get() (poolItem, error) {
+	for {
+		select {
+		case <-p.closed:
+			return poolItem{}, errPoolClosed
+		case it := <-p.items:
+			if it.healthy() {
+				return it, nil
+			}
+			it.close()
+		default:
+			return p.create()
+		}
+	}
+}
+
+// Open returns a pty and tty pair, as the package level Open does. It
+// fails if the pool prewarms sessions.
+func (p *Pool) Open() (pty, tty *os.File, err error) {
+	if p.newCmd != nil {
+		return nil, nil, errors.New("pool of sessions")
+	}
+	it, err := p.get()
+	return it.pty, it.tty, err
+}
+
+// Session returns a running session, started with the options given to
+// WithPrewarm. It fails unless the pool prewarms sessions.
+func (p *Pool) Session() (*Session, error) {
+	if p.newCmd == nil {
+		return nil, errors.New("pool of ptys")
+	}
+	it, err := p.get()
+	return it.s, err
+}
+
+// Close closes the ptys and sessions left in the pool.
+func (p *Pool) Close() error {
+	p.once.Do(func() {
+		close(p.closed)
+		p.wg.Wait()
+		for {
+			select {
+			case it := <-p.items:
+				it.close()
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,51 @@
+package pty
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	p := NewPool(2)
+	defer func() { _ = p.Close() }()
+	for i := 0; i < 4; i++ {
+		pty, tty, err := p.Open()
+		if err != nil {
+			t.Fatalf("Unexpected error from Open: %s", err)
+		}
+		_ = tty.Close()
+		_ = pty.Close()
+	}
+	if _, err := p.Session(); err == nil {
+		t.Error("Expected an error from Session without WithPrewarm")
+	}
+}
+
+func TestPoolPrewarm(t *testing.T) {
+	t.Parallel()
+
+	p := NewPool(1, WithPrewarm(func() *exec.Cmd { return exec.Command("cat") }))
+	s, err := p.Session()
+	if err != nil {
+		t.Fatalf("Unexpected error from Session: %s", err)
+	}
+	defer func() {
+		_ = s.Close()
+		_ = s.Wait()
+	}()
+	if _, err := s.Write([]byte("hi\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	buf := make([]byte, 64)
+	if _, err := s.Read(buf); err != nil {
+		t.Fatalf("Unexpected error from Read: %s", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Unexpected error from Close: %s", err)
+	}
+	if _, err := p.Session(); err != errPoolClosed {
+		t.Errorf("Unexpected error from Session after Close, got %v", err)
+	}
+}
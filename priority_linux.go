@@ -0,0 +1,70 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// startCmd starts c with the priority, the umask, the capabilities and the
+// seccomp filter of o, if any. The nice value, the umask, the capability
+// sets and the seccomp filters of a thread are all inherited by the
+// processes it forks, so c is started from a thread of its own with them.
+// The umask is shared by the threads of a process unless one unshares its
+// filesystem attributes first.
+func startCmd(c *exec.Cmd, o *startOptions) error {
+	if o.ambient != nil {
+		// A copy of SysProcAttr and AmbientCaps, which may be shared.
+		attr := &syscall.SysProcAttr{}
+		if c.SysProcAttr != nil {
+			*attr = *c.SysProcAttr
+		}
+		caps := make([]uintptr, 0, len(attr.AmbientCaps)+len(o.ambient))
+		attr.AmbientCaps = append(append(caps, attr.AmbientCaps...), o.ambient...)
+		orig := c.SysProcAttr
+		c.SysProcAttr = attr
+		defer func() { c.SysProcAttr = orig }()
+	}
+	if o.priority == nil && o.umask == nil && o.dropCaps == nil && o.ambient == nil && o.seccomp == nil {
+		return c.Start()
+	}
+	errc := make(chan error, 1)
+	go func() {
+		// Never unlocked: the thread, with its priority, umask,
+		// capabilities and seccomp filter, ends with the goroutine rather
+		// than running others. Nothing but the send of the result of
+		// c.Start runs on it after the filter is installed.
+		runtime.LockOSThread()
+		if o.priority != nil {
+			if err := syscall.Setpriority(syscall.PRIO_PROCESS, syscall.Gettid(), *o.priority); err != nil {
+				errc <- os.NewSyscallError("setpriority", err)
+				return
+			}
+		}
+		if o.umask != nil {
+			if err := syscall.Unshare(syscall.CLONE_FS); err != nil {
+				errc <- os.NewSyscallError("unshare", err)
+				return
+			}
+			syscall.Umask(int(o.umask.Perm()))
+		}
+		if o.dropCaps != nil || o.ambient != nil {
+			if err := setCaps(o.dropCaps, o.ambient); err != nil {
+				errc <- err
+				return
+			}
+		}
+		if o.seccomp != nil {
+			if err := installSeccomp(o.seccomp); err != nil {
+				errc <- err
+				return
+			}
+		}
+		errc <- c.Start()
+	}()
+	return <-errc
+}
@@ -0,0 +1,28 @@
+//go:build !linux && !windows && !js && !plan9 && !wasip1
+// +build !linux,!windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// startCmd starts c with the priority of o, if any, set once started: the
+// priority of a process is shared by its threads on these platforms, so it
+// can not be set on a thread of its own to be inherited, as on Linux.
+func startCmd(c *exec.Cmd, o *startOptions) error {
+	if o.umask != nil || o.dropCaps != nil || o.ambient != nil || o.seccomp != nil {
+		return ErrUnsupported
+	}
+	if err := c.Start(); err != nil || o.priority == nil {
+		return err
+	}
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, c.Process.Pid, *o.priority); err != nil {
+		_ = c.Process.Kill() // Best effort.
+		_ = c.Wait()
+		return os.NewSyscallError("setpriority", err)
+	}
+	return nil
+}
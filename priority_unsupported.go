@@ -0,0 +1,13 @@
+//go:build windows || js || plan9 || wasip1
+// +build windows js plan9 wasip1
+
+package pty
+
+import "os/exec"
+
+func startCmd(c *exec.Cmd, o *startOptions) error {
+	if o.priority != nil || o.umask != nil || o.dropCaps != nil || o.ambient != nil || o.seccomp != nil {
+		return ErrUnsupported
+	}
+	return c.Start()
+}
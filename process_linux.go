@@ -0,0 +1,22 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strconv"
+)
+
+// isZombie reports whether the process pid exited without being waited
+// for, as its state in /proc tells.
+func isZombie(pid int) bool {
+	stat, err := ioutil.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	// The state follows the command name, which may contain anything.
+	i := bytes.LastIndexByte(stat, ')')
+	return i >= 0 && i+2 < len(stat) && stat[i+2] == 'Z'
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !windows && !js && !plan9 && !wasip1
+// +build !linux,!windows,!js,!plan9,!wasip1
+
+package pty
+
+// isZombie reports whether the process pid exited without being waited
+// for. It can not be told cheaply here.
+func isZombie(int) bool {
+	return false
+}
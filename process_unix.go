@@ -0,0 +1,16 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive reports whether p can be signaled and, where it can be
+// told, is not a zombie: an exited process which was not waited for still
+// can be signaled.
+func processAlive(p *os.Process) bool {
+	return p.Signal(syscall.Signal(0)) == nil && !isZombie(p.Pid)
+}
@@ -0,0 +1,10 @@
+//go:build windows || js || plan9 || wasip1
+// +build windows js plan9 wasip1
+
+package pty
+
+import "os"
+
+func processAlive(*os.Process) bool {
+	return true
+}
@@ -0,0 +1,178 @@
+package pty
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/creack/pty/vt"
+)
+
+// defaultPrompt matches the usual endings of shell prompts.
+var defaultPrompt = regexp.MustCompile(`[$#%>]\s*$`)
+
+// maxPromptLine bounds the end of the current line kept by PromptDetector.
+const maxPromptLine = 256
+
+// PromptOption configures a PromptDetector.
+type PromptOption func(*PromptDetector)
+
+// WithPromptIdle sets how long the output must be idle after a line
+// looking like a prompt, 200 milliseconds by default.
+func WithPromptIdle(d time.Duration) PromptOption {
+	return func(p *PromptDetector) {
+		p.idle = d
+	}
+}
+
+// WithPromptPattern sets the pattern matched against the current line, with
+// the escape sequences removed, to recognize a prompt. By default, it ends
+// with $, #, % or > and optional spaces.
+func WithPromptPattern(re *regexp.Regexp) PromptOption {
+	return func(p *PromptDetector) {
+		p.re = re
+	}
+}
+
+// PromptDetector is an io.Writer watching the output of a command to tell
+// when it is idle at a prompt, for automation which must know when it is
+// safe to send the next command. When the shell emits the OSC 133 semantic
+// prompt markers, they are trusted. Otherwise, the output is deemed at a
+// prompt once the current line matches the prompt pattern and no output
+// followed for the idle time. It is safe for concurrent use.
+type PromptDetector struct {
+	idle time.Duration
+	re   *regexp.Regexp
+
+	mu      sync.Mutex
+	strip   ansiStripper
+	line    []byte
+	last    time.Time     // Of the last Write.
+	changed chan struct{} // Closed and replaced on Write.
+
+	osc    vt.OSCScanner
+	marker byte // The last OSC 133 marker, 0 if none was seen.
+}
+
+// NewPromptDetector returns a PromptDetector with the given options.
+func NewPromptDetector(opts ...PromptOption) *PromptDetector {
+	p := &PromptDetector{idle: 200 * time.Millisecond, re: defaultPrompt, changed: make(chan struct{})}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// DetectPrompt returns a PromptDetector watching the output read from the
+// session from now on, until stop is called.
+func (s *Session) DetectPrompt(opts ...PromptOption) (p *PromptDetector, stop func()) {
+	p = NewPromptDetector(opts...)
+	s.addTap(p)
+	var once sync.Once
+	return p, func() { once.Do(func() { s.removeTap(p) }) }
+}
+
+// Write implements io.Writer. It never fails.
+func (p *PromptDetector) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.osc.Scan(b, p.scanMarker)
+	for _, c := range p.strip.strip(nil, b) {
+		switch c {
+		case '\r', '\n':
+			p.line = p.line[:0]
+		case '\b':
+			if len(p.line) > 0 {
+				p.line = p.line[:len(p.line)-1]
+			}
+		default:
+			if len(p.line) == maxPromptLine {
+				p.line = append(p.line[:0], p.line[maxPromptLine/2:]...)
+			}
+			p.line = append(p.line, c)
+		}
+	}
+	p.last = time.Now()
+	close(p.changed)
+	p.changed = make(chan struct{})
+	return len(b), nil
+}
+
+// scanMarker looks for the OSC 133 markers: A before the prompt, B after
+// it, C before the output of a command and D after the command.
+func (p *PromptDetector) scanMarker(osc []byte) {
+	if len(osc) >= 5 && string(osc[:4]) == "133;" {
+		p.marker = osc[4]
+	}
+}
+
+// state reports whether the output is at a prompt, and otherwise how long
+// to wait before it may be deemed to be, 0 if it is busy.
+func (p *PromptDetector) state(now time.Time) (bool, time.Duration) {
+	switch p.marker {
+	case 'B':
+		return true, 0
+	case 'C':
+		return false, 0
+	}
+	if !p.re.Match(p.line) {
+		return false, 0
+	}
+	if d := p.last.Add(p.idle).Sub(now); d > 0 {
+		return false, d
+	}
+	return true, 0
+}
+
+// AtPrompt reports whether the output is idle at a prompt.
+func (p *PromptDetector) AtPrompt() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ok, _ := p.state(time.Now())
+	return ok
+}
+
+// Reset forgets the prompt seen so far. Call it before sending a command,
+// so that the prompt the command was typed at is not mistaken for the next
+// one.
+func (p *PromptDetector) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.line = p.line[:0]
+	if p.marker != 0 {
+		p.marker = 'C'
+	}
+}
+
+// WaitPrompt waits until the output is idle at a prompt, or ctx is done.
+func (p *PromptDetector) WaitPrompt(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		ok, wait := p.state(time.Now())
+		changed := p.changed
+		p.mu.Unlock()
+		if ok {
+			return nil
+		}
+
+		var t *time.Timer
+		var timer <-chan time.Time
+		if wait > 0 {
+			t = time.NewTimer(wait)
+			timer = t.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-timer:
+		}
+		if t != nil {
+			t.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
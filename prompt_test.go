@@ -0,0 +1,52 @@
+package pty
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPromptDetector(t *testing.T) {
+	t.Parallel()
+
+	p := NewPromptDetector(WithPromptIdle(20 * time.Millisecond))
+	_, _ = p.Write([]byte("building...\r\n\x1b[32muser@host\x1b[0m:~$ "))
+	if p.AtPrompt() {
+		t.Error("Unexpected prompt before the idle time")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.WaitPrompt(ctx); err != nil {
+		t.Fatalf("Unexpected error from WaitPrompt: %s", err)
+	}
+
+	p.Reset()
+	_, _ = p.Write([]byte("make\r\nbuilding 1 of 2..."))
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.WaitPrompt(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Unexpected error from WaitPrompt while busy: %v", err)
+	}
+}
+
+func TestPromptDetectorOSC133(t *testing.T) {
+	t.Parallel()
+
+	p := NewPromptDetector(WithPromptIdle(time.Hour))
+	_, _ = p.Write([]byte("\x1b]133;A\x07prompt\x1b]133;B\x1b\\"))
+	if !p.AtPrompt() {
+		t.Error("Expected a prompt after the OSC 133;B marker")
+	}
+	_, _ = p.Write([]byte("ls\r\n\x1b]133;C\x07$ "))
+	if p.AtPrompt() {
+		t.Error("Unexpected prompt after the OSC 133;C marker")
+	}
+	_, _ = p.Write([]byte("\x1b]133;D;0\x07\x1b]133;A\x07$ \x1b]133;B\x07"))
+	if !p.AtPrompt() {
+		t.Error("Expected a prompt after the next OSC 133;B marker")
+	}
+	p.Reset()
+	if p.AtPrompt() {
+		t.Error("Unexpected prompt after Reset")
+	}
+}
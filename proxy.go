@@ -0,0 +1,150 @@
+package pty
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// HangupPolicy tells Proxy what to do with the command when the client
+// side ends first.
+type HangupPolicy int
+
+const (
+	// HangupClose closes the session. The command gets a SIGHUP on Unix.
+	HangupClose HangupPolicy = iota
+	// HangupEOF sends the end of file character of the pty, VEOF, to the
+	// command, closing its input as far as a command in canonical mode is
+	// concerned, and lets it run to completion.
+	HangupEOF
+	// HangupKill kills the command.
+	HangupKill
+)
+
+// EndedBy tells which side ended a Proxy first.
+type EndedBy int
+
+const (
+	// EndedByClient means the client reached EOF or failed.
+	EndedByClient EndedBy = iota
+	// EndedByCommand means the command exited or its pty failed.
+	EndedByCommand
+	// EndedByContext means the context was done.
+	EndedByContext
+)
+
+func (e EndedBy) String() string {
+	switch e {
+	case EndedByClient:
+		return "client"
+	case EndedByCommand:
+		return "command"
+	case EndedByContext:
+		return "context"
+	}
+	return "unknown"
+}
+
+// ProxyOption configures Proxy.
+type ProxyOption func(*proxyOptions)
+
+type proxyOptions struct {
+	hangup HangupPolicy
+}
+
+// WithHangup sets what Proxy does with the command when the client side
+// ends first. Defaults to HangupClose.
+func WithHangup(p HangupPolicy) ProxyOption {
+	return func(o *proxyOptions) { o.hangup = p }
+}
+
+// defaultEOF is the default VEOF character, sent where the pty doesn't
+// tell its own.
+const defaultEOF = 0x04
+
+// Proxy copies the output of the session to rw and the input read from rw
+// to the session until either side ends or ctx is done, and reports which
+// side ended first.
+//
+// When the command ends first, Proxy returns once its output is written to
+// rw; a pending read from rw is aborted if rw supports read deadlines,
+// abandoned otherwise. When the client ends first, the hangup policy is
+// applied. With HangupEOF and HangupKill, Proxy then returns once the
+// command output reaches EOF, so the tail of the output is not lost. The
+// EIO reported on Linux when the command exits counts as EOF, as does the
+// DoneError reported with WithDoneError. With HangupClose, it returns once
+// the session is closed, dropping the output not read yet.
+//
+// The error is the one which ended the proxy, nil on EOF, or ctx.Err().
+func Proxy(ctx context.Context, s *Session, rw io.ReadWriter, opts ...ProxyOption) (EndedBy, error) {
+	o := &proxyOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	inCtx, cancelIn := context.WithCancel(ctx)
+	defer cancelIn()
+
+	out := make(chan error, 1)
+	go func() {
+		_, err := CopyContext(ctx, rw, sessionOutput{s: s})
+		out <- err
+	}()
+	in := make(chan error, 1)
+	go func() {
+		_, err := CopyContext(inCtx, s, rw)
+		in <- err
+	}()
+
+	select {
+	case err := <-out:
+		if ctx.Err() != nil {
+			return EndedByContext, ctx.Err()
+		}
+		return EndedByCommand, err
+	case err := <-in:
+		if ctx.Err() != nil {
+			return EndedByContext, ctx.Err()
+		}
+		hangup(s, o.hangup)
+		<-out // Only the client error matters, it ended first.
+		if ctx.Err() != nil {
+			return EndedByContext, ctx.Err()
+		}
+		return EndedByClient, err
+	case <-ctx.Done():
+		return EndedByContext, ctx.Err()
+	}
+}
+
+// hangup applies p to the command of s once the client is gone.
+func hangup(s *Session, p HangupPolicy) {
+	switch p {
+	case HangupClose:
+		logErr("pty: close session", s.Close()) // Best effort.
+	case HangupEOF:
+		_, err := s.Write([]byte{eofChar(s.pty)})
+		logErr("pty: send eof", err) // Best effort.
+	case HangupKill:
+		if s.cmd.Process != nil {
+			logErr("pty: kill command", s.cmd.Process.Kill()) // Best effort.
+		}
+	}
+}
+
+// sessionOutput reads the output of a session, reporting EIO and DoneError
+// as EOF, and lets CopyContext and ReadContext abort pending reads.
+type sessionOutput struct{ s *Session }
+
+func (p sessionOutput) Read(b []byte) (int, error) {
+	n, err := p.s.Read(b)
+	if err != nil && (isEIO(err) || errors.Is(err, ErrPtyDone)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (p sessionOutput) SetReadDeadline(t time.Time) error {
+	return p.s.pty.SetReadDeadline(t)
+}
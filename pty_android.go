@@ -0,0 +1,10 @@
+//go:build android
+// +build android
+
+package pty
+
+func init() {
+	// Android's SELinux policy allowlists ioctls per device and does not
+	// grant TIOCGPTPEER to app domains, so never fall back to it.
+	compatMode = 1
+}
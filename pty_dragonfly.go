@@ -13,7 +13,7 @@ import (
 
 // same code as pty_darwin.go
 func open() (pty, tty *os.File, err error) {
-	p, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	p, err := sys.openFile("/dev/ptmx", os.O_RDWR, 0)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -37,7 +37,7 @@ func open() (pty, tty *os.File, err error) {
 		return nil, nil, err
 	}
 
-	t, err := os.OpenFile(sname, os.O_RDWR, 0)
+	t, err := sys.openFile(sname, os.O_RDWR, 0)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -11,11 +11,14 @@ import (
 )
 
 func posixOpenpt(oflag int) (fd int, err error) {
-	r0, _, e1 := syscall.Syscall(syscall.SYS_POSIX_OPENPT, uintptr(oflag), 0, 0)
-	fd = int(r0)
-	if e1 != 0 {
-		err = e1
-	}
+	err = ignoringEINTR(func() error {
+		r0, _, e1 := syscall.Syscall(syscall.SYS_POSIX_OPENPT, uintptr(oflag), 0, 0)
+		fd = int(r0)
+		if e1 != 0 {
+			return e1
+		}
+		return nil
+	})
 	return fd, err
 }
 
@@ -37,7 +40,7 @@ func open() (pty, tty *os.File, err error) {
 		return nil, nil, err
 	}
 
-	t, err := os.OpenFile("/dev/"+sname, os.O_RDWR, 0)
+	t, err := sys.openFile("/dev/"+sname, os.O_RDWR, 0)
 	if err != nil {
 		return nil, nil, err
 	}
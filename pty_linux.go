@@ -6,12 +6,18 @@ package pty
 import (
 	"os"
 	"strconv"
+	"sync/atomic"
 	"syscall"
 	"unsafe"
 )
 
+// ptmxPaths lists the pty multiplexers to try, in order. /dev/pts/ptmx is
+// used when /dev/ptmx is missing or denied, as seen in some containers and
+// under Android's SELinux policy.
+var ptmxPaths = []string{"/dev/ptmx", "/dev/pts/ptmx"}
+
 func open() (pty, tty *os.File, err error) {
-	p, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	p, err := openPtmx()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -31,13 +37,60 @@ func open() (pty, tty *os.File, err error) {
 		return nil, nil, err
 	}
 
-	t, err := os.OpenFile(sname, os.O_RDWR|syscall.O_NOCTTY, 0) //nolint:gosec // Expected Open from a variable.
+	t, err := openTty(p, sname)
 	if err != nil {
 		return nil, nil, err
 	}
 	return p, t, nil
 }
 
+// compatMode is set once the kernel, or a sandbox in front of it such as
+// gVisor or a seccomp profile, rejects TIOCGPTPEER. From then on, the tty
+// is only opened by path.
+var compatMode uint32
+
+// openTty opens the tty of the given ptmx by path. When the path is missing
+// or denied, as in containers not mounting their own devpts, it falls back
+// to TIOCGPTPEER, and the tty has no deadlines.
+func openTty(p *os.File, sname string) (*os.File, error) {
+	t, err := sys.openFile(sname, os.O_RDWR|syscall.O_NOCTTY, 0) //nolint:gosec // Expected Open from a variable.
+	if err == nil || !(os.IsNotExist(err) || os.IsPermission(err)) || atomic.LoadUint32(&compatMode) != 0 {
+		return t, err
+	}
+	logDebug("pty: can't open the tty by path, trying TIOCGPTPEER", "path", sname, "err", err)
+	t, perr := ptypeer(p, sname)
+	if perr == nil {
+		return t, nil
+	}
+	if !isCompatErr(perr) {
+		return nil, perr
+	}
+	logDebug("pty: TIOCGPTPEER rejected", "err", perr)
+	atomic.StoreUint32(&compatMode, 1)
+	return nil, err
+}
+
+// isCompatErr reports whether err means the ioctl is missing or filtered
+// rather than a genuine failure.
+func isCompatErr(err error) bool {
+	switch err {
+	case syscall.ENOTTY, syscall.EINVAL, syscall.ENOSYS, syscall.EPERM, syscall.EACCES:
+		return true
+	}
+	return false
+}
+
+func openPtmx() (p *os.File, err error) {
+	for _, name := range ptmxPaths {
+		p, err = sys.openFile(name, os.O_RDWR, 0)
+		if err == nil || !(os.IsNotExist(err) || os.IsPermission(err)) {
+			return p, err
+		}
+		logDebug("pty: can't open pty multiplexer", "path", name, "err", err)
+	}
+	return nil, err
+}
+
 func ptsname(f *os.File) (string, error) {
 	var n _C_uint
 	err := ioctl(f, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))) //nolint:gosec // Expected unsafe pointer for Syscall call.
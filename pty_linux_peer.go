@@ -0,0 +1,42 @@
+//go:build linux && go1.12
+// +build linux,go1.12
+
+package pty
+
+import (
+	"os"
+	"syscall"
+)
+
+// ptypeer opens the tty of the given ptmx with TIOCGPTPEER, which avoids
+// looking the tty up by path. Requires linux 4.13 or later.
+//
+// The tty is used as opened, in blocking mode: a descriptor wrapped by
+// os.NewFile in non-blocking mode would stay so in the children it is
+// handed to. So it is not registered with the runtime poller, and it has no
+// deadlines.
+func ptypeer(f *os.File, sname string) (*os.File, error) {
+	sc, err := f.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := uintptr(syscall.O_RDWR | syscall.O_NOCTTY | syscall.O_CLOEXEC)
+
+	var fd uintptr
+	var ierr error
+	err = sc.Control(func(pfd uintptr) {
+		ierr = ignoringEINTR(func() error {
+			var err error
+			fd, err = sys.ioctlRet(pfd, _TIOCGPTPEER, flags)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ierr != nil {
+		return nil, ierr
+	}
+	return os.NewFile(fd, sname), nil
+}
@@ -0,0 +1,123 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestOpenCompatMode ensures that the tty is still opened by path once
+// TIOCGPTPEER was rejected.
+func TestOpenCompatMode(t *testing.T) {
+	prev := atomic.SwapUint32(&compatMode, 1)
+	defer atomic.StoreUint32(&compatMode, prev)
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+	defer func() { _ = tty.Close() }()
+
+	if _, err := tty.Write([]byte("ping")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	buffer := make([]byte, 4)
+	if err := readBytes(pty, buffer); err != nil {
+		t.Fatalf("Unexpected error from readBytes: %s", err)
+	}
+	if string(buffer) != "ping" {
+		t.Errorf("Unexpected result returned from Read, got %q expected %q", buffer, "ping")
+	}
+}
+
+func TestIsCompatErr(t *testing.T) {
+	t.Parallel()
+
+	if isCompatErr(nil) {
+		t.Error("nil should not be a compat error")
+	}
+	if !isCompatErr(syscall.ENOTTY) {
+		t.Error("ENOTTY should be a compat error")
+	}
+}
+
+// TestOpenDeadline ensures that the tty opened by path, by default, is
+// polled by the runtime and supports deadlines.
+func TestOpenDeadline(t *testing.T) {
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+	defer func() { _ = tty.Close() }()
+
+	if err := tty.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatalf("Unexpected error from SetReadDeadline: %s", err)
+	}
+	if _, err := tty.Read(make([]byte, 1)); !os.IsTimeout(err) {
+		t.Errorf("Unexpected error from Read, got %v expected a timeout", err)
+	}
+}
+
+// TestOpenPeerFallback ensures that the tty is opened with TIOCGPTPEER when
+// its path is denied.
+func TestOpenPeerFallback(t *testing.T) {
+	prev := atomic.SwapUint32(&compatMode, 0)
+	defer atomic.StoreUint32(&compatMode, prev)
+	withSyscalls(t, &fakeSyscalls{ttyOpenErr: syscall.EACCES})
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+	defer func() { _ = tty.Close() }()
+
+	if _, err := tty.Write([]byte("ping")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	buffer := make([]byte, 4)
+	if err := readBytes(pty, buffer); err != nil {
+		t.Fatalf("Unexpected error from readBytes: %s", err)
+	}
+	if string(buffer) != "ping" {
+		t.Errorf("Unexpected result returned from Read, got %q expected %q", buffer, "ping")
+	}
+}
+
+// TestOpenPeerRejected ensures that the error opening the tty by path is
+// returned when TIOCGPTPEER is rejected too, and that it is not tried again.
+func TestOpenPeerRejected(t *testing.T) {
+	prev := atomic.SwapUint32(&compatMode, 0)
+	defer atomic.StoreUint32(&compatMode, prev)
+	withSyscalls(t, &fakeSyscalls{ttyOpenErr: syscall.EACCES, ioctlRetErr: syscall.ENOTTY})
+
+	if _, _, err := Open(); !errors.Is(err, syscall.EACCES) {
+		t.Errorf("Unexpected error from Open, got %v expected %v", err, syscall.EACCES)
+	}
+	if atomic.LoadUint32(&compatMode) != 1 {
+		t.Error("Expected the compat mode after TIOCGPTPEER was rejected")
+	}
+}
+
+// TestOpenPeerError ensures that a genuine TIOCGPTPEER failure is returned
+// rather than hidden by the fallback.
+func TestOpenPeerError(t *testing.T) {
+	prev := atomic.SwapUint32(&compatMode, 0)
+	defer atomic.StoreUint32(&compatMode, prev)
+	withSyscalls(t, &fakeSyscalls{ttyOpenErr: syscall.EACCES, ioctlRetErr: syscall.EMFILE})
+
+	if _, _, err := Open(); !errors.Is(err, syscall.EMFILE) {
+		t.Errorf("Unexpected error from Open, got %v expected %v", err, syscall.EMFILE)
+	}
+	if atomic.LoadUint32(&compatMode) != 0 {
+		t.Error("Unexpected compat mode after a genuine failure")
+	}
+}
@@ -29,7 +29,7 @@ func open() (pty, tty *os.File, err error) {
 	 * returns them to the caller in struct ptmget.
 	 */
 
-	p, err := os.OpenFile("/dev/ptm", os.O_RDWR|syscall.O_CLOEXEC, 0)
+	p, err := sys.openFile("/dev/ptm", os.O_RDWR|syscall.O_CLOEXEC, 0)
 	if err != nil {
 		return nil, nil, err
 	}
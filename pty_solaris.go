@@ -16,7 +16,11 @@ import (
 )
 
 func open() (pty, tty *os.File, err error) {
-	ptmxfd, err := syscall.Open("/dev/ptmx", syscall.O_RDWR|syscall.O_NOCTTY, 0)
+	var ptmxfd int
+	err = ignoringEINTR(func() (err error) {
+		ptmxfd, err = syscall.Open("/dev/ptmx", syscall.O_RDWR|syscall.O_NOCTTY, 0)
+		return err
+	})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -41,7 +45,11 @@ func open() (pty, tty *os.File, err error) {
 		return nil, nil, err
 	}
 
-	ptsfd, err := syscall.Open(sname, os.O_RDWR|syscall.O_NOCTTY, 0)
+	var ptsfd int
+	err = ignoringEINTR(func() (err error) {
+		ptsfd, err = syscall.Open(sname, os.O_RDWR|syscall.O_NOCTTY, 0)
+		return err
+	})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -113,7 +121,7 @@ func ptsdev(f *os.File) (uint64, error) {
 	}
 	err = sc.Control(func(fd uintptr) {
 		var status syscall.Stat_t
-		if err := syscall.Fstat(int(fd), &status); err != nil {
+		if err := ignoringEINTR(func() error { return syscall.Fstat(int(fd), &status) }); err != nil {
 			results <- 0
 			errors <- err
 		}
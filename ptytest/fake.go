@@ -0,0 +1,107 @@
+package ptytest
+
+import (
+	"io"
+	"sync"
+
+	"github.com/creack/pty"
+)
+
+// Op is an operation of a FakeFile which can be made to fail.
+type Op int
+
+// Operations of a FakeFile.
+const (
+	OpRead Op = iota
+	OpWrite
+	OpResize
+	OpClose
+)
+
+// FakeFile is one end of an in-memory pty pair, for unit tests of code
+// using ptys without allocating real ones. It is a pty.Pipe whose
+// operations can be made to fail. It implements pty.File.
+type FakeFile struct {
+	*pty.Pipe
+
+	mu    sync.Mutex
+	fails map[Op]error
+}
+
+// NewFake returns an in-memory pty and its tty. See pty.OpenPipe.
+func NewFake() (ptmx, tty *FakeFile) {
+	p, t := pty.OpenPipe()
+	return &FakeFile{Pipe: p}, &FakeFile{Pipe: t}
+}
+
+// FailNext makes the next op on f fail with err.
+func (f *FakeFile) FailNext(op Op, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fails == nil {
+		f.fails = map[Op]error{}
+	}
+	f.fails[op] = err
+}
+
+func (f *FakeFile) fail(op Op) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.fails[op]
+	delete(f.fails, op)
+	return err
+}
+
+// Read implements io.Reader.
+func (f *FakeFile) Read(b []byte) (int, error) {
+	if err := f.fail(OpRead); err != nil {
+		return 0, err
+	}
+	return f.Pipe.Read(b)
+}
+
+// Write implements io.Writer.
+func (f *FakeFile) Write(b []byte) (int, error) {
+	if err := f.fail(OpWrite); err != nil {
+		return 0, err
+	}
+	return f.Pipe.Write(b)
+}
+
+// ReadFrom implements io.ReaderFrom, writing through Write for the
+// failures set with FailNext to apply to io.Copy too.
+func (f *FakeFile) ReadFrom(r io.Reader) (int64, error) {
+	return pty.CopyPooled(writerFunc(f.Write), r)
+}
+
+// WriteTo implements io.WriterTo, reading through Read for the failures
+// set with FailNext to apply to io.Copy too.
+func (f *FakeFile) WriteTo(w io.Writer) (int64, error) {
+	return pty.CopyPooled(w, readerFunc(f.Read))
+}
+
+// readerFunc and writerFunc hide the io.WriterTo and io.ReaderFrom
+// implementations of a FakeFile from the copy helpers.
+type (
+	readerFunc func([]byte) (int, error)
+	writerFunc func([]byte) (int, error)
+)
+
+func (fn readerFunc) Read(b []byte) (int, error)  { return fn(b) }
+func (fn writerFunc) Write(b []byte) (int, error) { return fn(b) }
+
+// Setsize sets the window size shared by both ends.
+func (f *FakeFile) Setsize(ws *pty.Winsize) error {
+	if err := f.fail(OpResize); err != nil {
+		return err
+	}
+	return f.Pipe.Setsize(ws)
+}
+
+// Close implements io.Closer.
+func (f *FakeFile) Close() error {
+	if err := f.fail(OpClose); err != nil {
+		return err
+	}
+	return f.Pipe.Close()
+}
@@ -0,0 +1,63 @@
+package ptytest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/creack/pty"
+)
+
+var (
+	_ pty.File = (*FakeFile)(nil)
+
+	errResize = errors.New("resize failed")
+	errRead   = errors.New("read failed")
+	errWrite  = errors.New("write failed")
+)
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+
+	ptmx, tty := NewFake()
+	defer func() { _ = ptmx.Close() }()
+
+	ptmx.FailNext(OpResize, errResize)
+	if err := ptmx.Setsize(&pty.Winsize{Rows: 1, Cols: 1}); !errors.Is(err, errResize) {
+		t.Errorf("Unexpected error from Setsize, got %v expected %v", err, errResize)
+	}
+	if err := ptmx.Setsize(&pty.Winsize{Rows: 50, Cols: 132}); err != nil {
+		t.Errorf("Unexpected error from Setsize: %s", err)
+	}
+	if ws, _ := tty.GetsizeFull(); ws.Rows != 50 || ws.Cols != 132 {
+		t.Errorf("Unexpected size, got %dx%d expected 132x50", ws.Cols, ws.Rows)
+	}
+
+	tty.FailNext(OpRead, errRead)
+	if _, err := tty.Read(make([]byte, 1)); !errors.Is(err, errRead) {
+		t.Errorf("Unexpected error from Read, got %v expected %v", err, errRead)
+	}
+}
+
+func TestFakeCopy(t *testing.T) {
+	t.Parallel()
+
+	ptmx, tty := NewFake()
+	defer func() { _ = ptmx.Close() }()
+
+	// io.Copy goes through WriteTo, which must fail as Read does.
+	go func() { _, _ = ptmx.Write([]byte("hello")) }()
+	tty.FailNext(OpRead, errRead)
+	var out bytes.Buffer
+	if n, err := io.Copy(&out, tty); !errors.Is(err, errRead) || n != 0 {
+		t.Errorf("Unexpected result from io.Copy, got %d, %v expected 0, %v", n, err, errRead)
+	}
+
+	// And through ReadFrom, which must fail as Write does.
+	ptmx.FailNext(OpWrite, errWrite)
+	if n, err := io.Copy(ptmx, strings.NewReader("hello")); !errors.Is(err, errWrite) || n != 0 {
+		t.Errorf("Unexpected result from io.Copy, got %d, %v expected 0, %v", n, err, errWrite)
+	}
+}
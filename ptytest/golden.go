@@ -0,0 +1,175 @@
+//go:build go1.14
+// +build go1.14
+
+package ptytest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// UpdateEnv is the environment variable which, set to a true value such as
+// 1, makes Golden update the golden files instead of comparing with them.
+const UpdateEnv = "PTYTEST_UPDATE"
+
+// updating tells whether the golden files are to be updated according to
+// UpdateEnv.
+func updating() bool {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return update
+}
+
+// timestampRe matches common dates and times.
+var timestampRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}([T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?)?|\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`)
+
+// GoldenOption configures Golden.
+type GoldenOption func(*golden)
+
+type golden struct {
+	size    pty.Winsize
+	strip   bool
+	scrubs  []scrub
+	timeout time.Duration
+	update  *bool
+}
+
+type scrub struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// WithSize runs the command in a terminal of rows and cols instead of the
+// default 24 rows of 80 columns.
+func WithSize(rows, cols uint16) GoldenOption {
+	return func(g *golden) { g.size = pty.Winsize{Rows: rows, Cols: cols} }
+}
+
+// StripANSI removes the escape sequences from the output. See
+// pty.StripANSI.
+func StripANSI() GoldenOption {
+	return func(g *golden) { g.strip = true }
+}
+
+// Scrub replaces the matches of re in the output with repl, as
+// regexp.Regexp.ReplaceAllString does, to hide what changes between runs.
+func Scrub(re *regexp.Regexp, repl string) GoldenOption {
+	return func(g *golden) { g.scrubs = append(g.scrubs, scrub{re: re, repl: repl}) }
+}
+
+// ScrubTimestamps replaces the dates and times in the output with
+// <TIMESTAMP>.
+func ScrubTimestamps() GoldenOption {
+	return Scrub(timestampRe, "<TIMESTAMP>")
+}
+
+// WithTimeout bounds the run of the command, 10 seconds by default.
+func WithTimeout(d time.Duration) GoldenOption {
+	return func(g *golden) { g.timeout = d }
+}
+
+// Update makes Golden update the golden file if update is true, or compare
+// with it otherwise, whatever UpdateEnv says, for example for test packages
+// defining their own -update flag:
+//
+//	var update = flag.Bool("update", false, "update the golden files")
+//	...
+//	ptytest.Golden(t, cmd, "testdata/ls.golden", ptytest.Update(*update))
+func Update(update bool) GoldenOption {
+	return func(g *golden) { g.update = &update }
+}
+
+// Golden runs cmd under a pty and compares its output, normalized, with
+// the golden file at path, relative to the package directory such as
+// testdata/name.golden. Line endings are normalized to \n. A command
+// exiting with a non-zero status does not fail the test: the status is
+// appended to the output, as a last line such as "[exit status 1]", to be
+// compared too. Running the test with PTYTEST_UPDATE=1, see UpdateEnv and
+// Update, writes the output to the golden file instead.
+func Golden(tb testing.TB, cmd *exec.Cmd, path string, opts ...GoldenOption) {
+	tb.Helper()
+
+	g := &golden{size: pty.Winsize{Rows: 24, Cols: 80}, timeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	s, err := pty.StartSession(cmd, pty.WithSize(&g.size))
+	if errors.Is(err, pty.ErrUnsupported) {
+		tb.Skip("ptys are not supported")
+	}
+	if err != nil {
+		tb.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+	out, err := s.WaitAndDrain(ctx)
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		tb.Fatalf("Unexpected error running %s: %s", cmd.Path, err)
+	}
+
+	if g.strip {
+		out, _ = ioutil.ReadAll(pty.StripANSI(bytes.NewReader(out)))
+	}
+	got := strings.Replace(string(out), "\r\n", "\n", -1)
+	for _, s := range g.scrubs {
+		got = s.re.ReplaceAllString(got, s.repl)
+	}
+	if exitErr != nil {
+		if got != "" && !strings.HasSuffix(got, "\n") {
+			got += "\n"
+		}
+		got += "[" + exitErr.ProcessState.String() + "]\n"
+	}
+
+	update := updating()
+	if g.update != nil {
+		update = *g.update
+	}
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatalf("Unexpected error from MkdirAll: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0o644); err != nil {
+			tb.Fatalf("Unexpected error from WriteFile: %s", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("Unexpected error reading the golden file, run with %s=1 to create it: %s", UpdateEnv, err)
+	}
+	if got != string(want) {
+		tb.Errorf("Output differs from %s, run with %s=1 to update it:\n%s", path, UpdateEnv, diffLine(string(want), got))
+	}
+}
+
+// diffLine describes the first line differing between want and got.
+func diffLine(want, got string) string {
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; ; i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g || i >= len(wl) || i >= len(gl) {
+			return "line " + strconv.Itoa(i+1) + ":\n- " + strconv.Quote(w) + "\n+ " + strconv.Quote(g)
+		}
+	}
+}
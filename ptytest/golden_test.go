@@ -0,0 +1,48 @@
+//go:build go1.14 && !windows && !js && !plan9 && !wasip1
+// +build go1.14,!windows,!js,!plan9,!wasip1
+
+package ptytest_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/creack/pty/ptytest"
+)
+
+// Importing ptytest must not keep test packages from defining -update.
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGoldenUpdate(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "echo.golden")
+	ptytest.Golden(t, exec.Command("echo", "hello"), path, ptytest.Update(true))
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadFile: %s", err)
+	}
+	if string(got) != "hello\n" {
+		t.Errorf("Unexpected golden file, got %q expected %q", got, "hello\n")
+	}
+	ptytest.Golden(t, exec.Command("echo", "hello"), path, ptytest.Update(*update))
+}
+
+func TestGoldenExitStatus(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "fail.golden")
+	cmd := func() *exec.Cmd { return exec.Command("sh", "-c", "echo failing; exit 3") }
+	ptytest.Golden(t, cmd(), path, ptytest.Update(true))
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadFile: %s", err)
+	}
+	if expect := "failing\n[exit status 3]\n"; string(got) != expect {
+		t.Errorf("Unexpected golden file, got %q expected %q", got, expect)
+	}
+	ptytest.Golden(t, cmd(), path)
+}
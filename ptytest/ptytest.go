@@ -0,0 +1,89 @@
+//go:build go1.14
+// +build go1.14
+
+// Package ptytest helps testing code which interacts with a terminal.
+package ptytest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// DefaultTimeout bounds the reads of ReadUntil.
+const DefaultTimeout = 5 * time.Second
+
+// Pty is a pty and tty pair closed at the end of the test. The code under
+// test typically uses Tty, while the test drives it with WriteLine and
+// ReadUntil, which use Pty.
+type Pty struct {
+	Pty, Tty *os.File
+
+	tb      testing.TB
+	timeout time.Duration
+	buf     []byte // Read past the last ReadUntil match.
+}
+
+// New opens a pty and tty pair closed at the end of the test. It skips
+// the test where ptys are not supported.
+func New(tb testing.TB) *Pty {
+	tb.Helper()
+
+	p, t, err := pty.Open()
+	if errors.Is(err, pty.ErrUnsupported) {
+		tb.Skip("ptys are not supported")
+	}
+	if err != nil {
+		tb.Fatalf("Unexpected error from Open: %s", err)
+	}
+	tb.Cleanup(func() {
+		_ = t.Close()
+		_ = p.Close()
+	})
+	return &Pty{Pty: p, Tty: t, tb: tb, timeout: DefaultTimeout}
+}
+
+// SetTimeout changes the timeout of ReadUntil.
+func (p *Pty) SetTimeout(d time.Duration) {
+	p.timeout = d
+}
+
+// WriteLine writes s followed by a newline to the pty, as input for the
+// code using the tty. It fails the test on error.
+func (p *Pty) WriteLine(s string) {
+	p.tb.Helper()
+
+	if _, err := p.Pty.Write([]byte(s + "\n")); err != nil {
+		p.tb.Fatalf("Unexpected error from Write: %s", err)
+	}
+}
+
+// ReadUntil reads the output written to the tty until it contains substr,
+// and returns it up to the end of substr. The output read past it is
+// returned by the next call. It fails the test if substr is not read in
+// time. Like other Fatal calls, it must be called from the goroutine
+// running the test.
+func (p *Pty) ReadUntil(substr string) string {
+	p.tb.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	buf := make([]byte, 1024)
+	for {
+		if i := bytes.Index(p.buf, []byte(substr)); i >= 0 {
+			out := string(p.buf[:i+len(substr)])
+			p.buf = p.buf[i+len(substr):]
+			return out
+		}
+		n, err := pty.ReadContext(ctx, p.Pty, buf)
+		p.buf = append(p.buf, buf[:n]...)
+		if err != nil {
+			p.tb.Fatalf("Unexpected error reading until %q, read %q: %s", substr, p.buf, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+//go:build go1.14
+// +build go1.14
+
+package ptytest
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestPty(t *testing.T) {
+	t.Parallel()
+
+	p := New(t)
+	go func() {
+		line, _ := bufio.NewReader(p.Tty).ReadString('\n')
+		_, _ = p.Tty.Write([]byte("got " + line))
+	}()
+	p.WriteLine("hello")
+	if out := p.ReadUntil("got hello"); out != "hello\r\ngot hello" {
+		t.Errorf("Unexpected output, got %q", out)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	t.Parallel()
+
+	cmd := exec.Command("sh", "-c", `printf '\033[1mhello\033[0m %s\n' "$(date +%H:%M:%S)"; echo columns $(stty size | cut -d" " -f2)`)
+	Golden(t, cmd, "testdata/hello.golden", WithSize(30, 100), StripANSI(), ScrubTimestamps())
+}
+
+func TestTUI(t *testing.T) {
+	t.Parallel()
+
+	// A line editor of sorts: echoes the keys received, in raw mode.
+	cmd := exec.Command("sh", "-c", `stty raw -echo; printf '\033[?1h\033[2J\033[Hready'; while :; do k=$(dd bs=1 count=1 2>/dev/null | od -An -c | tr -d ' '); printf '\r\033[Kgot %s' "$k"; done`)
+	tui := StartTUI(t, cmd, 10, 40)
+	tui.WaitFor("ready")
+	if !tui.Screen().AppCursor() {
+		t.Error("Expected the application cursor keys mode")
+	}
+	tui.Press("ctrl+a")
+	tui.WaitFor(`got 001`)
+	tui.Type("x")
+	tui.WaitFor(`got x`)
+	tui.Press("up")
+	tui.WaitFor(`got A`)
+}
+
+func TestSoak(t *testing.T) {
+	if testing.Short() {
+		t.Skip("soak test")
+	}
+
+	r := Soak(context.Background(), SoakOptions{Sessions: 4, Duration: time.Second, Lifetime: 100 * time.Millisecond})
+	t.Log(r)
+	if len(r.Errors) > 0 {
+		t.Errorf("Unexpected errors from the sessions: %v", r.Errors)
+	}
+	if r.Sessions == 0 || r.BytesRead == 0 {
+		t.Errorf("Unexpected idle run: %s", r)
+	}
+	if r.Leaked() {
+		t.Errorf("Unexpected leak: %s", r)
+	}
+}
@@ -0,0 +1,174 @@
+package ptytest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// SoakOptions configures Soak. The zero value is usable.
+type SoakOptions struct {
+	// Sessions is the number of sessions running concurrently, 8 by
+	// default.
+	Sessions int
+	// Duration is how long new sessions are started for, 5 seconds by
+	// default.
+	Duration time.Duration
+	// Lifetime bounds how long each session runs before being closed,
+	// 500 milliseconds by default.
+	Lifetime time.Duration
+	// Command returns the command run in each session. By default, a
+	// shell printing lines as fast as the pty reads them.
+	Command func() *exec.Cmd
+	// Settle bounds how long Soak waits for file descriptors and
+	// goroutines to be released once the sessions are closed, 2 seconds
+	// by default.
+	Settle time.Duration
+}
+
+// SoakReport is the outcome of Soak.
+type SoakReport struct {
+	Sessions  int   // Sessions started.
+	Resizes   int   // Successful resizes.
+	BytesRead int64 // Output read from all the sessions.
+	Errors    []error
+
+	// The open file descriptors, -1 where they can not be counted, and
+	// the goroutines of the process, before and after the run.
+	FdsBefore, FdsAfter               int
+	GoroutinesBefore, GoroutinesAfter int
+}
+
+// Leaked reports whether file descriptors or goroutines were not released
+// by the end of the run.
+func (r *SoakReport) Leaked() bool {
+	return r.FdsAfter > r.FdsBefore || r.GoroutinesAfter > r.GoroutinesBefore
+}
+
+func (r *SoakReport) String() string {
+	return fmt.Sprintf("%d sessions, %d resizes, %d bytes read, %d errors, fds %d -> %d, goroutines %d -> %d",
+		r.Sessions, r.Resizes, r.BytesRead, len(r.Errors),
+		r.FdsBefore, r.FdsAfter, r.GoroutinesBefore, r.GoroutinesAfter)
+}
+
+// Soak starts sessions running a noisy command, resizes them at random and
+// closes them, keeping opts.Sessions of them running until opts.Duration
+// elapses or ctx is done. It then reports the file descriptors and
+// goroutines left behind. Other activity of the process skews the counts,
+// so Soak is best run alone, e.g. from a test which is not parallel.
+func Soak(ctx context.Context, opts SoakOptions) *SoakReport {
+	if opts.Sessions <= 0 {
+		opts.Sessions = 8
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = 5 * time.Second
+	}
+	if opts.Lifetime <= 0 {
+		opts.Lifetime = 500 * time.Millisecond
+	}
+	if opts.Command == nil {
+		opts.Command = func() *exec.Cmd {
+			return exec.Command("sh", "-c", "while :; do echo soak soak soak soak; done")
+		}
+	}
+	if opts.Settle <= 0 {
+		opts.Settle = 2 * time.Second
+	}
+
+	r := &SoakReport{FdsBefore: countFds(), GoroutinesBefore: runtime.NumGoroutine()}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Sessions; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for ctx.Err() == nil {
+				resizes, n, err := soakSession(ctx, rnd, opts)
+				mu.Lock()
+				r.Sessions++
+				r.Resizes += resizes
+				r.BytesRead += n
+				if err != nil {
+					r.Errors = append(r.Errors, err)
+				}
+				mu.Unlock()
+			}
+		}(time.Now().UnixNano() + int64(i))
+	}
+	wg.Wait()
+
+	// Let the runtime and the kernel release what the sessions used.
+	deadline := time.Now().Add(opts.Settle)
+	for {
+		runtime.GC()
+		r.FdsAfter, r.GoroutinesAfter = countFds(), runtime.NumGoroutine()
+		if !r.Leaked() || time.Now().After(deadline) {
+			return r
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// soakSession runs a single session for a random part of opts.Lifetime.
+func soakSession(ctx context.Context, rnd *rand.Rand, opts SoakOptions) (resizes int, n int64, err error) {
+	s, err := pty.StartSession(opts.Command())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	read := make(chan int64, 1)
+	go func() {
+		n, _ := io.Copy(ioutil.Discard, s)
+		read <- n
+	}()
+
+	t := time.NewTimer(time.Duration(rnd.Int63n(int64(opts.Lifetime))) + time.Millisecond)
+	defer t.Stop()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-t.C:
+			break loop
+		case <-time.After(time.Duration(rnd.Intn(20)) * time.Millisecond):
+			ws := &pty.Winsize{Rows: uint16(1 + rnd.Intn(100)), Cols: uint16(1 + rnd.Intn(300))}
+			if err = s.Resize(ws); err != nil {
+				break loop
+			}
+			resizes++
+		}
+	}
+
+	if cerr := s.Close(); err == nil {
+		err = cerr
+	}
+	// The command usually exits on the hangup. Best effort.
+	_ = s.Cmd().Process.Kill()
+	_ = s.Wait()
+	return resizes, <-read, err
+}
+
+// countFds returns the number of open file descriptors of the process, or
+// -1 if they can not be listed.
+func countFds() int {
+	for _, dir := range []string{"/proc/self/fd", "/dev/fd"} {
+		if fds, err := ioutil.ReadDir(dir); err == nil {
+			return len(fds)
+		}
+	}
+	return -1
+}
@@ -0,0 +1,123 @@
+//go:build go1.14
+// +build go1.14
+
+package ptytest
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/creack/pty/vt"
+)
+
+// TUI drives a full screen program, such as a Bubble Tea or tcell one,
+// running under a pty, and renders its output on an in-memory screen for
+// assertions.
+type TUI struct {
+	tb      testing.TB
+	s       *pty.Session
+	term    *vt.Terminal
+	timeout time.Duration
+	done    chan struct{} // Closed once the output ended.
+}
+
+// StartTUI starts cmd under a pty of rows and cols. The program is
+// stopped at the end of the test. It skips the test where ptys are not
+// supported.
+func StartTUI(tb testing.TB, cmd *exec.Cmd, rows, cols uint16) *TUI {
+	tb.Helper()
+
+	if cmd.Env == nil {
+		cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	}
+	s, err := pty.StartSession(cmd, pty.WithSize(&pty.Winsize{Rows: rows, Cols: cols}))
+	if errors.Is(err, pty.ErrUnsupported) {
+		tb.Skip("ptys are not supported")
+	}
+	if err != nil {
+		tb.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	t := &TUI{tb: tb, s: s, term: vt.New(int(rows), int(cols)), timeout: DefaultTimeout, done: make(chan struct{})}
+	go func() {
+		defer close(t.done)
+		_, _ = io.Copy(t.term, s) // Until the program exits.
+	}()
+	tb.Cleanup(func() {
+		_ = s.Close()
+		if p := cmd.Process; p != nil {
+			_ = p.Kill()
+		}
+		_ = s.Wait()
+	})
+	return t
+}
+
+// SetTimeout changes the timeout of WaitFor.
+func (t *TUI) SetTimeout(d time.Duration) {
+	t.timeout = d
+}
+
+// Press sends keys, named as pty.EncodeKeys expects, taking the cursor
+// keys mode of the program into account. It fails the test on error.
+func (t *TUI) Press(keys ...string) {
+	t.tb.Helper()
+
+	b, err := pty.EncodeKeys(t.term.AppCursor(), keys...)
+	if err != nil {
+		t.tb.Fatalf("Unexpected error from EncodeKeys: %s", err)
+	}
+	if _, err := t.s.Write(b); err != nil {
+		t.tb.Fatalf("Unexpected error from Write: %s", err)
+	}
+}
+
+// Type sends text as typed. It fails the test on error.
+func (t *TUI) Type(text string) {
+	t.tb.Helper()
+
+	if _, err := t.s.Write([]byte(text)); err != nil {
+		t.tb.Fatalf("Unexpected error from Write: %s", err)
+	}
+}
+
+// Resize resizes the pty and the screen.
+func (t *TUI) Resize(rows, cols uint16) {
+	t.tb.Helper()
+
+	t.term.Resize(int(rows), int(cols))
+	if err := t.s.Resize(&pty.Winsize{Rows: rows, Cols: cols}); err != nil {
+		t.tb.Fatalf("Unexpected error from Resize: %s", err)
+	}
+}
+
+// Screen returns the screen as rendered so far.
+func (t *TUI) Screen() *vt.Terminal {
+	return t.term
+}
+
+// WaitFor waits until the screen contains text, and fails the test with
+// the screen if it doesn't in time.
+func (t *TUI) WaitFor(text string) {
+	t.tb.Helper()
+
+	deadline := time.Now().Add(t.timeout)
+	for !strings.Contains(t.term.String(), text) {
+		select {
+		case <-t.done:
+			if strings.Contains(t.term.String(), text) {
+				return
+			}
+			t.tb.Fatalf("Program exited before showing %q, screen:\n%s", text, t.term)
+		case <-time.After(10 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.tb.Fatalf("Timed out waiting for %q, screen:\n%s", text, t.term)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package pty
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Default answers to the device attributes queries: a VT220 with ANSI
+// colors, as many terminal emulators report.
+const (
+	DefaultDA  = "\x1b[?62;22c"
+	DefaultDA2 = "\x1b[>1;10;0c"
+)
+
+// maxQuery bounds the parameters of the control sequences looked at.
+const maxQuery = 16
+
+// maxAnswers bounds the answers waiting to be written, beyond which they are
+// dropped, as the command is not reading them.
+const maxAnswers = 4096
+
+// QueryResponses are the answers of a Session to the terminal queries of
+// its command, see Session.RespondQueries.
+type QueryResponses struct {
+	Row, Col int    // The cursor position reported, 1, 1 if zero.
+	DA       string // The primary device attributes, DefaultDA if empty.
+	DA2      string // The secondary device attributes, DefaultDA2 if empty.
+}
+
+// RespondQueries answers the terminal queries found in the output read
+// from the session from now on with r, until stop is called, as a terminal
+// would, for headless sessions not to hang on commands waiting for the
+// answers. The device status (DSR), cursor position (CPR and DECXCPR) and
+// device attributes (DA and DA2) queries are answered, the others are
+// left alone. The queries are looked for before the transformers, which
+// may hide them. The answers are written to the session as input, from a
+// new goroutine, once the queries are read, so the output of the session
+// must be read.
+func (s *Session) RespondQueries(r QueryResponses) (stop func()) {
+	q := &queryResponder{s: s, r: r}
+	s.addRawTap(q)
+	var once sync.Once
+	return func() { once.Do(func() { s.removeTap(q) }) }
+}
+
+// queryResponder answers the queries in the output of a session, split
+// across writes or not.
+type queryResponder struct {
+	s *Session
+	r QueryResponses
+
+	mu      sync.Mutex
+	state   int    // 0: ground, 1: after ESC, 2: in ESC [.
+	params  []byte // Of the control sequence so far, with its private marker.
+	answers []byte // Not written yet.
+	writing bool   // Whether a goroutine writes the answers.
+}
+
+// Write implements io.Writer, as a tap of the session output.
+func (q *queryResponder) Write(p []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, c := range p {
+		switch {
+		case c == 0x1b:
+			q.state = 1
+		case q.state == 1 && c == '[':
+			q.state, q.params = 2, q.params[:0]
+		case q.state == 2 && c >= 0x30 && c <= 0x3f && len(q.params) < maxQuery:
+			q.params = append(q.params, c)
+		case q.state == 2 && c >= 0x40 && c <= 0x7e:
+			if a := q.answer(string(q.params), c); len(q.answers)+len(a) <= maxAnswers {
+				q.answers = append(q.answers, a...)
+			}
+			q.state = 0
+		default:
+			q.state = 0
+		}
+	}
+	// Not written from here, the read path of the session: the command may
+	// not read its input until its output is read.
+	if len(q.answers) > 0 && !q.writing {
+		q.writing = true
+		go q.writeAnswers()
+	}
+	return len(p), nil
+}
+
+// writeAnswers writes the answers to the session until there are no more.
+func (q *queryResponder) writeAnswers() {
+	for {
+		q.mu.Lock()
+		answers := q.answers
+		q.answers = nil
+		if len(answers) == 0 {
+			q.writing = false
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+
+		_, err := q.s.Write(answers)
+		logErr("pty: answer terminal query", err) // Best effort.
+	}
+}
+
+// answer returns the answer to the control sequence with the given
+// parameters and final byte, nil if it is not a query answered.
+func (q *queryResponder) answer(params string, final byte) []byte {
+	row, col := q.r.Row, q.r.Col
+	if row == 0 {
+		row = 1
+	}
+	if col == 0 {
+		col = 1
+	}
+	pos := strconv.Itoa(row) + ";" + strconv.Itoa(col)
+
+	switch {
+	case final == 'n' && params == "5":
+		return []byte("\x1b[0n")
+	case final == 'n' && params == "6":
+		return []byte("\x1b[" + pos + "R")
+	case final == 'n' && params == "?6": // With the page, always 1.
+		return []byte("\x1b[?" + pos + ";1R")
+	case final == 'c' && (params == "" || params == "0"):
+		if q.r.DA != "" {
+			return []byte(q.r.DA)
+		}
+		return []byte(DefaultDA)
+	case final == 'c' && (params == ">" || params == ">0"):
+		if q.r.DA2 != "" {
+			return []byte(q.r.DA2)
+		}
+		return []byte(DefaultDA2)
+	}
+	return nil
+}
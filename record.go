@@ -0,0 +1,151 @@
+package pty
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordFormat encodes the events of a recording. Times are relative to the
+// start of the recording.
+type recordFormat interface {
+	output(t time.Duration, p []byte) error
+	resize(t time.Duration, ws *Winsize) error
+	close() error
+}
+
+// inputFormat is implemented by formats which can record input.
+type inputFormat interface {
+	input(t time.Duration, p []byte) error
+}
+
+// RecordOption configures a Recorder.
+type RecordOption func(*recordOptions)
+
+type recordOptions struct {
+	input bool
+}
+
+// RecordInput records the input written to the session too, where the
+// format supports it. Beware that input includes passwords typed without
+// echo.
+func RecordInput() RecordOption {
+	return func(o *recordOptions) { o.input = true }
+}
+
+// Recorder records the output of a Session, along with its resizes and
+// optionally its input, from the moment it is created until it is closed.
+// Output is recorded as it is read from the session, so something must
+// keep reading it.
+type Recorder struct {
+	s      *Session
+	format recordFormat
+	start  time.Time
+	in     io.Writer // The input tap, if any.
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+func newRecorder(s *Session, start time.Time, f recordFormat, opts []RecordOption) *Recorder {
+	o := &recordOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	r := &Recorder{s: s, format: f, start: start}
+	s.addTap(r)
+	s.addResizeTap(r)
+	if in, ok := f.(inputFormat); ok && o.input {
+		r.in = recorderInput{r: r, format: in}
+		s.addInputTap(r.in)
+	}
+	return r
+}
+
+// recorderInput records the input written to the session.
+type recorderInput struct {
+	r      *Recorder
+	format inputFormat
+}
+
+func (i recorderInput) Write(p []byte) (int, error) {
+	i.r.record(func(t time.Duration) error { return i.format.input(t, p) })
+	return len(p), nil
+}
+
+// record calls fn with the current time of the recording unless recording
+// stopped or failed.
+func (r *Recorder) record(fn func(t time.Duration) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.err != nil {
+		return
+	}
+	r.err = fn(time.Since(r.start))
+}
+
+// initialSize returns the size of the pty of s, defaulting to 80x24 where
+// it is not available.
+func initialSize(s *Session) *Winsize {
+	ws, err := GetsizeFull(s.pty)
+	if err != nil {
+		return &Winsize{Rows: 24, Cols: 80}
+	}
+	return ws
+}
+
+// sessionTerm returns the TERM of the command of s, taken from the
+// environment of this process if the command inherits it.
+func sessionTerm(s *Session) string {
+	env := s.Cmd().Env
+	if env == nil {
+		return os.Getenv("TERM")
+	}
+	for i := len(env) - 1; i >= 0; i-- { // The last one wins, as in exec.
+		if strings.HasPrefix(env[i], "TERM=") {
+			return env[i][len("TERM="):]
+		}
+	}
+	return ""
+}
+
+// Write records the output p. It is called by the session as output is
+// read and never fails, the first error is returned by Close.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.record(func(t time.Duration) error { return r.format.output(t, p) })
+	return len(p), nil
+}
+
+// Resize resizes the session, which is recorded as any resize of the
+// session is, with Session.Resize or ResizeFrom.
+func (r *Recorder) Resize(ws *Winsize) error {
+	return r.s.Resize(ws)
+}
+
+// resized records the resize of the session to ws.
+func (r *Recorder) resized(ws *Winsize) {
+	r.record(func(t time.Duration) error { return r.format.resize(t, ws) })
+}
+
+// Close stops recording and returns the first error encountered writing
+// the recording, if any. It doesn't close the session.
+func (r *Recorder) Close() error {
+	r.s.removeTap(r)
+	r.s.removeResizeTap(r)
+	if r.in != nil {
+		r.s.removeInputTap(r.in)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return r.err
+	}
+	r.closed = true
+	if err := r.format.close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
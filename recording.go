@@ -0,0 +1,34 @@
+package pty
+
+import "time"
+
+// maxEventData bounds the data of the events loaded from recordings, for
+// malformed ones not to exhaust the memory.
+const maxEventData = 1 << 26
+
+// EventKind is the kind of an Event of a Recording.
+type EventKind int
+
+const (
+	// EventOutput is output of the command.
+	EventOutput EventKind = iota
+	// EventInput is input sent to the command.
+	EventInput
+	// EventResize is a resize of the pty.
+	EventResize
+)
+
+// Event is an event of a Recording.
+type Event struct {
+	Time time.Duration // Since the start of the recording.
+	Kind EventKind
+	Data []byte   // Output or input.
+	Size *Winsize // New size of a resize.
+}
+
+// Recording is a recording loaded by LoadAsciicast, LoadTtyrec or
+// LoadTypescript, ready to be played back.
+type Recording struct {
+	Size   *Winsize // Initial size, nil if unknown.
+	Events []Event  // Ordered by time.
+}
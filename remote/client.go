@@ -0,0 +1,192 @@
+package remote
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// ErrNoExit is returned by Client.Wait when the connection ended before
+// the exit code of the command was received.
+var ErrNoExit = errors.New("connection ended before the command exited")
+
+// Client is the local end of a session served by Serve. It implements
+// pty.File: reading it reads the output of the remote command, writing it
+// sends input. The output must be read for Wait to return.
+type Client struct {
+	t    transport
+	name string
+	out  net.Conn // The end of a net.Pipe the output is read from.
+
+	mu        sync.Mutex
+	closed    bool
+	wdeadline time.Time
+	title     string
+	code      int
+	err       error
+	exited    chan struct{}
+}
+
+// NewClient returns a Client for the session served over conn.
+func NewClient(conn io.ReadWriteCloser) *Client {
+	name := "remote"
+	if conn, ok := conn.(net.Conn); ok {
+		name = fmt.Sprintf("remote:%s", conn.RemoteAddr())
+	}
+	return newClient(&connTransport{conn: conn}, name)
+}
+
+func newClient(t transport, name string) *Client {
+	pr, pw := net.Pipe()
+	c := &Client{t: t, name: name, out: pr, exited: make(chan struct{})}
+	go c.receive(pw)
+	return c
+}
+
+func (c *Client) receive(pw net.Conn) {
+	err := ErrNoExit
+	defer func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		close(c.exited)
+		_ = pw.Close() // Read returns c.err instead of io.EOF.
+	}()
+	for {
+		typ, payload, rerr := c.t.recv()
+		if rerr != nil {
+			c.mu.Lock()
+			if rerr != io.EOF && !c.closed {
+				err = rerr
+			}
+			c.mu.Unlock()
+			return
+		}
+		switch typ {
+		case frameData:
+			if _, err := pw.Write(payload); err != nil {
+				return // Closed by Close.
+			}
+		case frameTitle:
+			c.mu.Lock()
+			c.title = string(payload)
+			c.mu.Unlock()
+		case frameExit:
+			if len(payload) >= 4 {
+				c.mu.Lock()
+				c.code = int(int32(binary.BigEndian.Uint32(payload)))
+				c.mu.Unlock()
+				err = nil
+				return
+			}
+		}
+	}
+}
+
+// Read reads the output of the command. It returns io.EOF once the command
+// exited.
+func (c *Client) Read(p []byte) (int, error) {
+	n, err := c.out.Read(p)
+	if err == io.EOF {
+		c.mu.Lock()
+		if c.err != nil {
+			err = c.err
+		}
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+// Write sends p as input to the command, in frames of at most maxFrame
+// bytes, the most the server accepts.
+func (c *Client) Write(p []byte) (int, error) {
+	n := 0
+	for {
+		c.mu.Lock()
+		d := c.wdeadline
+		c.mu.Unlock()
+		if !d.IsZero() && !time.Now().Before(d) {
+			return n, timeoutError{}
+		}
+		chunk := p[n:]
+		if len(chunk) > maxFrame {
+			chunk = chunk[:maxFrame]
+		}
+		if err := c.t.send(frameData, chunk); err != nil {
+			return n, err
+		}
+		if n += len(chunk); n == len(p) {
+			return n, nil
+		}
+	}
+}
+
+// Resize resizes the remote pty.
+func (c *Client) Resize(ws *pty.Winsize) error {
+	var buf [4]byte
+	binary.BigEndian.PutUint16(buf[:], ws.Rows)
+	binary.BigEndian.PutUint16(buf[2:], ws.Cols)
+	return c.t.send(frameResize, buf[:])
+}
+
+// Title returns the last window title set by the command.
+func (c *Client) Title() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.title
+}
+
+// Wait waits for the command to exit and returns its exit code, -1 if it
+// was killed by a signal.
+func (c *Client) Wait() (int, error) {
+	<-c.exited
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.code, c.err
+}
+
+// SetDeadline sets the read and write deadlines, see SetWriteDeadline.
+func (c *Client) SetDeadline(t time.Time) error {
+	_ = c.SetWriteDeadline(t)
+	return c.SetReadDeadline(t)
+}
+
+// SetReadDeadline sets the deadline of pending and future reads.
+func (c *Client) SetReadDeadline(t time.Time) error { return c.out.SetReadDeadline(t) }
+
+// SetWriteDeadline sets the deadline of future writes. A write already
+// sending its input is not interrupted, as that would cut a frame short and
+// corrupt the connection.
+func (c *Client) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.wdeadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+// timeoutError is returned by writes past their deadline. Like the errors
+// of the net package, os.IsTimeout is true for it.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Close closes the connection, hanging up the remote session.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	_ = c.out.Close()
+	return c.t.Close()
+}
+
+// Name returns "remote:" followed by the remote address of the connection,
+// if it is a net.Conn.
+func (c *Client) Name() string { return c.name }
@@ -0,0 +1,83 @@
+// Package remote serves a pty session over a net.Conn, or any other
+// io.ReadWriteCloser, and exposes it on the other end as a local pty.
+//
+// Both ends exchange frames made of a type byte, a big endian uint32
+// payload length and the payload:
+//
+//	data   both ways     The input or the output of the command.
+//	resize to server     Big endian uint16 rows and columns.
+//	title  to client     The window title set by the command.
+//	exit   to client     The big endian int32 exit code of the command.
+//
+// A Mux carries many sessions over a single connection instead. The
+// remotepb module serves them over gRPC, as defined by its remote.proto.
+package remote
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Frame types.
+const (
+	frameData   = 1
+	frameResize = 2
+	frameTitle  = 3
+	frameExit   = 4
+)
+
+// maxFrame bounds the payloads accepted from the other end.
+const maxFrame = 1 << 20
+
+var errFrameTooLarge = errors.New("frame too large")
+
+// transport carries the frames of a session, over a connection of its own
+// or over a channel of a Mux.
+type transport interface {
+	send(typ byte, payload []byte) error
+	recv() (byte, []byte, error)
+	Close() error
+}
+
+// connTransport is a transport over a connection of its own.
+type connTransport struct {
+	conn io.ReadWriteCloser
+	mu   sync.Mutex // Serializes the frames sent.
+}
+
+func (t *connTransport) send(typ byte, payload []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return writeFrame(t.conn, typ, payload)
+}
+
+func (t *connTransport) recv() (byte, []byte, error) { return readFrame(t.conn) }
+
+func (t *connTransport) Close() error { return t.conn.Close() }
+
+func writeFrame(w io.Writer, typ byte, payload []byte) error {
+	buf := make([]byte, 5+len(payload))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(payload)))
+	copy(buf[5:], payload)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > maxFrame {
+		return 0, nil, errFrameTooLarge
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	return hdr[0], payload, nil
+}
@@ -0,0 +1,386 @@
+package remote
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/creack/pty"
+)
+
+// Mux frame types, on top of the session ones.
+const (
+	frameOpen   = 5 // To server, the NUL separated arguments of the command.
+	frameClose  = 6 // Both ways, with the reason of a failed open to client.
+	frameWindow = 7 // Both ways, the big endian uint32 bytes read since the last one.
+)
+
+const (
+	// muxWindow bounds the bytes of the frames, headers included, sent on
+	// a channel and not read yet by the other end.
+	muxWindow = 256 << 10
+	// muxChunk bounds the data sent in a single frame.
+	muxChunk = 32 << 10
+	// maxMuxChannels bounds the channels open at once on each end.
+	maxMuxChannels = 256
+)
+
+var (
+	errTooManyChannels = errors.New("too many channels")
+	errWindowExceeded  = errors.New("channel window exceeded")
+	errUnexpectedOpen  = errors.New("unexpected open of a channel")
+)
+
+// Mux carries many sessions over a single connection, each in a channel
+// of its own, for clients behind firewalls letting a single connection
+// through. The frames of a Mux have a big endian uint32 channel ID between
+// their type and their length. A client opens sessions with Open, which
+// the server started with ServeMux starts.
+//
+// A channel whose frames are not read does not hold up the others: each
+// end only sends a channel the frames the other end has room for, and
+// waits for it to read them to send more.
+type Mux struct {
+	conn   io.ReadWriteCloser
+	accept func(ch *muxChannel, payload []byte) // Of the server.
+
+	wmu sync.Mutex // Serializes the frames sent.
+
+	mu    sync.Mutex
+	chans map[uint32]*muxChannel
+	next  uint32
+	err   error
+	done  chan struct{}
+}
+
+// NewMux returns a Mux opening sessions served by ServeMux over conn.
+func NewMux(conn io.ReadWriteCloser) *Mux {
+	m := newMux(conn, nil)
+	go func() { _ = m.read() }()
+	return m
+}
+
+// ServeMux serves the sessions opened by a Mux over conn, started by start
+// with the arguments given to Open, until conn fails. Each session is
+// served as by Serve.
+func ServeMux(conn io.ReadWriteCloser, start func(args []string) (*pty.Session, error)) error {
+	m := newMux(conn, func(ch *muxChannel, payload []byte) {
+		s, err := start(strings.Split(string(payload), "\x00"))
+		if err != nil {
+			ch.closeWith([]byte(err.Error()))
+			return
+		}
+		go func() { _ = serve(ch, s) }()
+	})
+	err := m.read()
+	_ = conn.Close()
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func newMux(conn io.ReadWriteCloser, accept func(*muxChannel, []byte)) *Mux {
+	return &Mux{conn: conn, accept: accept, chans: map[uint32]*muxChannel{}, done: make(chan struct{})}
+}
+
+// read dispatches the frames received to their channel until conn fails.
+func (m *Mux) read() error {
+	var err error
+	for {
+		var hdr [9]byte
+		if _, err = io.ReadFull(m.conn, hdr[:]); err != nil {
+			break
+		}
+		typ, id, n := hdr[0], binary.BigEndian.Uint32(hdr[1:]), binary.BigEndian.Uint32(hdr[5:])
+		if n > maxFrame {
+			err = errFrameTooLarge
+			break
+		}
+		payload := make([]byte, n)
+		if _, err = io.ReadFull(m.conn, payload); err != nil {
+			err = io.ErrUnexpectedEOF
+			break
+		}
+
+		m.mu.Lock()
+		ch := m.chans[id]
+		refused, opened := false, false
+		if ch == nil && typ == frameOpen && m.accept != nil {
+			if refused = len(m.chans) >= maxMuxChannels; !refused {
+				ch, opened = m.channel(id), true
+			}
+		}
+		m.mu.Unlock()
+		switch {
+		case refused:
+			_ = m.send(frameClose, id, []byte(errTooManyChannels.Error())) // Best effort.
+		case ch == nil:
+		case opened:
+			// Not to hold up the frames of the other channels while the
+			// session starts.
+			go m.accept(ch, payload)
+		case typ == frameOpen:
+			// Only a client opens channels, and only unused ones.
+			ch.closeWith([]byte(errUnexpectedOpen.Error()))
+		case typ == frameClose:
+			ch.closed(payload)
+		case typ == frameWindow:
+			if len(payload) >= 4 {
+				ch.grant(int(binary.BigEndian.Uint32(payload)))
+			}
+		default:
+			if !ch.push(muxFrame{typ: typ, payload: payload}) {
+				ch.closeWith([]byte(errWindowExceeded.Error()))
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.err = err
+	m.mu.Unlock()
+	close(m.done)
+	return err
+}
+
+// channel registers the channel id. m.mu must be held.
+func (m *Mux) channel(id uint32) *muxChannel {
+	ch := &muxChannel{
+		m:       m,
+		id:      id,
+		done:    make(chan struct{}),
+		credit:  muxWindow,
+		arrived: make(chan struct{}, 1),
+		granted: make(chan struct{}, 1),
+	}
+	m.chans[id] = ch
+	return ch
+}
+
+func (m *Mux) send(typ byte, id uint32, payload []byte) error {
+	buf := make([]byte, 9+len(payload))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:], id)
+	binary.BigEndian.PutUint32(buf[5:], uint32(len(payload)))
+	copy(buf[9:], payload)
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+	_, err := m.conn.Write(buf)
+	return err
+}
+
+// Open opens a session running the command with the given arguments, as
+// understood by the start function of the server.
+func (m *Mux) Open(args ...string) (*Client, error) {
+	m.mu.Lock()
+	if m.err != nil {
+		m.mu.Unlock()
+		return nil, m.err
+	}
+	if len(m.chans) >= maxMuxChannels {
+		m.mu.Unlock()
+		return nil, errTooManyChannels
+	}
+	m.next++
+	ch := m.channel(m.next)
+	m.mu.Unlock()
+
+	if err := m.send(frameOpen, ch.id, []byte(strings.Join(args, "\x00"))); err != nil {
+		ch.closed(nil)
+		return nil, err
+	}
+	return newClient(ch, fmt.Sprintf("remote:%d", ch.id)), nil
+}
+
+// Close closes the connection, hanging up all the sessions.
+func (m *Mux) Close() error {
+	return m.conn.Close()
+}
+
+type muxFrame struct {
+	typ     byte
+	payload []byte
+}
+
+// muxChannel is a transport over a channel of a Mux.
+type muxChannel struct {
+	m    *Mux
+	id   uint32
+	once sync.Once
+	err  error // The reason the other end closed the channel, if any.
+	done chan struct{}
+
+	mu      sync.Mutex
+	in      []muxFrame    // Received and not read yet.
+	queued  int           // The bytes of in, headers included.
+	unacked int           // The bytes read and not acknowledged yet.
+	credit  int           // The bytes the other end has room for.
+	arrived chan struct{} // Notified when in grows.
+	granted chan struct{} // Notified when credit grows.
+}
+
+// notify wakes up the goroutine waiting on c, if any, or the next one.
+func notify(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// frameSize returns the bytes a frame counts for in the window.
+func frameSize(payload []byte) int { return 9 + len(payload) }
+
+func (ch *muxChannel) send(typ byte, payload []byte) error {
+	for {
+		p := payload
+		if typ == frameData && len(p) > muxChunk {
+			p = p[:muxChunk]
+		}
+		if err := ch.take(frameSize(p)); err != nil {
+			return err
+		}
+		if err := ch.m.send(typ, ch.id, p); err != nil {
+			return err
+		}
+		if payload = payload[len(p):]; len(payload) == 0 {
+			return nil
+		}
+	}
+}
+
+// take waits for the other end to have room for n more bytes, and takes
+// them.
+func (ch *muxChannel) take(n int) error {
+	for {
+		select {
+		case <-ch.done:
+			return io.ErrClosedPipe
+		default:
+		}
+		ch.mu.Lock()
+		ok := ch.credit >= n
+		if ok {
+			ch.credit -= n
+		}
+		ch.mu.Unlock()
+		if ok {
+			return nil
+		}
+		select {
+		case <-ch.granted:
+		case <-ch.done:
+			return io.ErrClosedPipe
+		case <-ch.m.done:
+			return ch.m.err
+		}
+	}
+}
+
+// grant gives n more bytes of room in the other end.
+func (ch *muxChannel) grant(n int) {
+	ch.mu.Lock()
+	ch.credit += n
+	ch.mu.Unlock()
+	notify(ch.granted)
+}
+
+// push queues a frame received, unless the other end overflowed the
+// window.
+func (ch *muxChannel) push(f muxFrame) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.queued += frameSize(f.payload); ch.queued > muxWindow {
+		return false
+	}
+	ch.in = append(ch.in, f)
+	notify(ch.arrived)
+	return true
+}
+
+// pop dequeues the next frame received, if any, acknowledging the bytes
+// read once they make half the window.
+func (ch *muxChannel) pop() (muxFrame, bool) {
+	ch.mu.Lock()
+	if len(ch.in) == 0 {
+		ch.mu.Unlock()
+		return muxFrame{}, false
+	}
+	f := ch.in[0]
+	ch.in[0] = muxFrame{}
+	ch.in = ch.in[1:]
+	n := frameSize(f.payload)
+	ch.queued -= n
+	ch.unacked += n
+	ack := 0
+	if ch.unacked >= muxWindow/2 {
+		ack, ch.unacked = ch.unacked, 0
+	}
+	ch.mu.Unlock()
+
+	if ack > 0 {
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], uint32(ack))
+		_ = ch.m.send(frameWindow, ch.id, buf[:]) // Best effort.
+	}
+	return f, true
+}
+
+func (ch *muxChannel) recv() (byte, []byte, error) {
+	for {
+		if f, ok := ch.pop(); ok {
+			return f.typ, f.payload, nil
+		}
+		select {
+		case <-ch.arrived:
+			continue
+		case <-ch.done:
+		case <-ch.m.done:
+		}
+		break
+	}
+	// The frames received before the end are all queued by now.
+	if f, ok := ch.pop(); ok {
+		return f.typ, f.payload, nil
+	}
+	select {
+	case <-ch.done:
+		if ch.err != nil {
+			return 0, nil, ch.err
+		}
+		return 0, nil, io.EOF
+	default:
+		return 0, nil, ch.m.err
+	}
+}
+
+// closed unregisters the channel, closed by the other end for reason.
+func (ch *muxChannel) closed(reason []byte) {
+	ch.once.Do(func() {
+		if len(reason) > 0 {
+			ch.err = errors.New(string(reason))
+		}
+		ch.m.mu.Lock()
+		delete(ch.m.chans, ch.id)
+		ch.m.mu.Unlock()
+		close(ch.done)
+	})
+}
+
+// closeWith closes the channel, telling the other end the reason.
+func (ch *muxChannel) closeWith(reason []byte) {
+	select {
+	case <-ch.done:
+		return
+	default:
+	}
+	_ = ch.m.send(frameClose, ch.id, reason) // Best effort.
+	ch.closed(nil)
+}
+
+func (ch *muxChannel) Close() error {
+	ch.closeWith(nil)
+	return nil
+}
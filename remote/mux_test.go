@@ -0,0 +1,269 @@
+package remote
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+func TestMux(t *testing.T) {
+	t.Parallel()
+
+	sconn, cconn := net.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- ServeMux(sconn, func(args []string) (*pty.Session, error) {
+			if args[0] != "echo" {
+				return nil, errors.New("not allowed")
+			}
+			return pty.StartSession(exec.Command(args[0], args[1:]...))
+		})
+	}()
+
+	m := NewMux(cconn)
+	clients := make([]*Client, 3)
+	for i := range clients {
+		c, err := m.Open("echo", "session", string(rune('a'+i)))
+		if err != nil {
+			t.Fatalf("Unexpected error from Open: %s", err)
+		}
+		clients[i] = c
+	}
+	for i, c := range clients {
+		out, err := ioutil.ReadAll(c)
+		if err != nil {
+			t.Fatalf("Unexpected error from ReadAll: %s", err)
+		}
+		if want := "session " + string(rune('a'+i)); !strings.Contains(string(out), want) {
+			t.Errorf("Unexpected output of %d, got %q, want %q", i, out, want)
+		}
+		if code, err := c.Wait(); code != 0 || err != nil {
+			t.Errorf("Unexpected exit of %d, got %d, %v", i, code, err)
+		}
+	}
+
+	c, err := m.Open("sh")
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	if _, err := c.Wait(); err == nil || err.Error() != "not allowed" {
+		t.Errorf("Unexpected error from Wait of a refused session, got %v", err)
+	}
+
+	_ = m.Close()
+	if err := <-served; err != nil {
+		t.Errorf("Unexpected error from ServeMux: %s", err)
+	}
+}
+
+func TestMuxSlowChannel(t *testing.T) {
+	t.Parallel()
+
+	sconn, cconn := net.Pipe()
+	go func() {
+		_ = ServeMux(sconn, func(args []string) (*pty.Session, error) {
+			return pty.StartSession(exec.Command(args[0], args[1:]...))
+		})
+	}()
+	m := NewMux(cconn)
+	defer func() { _ = m.Close() }()
+
+	// The output of slow, many windows long, is not read until fast ended.
+	slow, err := m.Open("head", "-c", "1000000", "/dev/zero")
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	fast, err := m.Open("echo", "fast")
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if out, err := ioutil.ReadAll(fast); err != nil || !strings.Contains(string(out), "fast") {
+			t.Errorf("Unexpected output of the fast channel, got %q, %v", out, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for the fast channel")
+	}
+
+	out, err := ioutil.ReadAll(slow)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadAll: %s", err)
+	}
+	if len(out) != 1000000 {
+		t.Errorf("Unexpected output length of the slow channel, got %d expected %d", len(out), 1000000)
+	}
+}
+
+func TestMuxSlowStart(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	defer close(release)
+	sconn, cconn := net.Pipe()
+	go func() {
+		_ = ServeMux(sconn, func(args []string) (*pty.Session, error) {
+			if args[0] == "slow" {
+				<-release
+				return nil, errors.New("released")
+			}
+			return pty.StartSession(exec.Command(args[0], args[1:]...))
+		})
+	}()
+	m := NewMux(cconn)
+	defer func() { _ = m.Close() }()
+
+	// The session of fast starts while the one of slow still does.
+	if _, err := m.Open("slow"); err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	fast, err := m.Open("echo", "fast")
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if out, err := ioutil.ReadAll(fast); err != nil || !strings.Contains(string(out), "fast") {
+			t.Errorf("Unexpected output of the fast channel, got %q, %v", out, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for the fast channel")
+	}
+}
+
+func TestMuxTooManyChannels(t *testing.T) {
+	t.Parallel()
+
+	sconn, cconn := net.Pipe()
+	go func() { _, _ = io.Copy(ioutil.Discard, sconn) }()
+	m := NewMux(cconn)
+	defer func() { _ = m.Close() }()
+
+	for i := 0; i < maxMuxChannels; i++ {
+		if _, err := m.Open("true"); err != nil {
+			t.Fatalf("Unexpected error from Open: %s", err)
+		}
+	}
+	if _, err := m.Open("true"); err != errTooManyChannels {
+		t.Errorf("Unexpected error from Open, got %v expected %v", err, errTooManyChannels)
+	}
+}
+
+// writeMuxFrame sends a raw mux frame on conn, as a misbehaving peer.
+func writeMuxFrame(conn io.Writer, typ byte, id uint32, payload []byte) error {
+	buf := make([]byte, 9+len(payload))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:], id)
+	binary.BigEndian.PutUint32(buf[5:], uint32(len(payload)))
+	copy(buf[9:], payload)
+	_, err := conn.Write(buf)
+	return err
+}
+
+// readMuxFrame reads a raw mux frame from conn.
+func readMuxFrame(conn io.Reader) (typ byte, id uint32, payload []byte, err error) {
+	var hdr [9]byte
+	if _, err := io.ReadFull(conn, hdr[:]); err != nil {
+		return 0, 0, nil, err
+	}
+	payload = make([]byte, binary.BigEndian.Uint32(hdr[5:]))
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return 0, 0, nil, err
+	}
+	return hdr[0], binary.BigEndian.Uint32(hdr[1:]), payload, nil
+}
+
+// TestMuxClientUnexpectedOpen ensures that a client closes a channel its
+// server tries to open again, rather than accepting it.
+func TestMuxClientUnexpectedOpen(t *testing.T) {
+	t.Parallel()
+
+	sconn, cconn := net.Pipe()
+	m := NewMux(cconn)
+	defer func() { _ = m.Close() }()
+
+	opened := make(chan uint32, 1)
+	go func() {
+		typ, id, _, err := readMuxFrame(sconn)
+		if err != nil || typ != frameOpen {
+			t.Errorf("Unexpected frame, got %d, %v expected an open", typ, err)
+		}
+		opened <- id
+	}()
+	c, err := m.Open("true")
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	id := <-opened
+	if err := writeMuxFrame(sconn, frameOpen, id, nil); err != nil {
+		t.Fatalf("Unexpected error from writeMuxFrame: %s", err)
+	}
+	typ, _, payload, err := readMuxFrame(sconn)
+	if err != nil || typ != frameClose || string(payload) != errUnexpectedOpen.Error() {
+		t.Errorf("Unexpected frame, got %d %q, %v expected a close", typ, payload, err)
+	}
+	if _, err := c.Read(make([]byte, 1)); err == nil {
+		t.Error("Expected an error reading the closed channel")
+	}
+}
+
+// TestMuxServerUnexpectedOpen ensures that a server starts a single session
+// for a channel opened twice.
+func TestMuxServerUnexpectedOpen(t *testing.T) {
+	t.Parallel()
+
+	sconn, cconn := net.Pipe()
+	defer func() { _ = cconn.Close() }()
+	started := make(chan struct{}, 2)
+	go func() {
+		_ = ServeMux(sconn, func(args []string) (*pty.Session, error) {
+			started <- struct{}{}
+			return pty.StartSession(exec.Command("cat"))
+		})
+	}()
+
+	for i := 0; i < 2; i++ {
+		if err := writeMuxFrame(cconn, frameOpen, 1, []byte("cat")); err != nil {
+			t.Fatalf("Unexpected error from writeMuxFrame: %s", err)
+		}
+	}
+	for {
+		typ, id, payload, err := readMuxFrame(cconn)
+		if err != nil {
+			t.Fatalf("Unexpected error from readMuxFrame: %s", err)
+		}
+		if typ == frameClose && id == 1 {
+			if string(payload) != errUnexpectedOpen.Error() {
+				t.Errorf("Unexpected close reason, got %q expected %q", payload, errUnexpectedOpen)
+			}
+			break
+		}
+	}
+	// The session is started off the read loop, possibly after the close.
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for the session to start")
+	}
+	select {
+	case <-started:
+		t.Error("Unexpected second session started")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
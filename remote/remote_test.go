@@ -0,0 +1,119 @@
+package remote
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+func TestRemote(t *testing.T) {
+	t.Parallel()
+
+	s, err := pty.StartSession(exec.Command("sh", "-c", `printf "\033]0;build\007ready\n"; read l; echo "got $l"; stty size; exit 3`))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	sconn, cconn := net.Pipe()
+	served := make(chan error, 1)
+	go func() { served <- Serve(sconn, s) }()
+
+	c := NewClient(cconn)
+	defer func() { _ = c.Close() }()
+	if err := c.Resize(&pty.Winsize{Rows: 40, Cols: 120}); err != nil {
+		t.Fatalf("Unexpected error from Resize: %s", err)
+	}
+	if _, err := c.Write([]byte("hi\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	out, err := ioutil.ReadAll(c)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadAll: %s", err)
+	}
+	if !strings.Contains(string(out), "got hi") || !strings.Contains(string(out), "40 120") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+	if code, err := c.Wait(); err != nil || code != 3 {
+		t.Errorf("Unexpected exit from Wait, got %d, %v", code, err)
+	}
+	if title := c.Title(); title != "build" {
+		t.Errorf("Unexpected title %q", title)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Unexpected error from Serve: %s", err)
+	}
+}
+
+func TestRemoteHangup(t *testing.T) {
+	t.Parallel()
+
+	s, err := pty.StartSession(exec.Command("sleep", "60"))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	sconn, cconn := net.Pipe()
+	served := make(chan error, 1)
+	go func() { served <- Serve(sconn, s) }()
+
+	c := NewClient(cconn)
+	_ = c.Close()
+	<-served
+	if _, err := c.Wait(); err != ErrNoExit {
+		t.Errorf("Unexpected error from Wait, got %v", err)
+	}
+}
+
+var _ pty.File = (*Client)(nil)
+
+func TestClientDeadline(t *testing.T) {
+	t.Parallel()
+
+	sconn, cconn := net.Pipe()
+	defer func() { _ = sconn.Close() }()
+	c := NewClient(cconn)
+	defer func() { _ = c.Close() }()
+
+	if err := c.SetDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatalf("Unexpected error from SetDeadline: %s", err)
+	}
+	if _, err := c.Read(make([]byte, 1)); !os.IsTimeout(err) {
+		t.Errorf("Unexpected error from Read past the deadline, got %v expected a timeout", err)
+	}
+	if _, err := c.Write([]byte("x")); !os.IsTimeout(err) {
+		t.Errorf("Unexpected error from Write past the deadline, got %v expected a timeout", err)
+	}
+}
+
+func TestClientLargeWrite(t *testing.T) {
+	t.Parallel()
+
+	sconn, cconn := net.Pipe()
+	defer func() { _ = sconn.Close() }()
+	c := NewClient(cconn)
+	defer func() { _ = c.Close() }()
+
+	input := make([]byte, maxFrame+maxFrame/2)
+	written := make(chan error, 1)
+	go func() {
+		_, err := c.Write(input)
+		written <- err
+	}()
+	for n := 0; n < len(input); {
+		typ, payload, err := readFrame(sconn)
+		if err != nil {
+			t.Fatalf("Unexpected error from readFrame: %s", err)
+		}
+		if typ != frameData {
+			t.Fatalf("Unexpected frame type, got %d expected %d", typ, frameData)
+		}
+		n += len(payload)
+	}
+	if err := <-written; err != nil {
+		t.Errorf("Unexpected error from Write: %s", err)
+	}
+}
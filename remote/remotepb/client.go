@@ -0,0 +1,139 @@
+package remotepb
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/creack/pty"
+	"github.com/creack/pty/remote"
+	"google.golang.org/grpc"
+)
+
+// inputChunk bounds the input sent in a single message.
+const inputChunk = 32 << 10
+
+// Client is the local end of an Attach stream. Reading it reads the output
+// of the remote command, writing it sends input. The output must be read
+// for Wait to return.
+type Client struct {
+	stream Pty_AttachClient
+	cancel context.CancelFunc
+	out    *io.PipeReader
+
+	wmu sync.Mutex // Serializes the messages sent.
+
+	mu     sync.Mutex
+	title  string
+	code   int
+	err    error
+	exited chan struct{}
+}
+
+// Attach opens an Attach stream with c, running the command of st, and
+// returns a Client for it. ctx applies to the whole stream: canceling it
+// hangs up the session. The errors of the server, such as those of its
+// interceptors, are returned by Read and Wait.
+func Attach(ctx context.Context, c PtyClient, st *Start, opts ...grpc.CallOption) (*Client, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.Attach(ctx, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := stream.Send(&ClientMessage{Msg: &ClientMessage_Start{Start: st}}); err != nil && err != io.EOF {
+		cancel()
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	cl := &Client{stream: stream, cancel: cancel, out: pr, exited: make(chan struct{})}
+	go cl.receive(pw)
+	return cl, nil
+}
+
+func (c *Client) receive(pw *io.PipeWriter) {
+	err := remote.ErrNoExit
+	defer func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		close(c.exited)
+		_ = pw.CloseWithError(err) // Read returns io.EOF once the command exited.
+	}()
+	for {
+		msg, rerr := c.stream.Recv()
+		if rerr != nil {
+			if rerr != io.EOF {
+				err = rerr
+			}
+			return
+		}
+		switch m := msg.Msg.(type) {
+		case *ServerMessage_Output:
+			if _, err := pw.Write(m.Output); err != nil {
+				return // Closed by Close.
+			}
+		case *ServerMessage_Title:
+			c.mu.Lock()
+			c.title = m.Title
+			c.mu.Unlock()
+		case *ServerMessage_Exit:
+			c.mu.Lock()
+			c.code = int(m.Exit)
+			c.mu.Unlock()
+			err = nil
+			return
+		}
+	}
+}
+
+// Read reads the output of the command. It returns io.EOF once the command
+// exited.
+func (c *Client) Read(p []byte) (int, error) { return c.out.Read(p) }
+
+// Write sends p as input to the command.
+func (c *Client) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	n := 0
+	for n < len(p) {
+		chunk := p[n:]
+		if len(chunk) > inputChunk {
+			chunk = chunk[:inputChunk]
+		}
+		if err := c.stream.Send(&ClientMessage{Msg: &ClientMessage_Input{Input: chunk}}); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+	}
+	return n, nil
+}
+
+// Resize resizes the remote pty.
+func (c *Client) Resize(ws *pty.Winsize) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.stream.Send(&ClientMessage{Msg: &ClientMessage_Resize{Resize: &Resize{Rows: uint32(ws.Rows), Cols: uint32(ws.Cols)}}})
+}
+
+// Title returns the last window title set by the command.
+func (c *Client) Title() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.title
+}
+
+// Wait waits for the command to exit and returns its exit code, -1 if it
+// was killed by a signal.
+func (c *Client) Wait() (int, error) {
+	<-c.exited
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.code, c.err
+}
+
+// Close hangs up the remote session.
+func (c *Client) Close() error {
+	c.cancel()
+	return c.out.Close()
+}
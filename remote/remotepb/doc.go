@@ -0,0 +1,9 @@
+// Package remotepb holds the gRPC bindings of the Pty service of
+// remote.proto, generated by protoc-gen-go and protoc-gen-go-grpc, and
+// adapts a pty.Session to them: NewServer serves the service on a
+// grpc.Server, whose interceptors authenticate the callers, and Attach
+// calls it. It is a module of its own, so that the pty module does not
+// depend on gRPC.
+package remotepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative remote.proto
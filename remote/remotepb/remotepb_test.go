@@ -0,0 +1,129 @@
+package remotepb
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authorize is a stream interceptor letting the callers with the secret
+// through.
+func authorize(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(ss.Context())
+	if v := md.Get("authorization"); len(v) != 1 || v[0] != "Bearer secret" {
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return handler(srv, ss)
+}
+
+func TestAttach(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error from Listen: %s", err)
+	}
+	gs := grpc.NewServer(grpc.StreamInterceptor(authorize))
+	RegisterPtyServer(gs, NewServer(func(_ context.Context, st *Start) (*pty.Session, error) {
+		return pty.StartSession(exec.Command(st.Argv[0], st.Argv[1:]...), pty.WithSize(Winsize(st.Size)))
+	}))
+	go func() { _ = gs.Serve(l) }()
+	defer gs.Stop()
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Unexpected error from NewClient: %s", err)
+	}
+	defer func() { _ = conn.Close() }()
+	pc := NewPtyClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	st := &Start{
+		Argv: []string{"sh", "-c", `printf "\033]0;build\007ready\n"; read l; echo "got $l"; stty size; exit 3`},
+		Size: &Resize{Rows: 12, Cols: 34},
+	}
+
+	// Without credentials, the interceptor fails the stream.
+	c, err := Attach(ctx, pc, st)
+	if err != nil {
+		t.Fatalf("Unexpected error from Attach: %s", err)
+	}
+	if _, err := c.Wait(); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Unexpected error from Wait without credentials, got %v", err)
+	}
+	_ = c.Close()
+
+	c, err = Attach(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret"), pc, st)
+	if err != nil {
+		t.Fatalf("Unexpected error from Attach: %s", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	buf := make([]byte, 64)
+	for read := ""; !strings.Contains(read, "ready"); {
+		n, err := c.Read(buf)
+		if err != nil {
+			t.Fatalf("Unexpected error from Read: %s", err)
+		}
+		read += string(buf[:n])
+	}
+	if _, err := c.Write([]byte("hi\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	out, err := ioutil.ReadAll(c)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadAll: %s", err)
+	}
+	if !strings.Contains(string(out), "got hi") || !strings.Contains(string(out), "12 34") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+	if code, err := c.Wait(); code != 3 || err != nil {
+		t.Errorf("Unexpected exit, got %d, %v expected 3", code, err)
+	}
+	if title := c.Title(); title != "build" {
+		t.Errorf("Unexpected title, got %q expected %q", title, "build")
+	}
+}
+
+func TestAttachNoStart(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error from Listen: %s", err)
+	}
+	gs := grpc.NewServer()
+	RegisterPtyServer(gs, NewServer(func(context.Context, *Start) (*pty.Session, error) {
+		t.Error("Unexpected start without a command")
+		return nil, nil
+	}))
+	go func() { _ = gs.Serve(l) }()
+	defer gs.Stop()
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Unexpected error from NewClient: %s", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	c, err := Attach(context.Background(), NewPtyClient(conn), &Start{})
+	if err != nil {
+		t.Fatalf("Unexpected error from Attach: %s", err)
+	}
+	defer func() { _ = c.Close() }()
+	if _, err := c.Wait(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Unexpected error from Wait, got %v expected %v", err, codes.InvalidArgument)
+	}
+}
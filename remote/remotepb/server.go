@@ -0,0 +1,124 @@
+package remotepb
+
+import (
+	"context"
+
+	"github.com/creack/pty"
+	"github.com/creack/pty/vt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type server struct {
+	UnimplementedPtyServer
+	start func(ctx context.Context, st *Start) (*pty.Session, error)
+}
+
+// NewServer returns a PtyServer, to register on a grpc.Server with
+// RegisterPtyServer, starting the session asked for by the Start message
+// opening each Attach stream with start, or failing the stream with its
+// error. start is given the context of the stream, holding its metadata
+// and what the interceptors of the server added to it. Its Size is left
+// to start to apply, see Winsize.
+//
+// Each session is served as by remote.Serve: the caller hanging up closes
+// it, and the stream ends with the exit code of the command once it
+// exited. The sessions must not be waited for elsewhere.
+func NewServer(start func(ctx context.Context, st *Start) (*pty.Session, error)) PtyServer {
+	return &server{start: start}
+}
+
+// Winsize returns the window size of r, nil if r is nil, the sizes beyond
+// those of a pty.Winsize clamped.
+func Winsize(r *Resize) *pty.Winsize {
+	if r == nil {
+		return nil
+	}
+	clamp := func(v uint32) uint16 {
+		if v > 0xffff {
+			return 0xffff
+		}
+		return uint16(v)
+	}
+	return &pty.Winsize{Rows: clamp(r.Rows), Cols: clamp(r.Cols)}
+}
+
+func (srv *server) Attach(stream Pty_AttachServer) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	st := msg.GetStart()
+	if st == nil || len(st.Argv) == 0 {
+		return status.Error(codes.InvalidArgument, "the first message must be a Start")
+	}
+	s, err := srv.start(stream.Context(), st)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		serveInput(stream, s)
+		_ = s.Close() // Best effort.
+	}()
+
+	err = serveOutput(stream, s)
+	_ = s.Close()
+	code := 0
+	if werr := s.Wait(); werr != nil {
+		code = -1
+		if ps := s.Cmd().ProcessState; ps != nil {
+			code = ps.ExitCode()
+		}
+	}
+	if err == nil {
+		err = stream.Send(&ServerMessage{Msg: &ServerMessage_Exit{Exit: int32(code)}})
+	}
+	// Returning ends the stream, and the pending Recv of serveInput.
+	return err
+}
+
+// serveInput applies the messages sent by the caller until it hangs up.
+func serveInput(stream Pty_AttachServer, s *pty.Session) {
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return
+		}
+		switch m := msg.Msg.(type) {
+		case *ClientMessage_Input:
+			if _, err := s.Write(m.Input); err != nil {
+				return
+			}
+		case *ClientMessage_Resize:
+			_ = s.Resize(Winsize(m.Resize)) // Best effort.
+		}
+	}
+}
+
+// serveOutput sends the output of the command, and the titles it sets,
+// until it ends. It only fails if sending does.
+func serveOutput(stream Pty_AttachServer, s *pty.Session) error {
+	var titles vt.OSCScanner
+	var werr error
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.Read(buf)
+		if n > 0 {
+			titles.Scan(buf[:n], func(osc []byte) {
+				if werr == nil && len(osc) >= 2 && (osc[0] == '0' || osc[0] == '2') && osc[1] == ';' {
+					werr = stream.Send(&ServerMessage{Msg: &ServerMessage_Title{Title: string(osc[2:])}})
+				}
+			})
+			if werr == nil {
+				werr = stream.Send(&ServerMessage{Msg: &ServerMessage_Output{Output: buf[:n]}})
+			}
+			if werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			return nil // EIO once the command exited on Linux.
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package remote
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/creack/pty"
+	"github.com/creack/pty/vt"
+)
+
+// Serve serves s over conn until the command exits or the connection fails,
+// whichever comes first, then closes both. The client hanging up closes the
+// session, which sends SIGHUP to the command on Unix. Serve waits for the
+// command, whose exit code is sent to the client, so it must not be waited
+// for elsewhere. It returns the error sending to conn, if any.
+func Serve(conn io.ReadWriteCloser, s *pty.Session) error {
+	return serve(&connTransport{conn: conn}, s)
+}
+
+func serve(t transport, s *pty.Session) error {
+	done := make(chan struct{})
+	go func() {
+		_ = serveInput(t, s)
+		_ = s.Close() // Best effort.
+		close(done)
+	}()
+
+	err := serveOutput(t, s)
+	_ = s.Close()
+	code := 0
+	if werr := s.Wait(); werr != nil {
+		code = -1
+		if ps := s.Cmd().ProcessState; ps != nil {
+			code = ps.ExitCode()
+		}
+	}
+	if err == nil {
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], uint32(int32(code)))
+		err = t.send(frameExit, buf[:])
+	}
+	_ = t.Close()
+	<-done
+	return err
+}
+
+// serveInput applies the frames sent by the client until it hangs up or
+// sends an invalid frame.
+func serveInput(t transport, s *pty.Session) error {
+	for {
+		typ, payload, err := t.recv()
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case frameData:
+			if _, err := s.Write(payload); err != nil {
+				return err
+			}
+		case frameResize:
+			if len(payload) < 4 {
+				continue
+			}
+			ws := &pty.Winsize{Rows: binary.BigEndian.Uint16(payload), Cols: binary.BigEndian.Uint16(payload[2:])}
+			_ = s.Resize(ws) // Best effort.
+		}
+	}
+}
+
+// serveOutput sends the output of the command, and the titles it sets,
+// until it ends. It only fails if sending does.
+func serveOutput(t transport, s *pty.Session) error {
+	var titles vt.OSCScanner
+	var werr error
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.Read(buf)
+		if n > 0 {
+			titles.Scan(buf[:n], func(osc []byte) {
+				if werr == nil && len(osc) >= 2 && (osc[0] == '0' || osc[0] == '2') && osc[1] == ';' {
+					werr = t.send(frameTitle, osc[2:])
+				}
+			})
+			if werr == nil {
+				werr = t.send(frameData, buf[:n])
+			}
+			if werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			return nil // EIO once the command exited on Linux.
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package pty
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// replayDrainTimeout bounds how long ReplayAndVerify keeps reading once all
+// the input was replayed.
+var replayDrainTimeout = time.Second
+
+// ReplayMismatchError is returned by ReplayAndVerify when the output of the
+// command differs from the recorded one.
+type ReplayMismatchError struct {
+	Offset int    // Of the first differing byte.
+	Want   []byte // Recorded output from Offset.
+	Got    []byte // Output from Offset.
+}
+
+func (e *ReplayMismatchError) Error() string {
+	want, got := e.Want, e.Got
+	if len(want) > 32 {
+		want = want[:32]
+	}
+	if len(got) > 32 {
+		got = got[:32]
+	}
+	return fmt.Sprintf("replay output differs at offset %d: got %q, want %q", e.Offset, got, want)
+}
+
+// Replay feeds the input recorded in rec to s with its original timing,
+// applying its resizes too, to reproduce a recorded session with a new
+// command. It returns once all the input is written. See Player for the
+// options.
+//
+// Input is only recorded in asciicast recordings made with RecordInput.
+func Replay(ctx context.Context, s *Session, rec *Recording, opts ...PlayerOption) error {
+	opts = append([]PlayerOption{PlayInput()}, opts...)
+	return NewPlayer(rec, s, opts...).Play(ctx)
+}
+
+// ReplayAndVerify replays rec to s like Replay while reading the output of
+// s, and checks that it matches the recorded output. It returns a
+// *ReplayMismatchError as soon as the output differs, or when less output
+// than recorded was read a second after all the input was replayed, and
+// otherwise returns once as much output as recorded was read.
+//
+// Only the recorded amount of output is read, any output beyond it is left
+// for the caller to read.
+func ReplayAndVerify(ctx context.Context, s *Session, rec *Recording, opts ...PlayerOption) error {
+	var want []byte
+	for _, ev := range rec.Events {
+		if ev.Kind == EventOutput {
+			want = append(want, ev.Data...)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		got []byte
+	)
+	read := make(chan error, 1)
+	go func() {
+		buf := make([]byte, chunkSize)
+		for {
+			mu.Lock()
+			n := len(want) - len(got)
+			mu.Unlock()
+			if n == 0 {
+				read <- nil
+				return
+			}
+			if n > len(buf) {
+				n = len(buf)
+			}
+			n, err := ReadContext(ctx, sessionOutput{s: s}, buf[:n])
+			mu.Lock()
+			got = append(got, buf[:n]...)
+			mismatch := !bytes.HasPrefix(want, got)
+			mu.Unlock()
+			if mismatch || err != nil {
+				read <- err
+				return
+			}
+		}
+	}()
+
+	replayed := make(chan error, 1)
+	go func() { replayed <- Replay(ctx, s, rec, opts...) }()
+
+	var err error
+	select {
+	case err = <-read:
+	case err = <-replayed:
+		if err != nil {
+			break
+		}
+		t := time.NewTimer(replayDrainTimeout)
+		select {
+		case err = <-read:
+		case <-t.C:
+			cancel()
+			if err = <-read; err == context.Canceled {
+				err = nil // The output is short, reported below.
+			}
+		}
+		t.Stop()
+	}
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range got {
+		if got[i] != want[i] {
+			return &ReplayMismatchError{Offset: i, Want: want[i:], Got: got[i:]}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(got) < len(want) {
+		return &ReplayMismatchError{Offset: len(got), Want: want[len(got):]}
+	}
+	return nil
+}
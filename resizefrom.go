@@ -0,0 +1,73 @@
+package pty
+
+import (
+	"sync"
+	"time"
+)
+
+// resizeMaxWait bounds how long ResizeFrom delays a size, in debounces, for
+// a window dragged continuously to be resized along the way.
+const resizeMaxWait = 4
+
+// ResizeFrom resizes the session to the sizes received from ch, from a new
+// goroutine, until ch is closed or the returned function is called. This
+// replaces the goroutine SSH servers otherwise write for each session,
+// e.g. over the window channel returned by the Pty method of a
+// charmbracelet/ssh session, converted to Winsize, or the "window-change"
+// requests parsed with ParseWindowChange.
+//
+// A size is only applied once no other one was received for debounce, so
+// that a window being dragged does not resize the session, and redraw its
+// command, at every step, nor later than four times debounce after the
+// first size not applied yet. The last size is applied when ch is closed,
+// and dropped when stop is called.
+func (s *Session) ResizeFrom(ch <-chan Winsize, debounce time.Duration) (stop func()) {
+	stopc := make(chan struct{})
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		defer close(done)
+		var (
+			ws       Winsize
+			pending  bool
+			deadline time.Time // Of the pending size.
+			timer    <-chan time.Time
+		)
+		apply := func() {
+			if pending {
+				logErr("pty: resize session", s.Resize(&ws)) // Best effort.
+				pending = false
+			}
+		}
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					apply()
+					return
+				}
+				if !pending {
+					deadline = time.Now().Add(resizeMaxWait * debounce)
+				}
+				ws, pending = v, true
+				if debounce <= 0 {
+					apply()
+					continue
+				}
+				d := debounce
+				if left := time.Until(deadline); left < d {
+					d = left
+				}
+				timer = time.After(d)
+			case <-timer:
+				apply()
+			case <-stopc:
+				return
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() { close(stopc) })
+		<-done
+	}
+}
@@ -0,0 +1,85 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestResizeFrom(t *testing.T) {
+	t.Parallel()
+
+	tracer := &recordTracer{}
+	s, err := StartSession(exec.Command("cat"), WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	defer func() {
+		_ = s.Close()
+		_ = s.Cmd().Process.Kill()
+		_ = s.Wait()
+	}()
+
+	ch := make(chan Winsize)
+	stop := s.ResizeFrom(ch, time.Minute)
+	defer stop()
+	for i := uint16(1); i <= 10; i++ {
+		ch <- Winsize{Rows: i, Cols: 2 * i}
+	}
+	close(ch) // Applies the last size, without waiting for a minute.
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ws, err := GetsizeFull(s.Pty())
+		if err != nil {
+			t.Fatalf("Unexpected error from GetsizeFull: %s", err)
+		}
+		if ws.Rows == 10 && ws.Cols == 20 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Unexpected size, got %dx%d expected 10x20", ws.Rows, ws.Cols)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if got := len(tracer.ops) - 2; got != 1 { // After open and start.
+		t.Errorf("Unexpected number of resizes, got %d expected 1: %q", got, tracer.ops)
+	}
+}
+
+func TestResizeFromContinuous(t *testing.T) {
+	t.Parallel()
+
+	s, err := StartSession(exec.Command("cat"), WithSize(&Winsize{Rows: 1, Cols: 1}))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	defer func() {
+		_ = s.Close()
+		_ = s.Cmd().Process.Kill()
+		_ = s.Wait()
+	}()
+
+	ch := make(chan Winsize)
+	stop := s.ResizeFrom(ch, 50*time.Millisecond)
+	defer stop()
+	defer stop() // Stopping twice is fine.
+
+	// Sizes keep coming faster than the debounce, yet some are applied.
+	for i := uint16(2); i < 100; i++ {
+		ch <- Winsize{Rows: i, Cols: i}
+		time.Sleep(10 * time.Millisecond)
+	}
+	ws, err := GetsizeFull(s.Pty())
+	if err != nil {
+		t.Fatalf("Unexpected error from GetsizeFull: %s", err)
+	}
+	if ws.Rows == 1 {
+		t.Error("Unexpected size, no size was applied while resizing continuously")
+	}
+}
@@ -0,0 +1,72 @@
+package pty
+
+import "sync"
+
+// RingBuffer is an io.Writer retaining the last bytes written to it, up to
+// its size. It is safe for concurrent use.
+type RingBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	start int // Index of the oldest byte.
+	n     int // Number of bytes retained.
+}
+
+// NewRingBuffer returns a RingBuffer retaining up to size bytes. A size of
+// zero or less retains nothing.
+func NewRingBuffer(size int) *RingBuffer {
+	if size < 0 {
+		size = 0
+	}
+	return &RingBuffer{buf: make([]byte, size)}
+}
+
+// Write appends p, discarding the oldest bytes when full. It never fails.
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	written := len(p)
+	size := len(r.buf)
+	if size == 0 {
+		return written, nil
+	}
+	if len(p) >= size {
+		copy(r.buf, p[len(p)-size:])
+		r.start, r.n = 0, size
+		return written, nil
+	}
+	end := (r.start + r.n) % size
+	c := copy(r.buf[end:], p)
+	copy(r.buf, p[c:])
+	r.n += len(p)
+	if r.n > size {
+		r.start = (r.start + r.n - size) % size
+		r.n = size
+	}
+	return written, nil
+}
+
+// Bytes returns a copy of the retained bytes, oldest first.
+func (r *RingBuffer) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]byte, r.n)
+	c := copy(out, r.buf[r.start:])
+	copy(out[c:], r.buf)
+	return out
+}
+
+// Len returns the number of bytes retained.
+func (r *RingBuffer) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.n
+}
+
+// Reset discards the retained bytes.
+func (r *RingBuffer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start, r.n = 0, 0
+}
@@ -0,0 +1,44 @@
+package pty
+
+import "testing"
+
+func TestRingBuffer(t *testing.T) {
+	t.Parallel()
+
+	r := NewRingBuffer(8)
+	for _, tc := range []struct {
+		write, expect string
+	}{
+		{write: "abc", expect: "abc"},
+		{write: "defgh", expect: "abcdefgh"},
+		{write: "ij", expect: "cdefghij"},
+		{write: "klmnopqrstu", expect: "nopqrstu"},
+		{write: "v", expect: "opqrstuv"},
+	} {
+		if n, err := r.Write([]byte(tc.write)); err != nil || n != len(tc.write) {
+			t.Errorf("Unexpected result from Write(%q), got %d, %v", tc.write, n, err)
+		}
+		if got := string(r.Bytes()); got != tc.expect {
+			t.Errorf("Unexpected result returned from Bytes after Write(%q), got %q expected %q", tc.write, got, tc.expect)
+		}
+	}
+
+	r.Reset()
+	if r.Len() != 0 {
+		t.Errorf("Unexpected length after Reset, got %d expected 0", r.Len())
+	}
+}
+
+func TestRingBufferEmpty(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{0, -1} {
+		r := NewRingBuffer(size)
+		if n, err := r.Write([]byte("abc")); err != nil || n != 3 {
+			t.Errorf("Unexpected result from Write with size %d, got %d, %v", size, n, err)
+		}
+		if r.Len() != 0 {
+			t.Errorf("Unexpected length with size %d, got %d expected 0", size, r.Len())
+		}
+	}
+}
@@ -22,21 +22,66 @@ func Start(cmd *exec.Cmd) (*os.File, error) {
 // This will resize the pty to the specified size before starting the command if a size is provided.
 // The `attrs` parameter overrides the one set in c.SysProcAttr.
 //
-// This should generally not be needed. Used in some edge cases where it is needed to create a pty
-// without a controlling terminal.
+// This should generally not be needed. To create a pty without a controlling
+// terminal, use StartWithOptions with WithNoCtty instead.
 func StartWithAttrs(c *exec.Cmd, sz *Winsize, attrs *syscall.SysProcAttr) (*os.File, error) {
+	pty, _, err := startWithAttrs(c, &startOptions{size: sz}, attrs)
+	return pty, err
+}
+
+// StartWithOptions assigns a pseudo-terminal tty os.File to c.Stdin, c.Stdout,
+// and c.Stderr, calls c.Start, and returns the File of the tty's
+// corresponding pty.
+//
+// Starts the process in a new session and sets the controlling terminal,
+// then applies the given options. The other fields of c.SysProcAttr, such
+// as Credential, are kept.
+func StartWithOptions(c *exec.Cmd, opts ...StartOption) (*os.File, error) {
+	o := &startOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	pty, _, err := startWithOptions(c, o)
+	return pty, err
+}
+
+// startWithAttrs starts c with attrs, returning the end of its separate
+// stderr too, if any.
+func startWithAttrs(c *exec.Cmd, o *startOptions, attrs *syscall.SysProcAttr) (*os.File, *os.File, error) {
+	end := trace(o.tracer, OpOpen)
 	pty, tty, err := Open()
+	end(err)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer func() { _ = tty.Close() }() // Best effort.
+	defer func() { logErr("pty: close tty", tty.Close()) }() // Best effort.
 
-	if sz != nil {
-		if err := Setsize(pty, sz); err != nil {
-			_ = pty.Close() // Best effort.
-			return nil, err
+	if o.size != nil {
+		if err := Setsize(pty, o.size); err != nil {
+			logErr("pty: close pty", pty.Close()) // Best effort.
+			return nil, nil, err
 		}
 	}
+	if len(o.modes) > 0 {
+		if err := setTerminalModes(tty, o.modes); err != nil {
+			logErr("pty: close pty", pty.Close()) // Best effort.
+			return nil, nil, wrapErr(OpSetModes, tty.Name(), err)
+		}
+	}
+	if o.credential != nil {
+		if err := tty.Chown(int(o.credential.uid), int(o.credential.gid)); err != nil {
+			logErr("pty: close pty", pty.Close()) // Best effort.
+			return nil, nil, wrapErr(OpChown, tty.Name(), err)
+		}
+	}
+	stderr, peer, err := openStderr(c, o)
+	if err != nil {
+		logErr("pty: close pty", pty.Close()) // Best effort.
+		return nil, nil, err
+	}
+	if peer != nil {
+		defer func() { logErr("pty: close stderr tty", peer.Close()) }() // Best effort.
+	}
 	if c.Stdout == nil {
 		c.Stdout = tty
 	}
@@ -48,10 +93,19 @@ func StartWithAttrs(c *exec.Cmd, sz *Winsize, attrs *syscall.SysProcAttr) (*os.F
 	}
 
 	c.SysProcAttr = attrs
+	if len(o.env) > 0 {
+		c.Env = setEnv(c.Env, o.env)
+	}
 
-	if err := c.Start(); err != nil {
-		_ = pty.Close() // Best effort.
-		return nil, err
+	end = trace(o.tracer, OpStart)
+	err = startInCgroup(c, o, func() error { return startCmd(c, o) })
+	end(err)
+	if err != nil {
+		logErr("pty: close pty", pty.Close()) // Best effort.
+		if stderr != nil {
+			logErr("pty: close stderr", stderr.Close()) // Best effort.
+		}
+		return nil, nil, wrapErr(OpStart, c.Path, err)
 	}
-	return pty, err
+	return pty, stderr, nil
 }
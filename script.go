@@ -0,0 +1,178 @@
+package pty
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Script is a conversation with a command read from a text file, run like
+// the steps given to Expecter.Run, to script a session as expect(1) does.
+//
+// Each line holds a directive and its argument. Empty lines and lines
+// starting with # are ignored, as is the rest of a line from a # following
+// a space. Arguments are taken as is, or unquoted as Go strings if they
+// start with a double quote, to allow escapes and #:
+//
+//	timeout 10s       # Of the following expect directives of the run.
+//	expect login: $   # Waits for a regular expression.
+//	expect-string $   # Waits for a string.
+//	sendline bob      # Sends a line, followed by a carriage return.
+//	send "\x03"       # Sends as is.
+//	sleep 500ms
+//	resize 40 120     # Resizes to 40 rows and 120 columns.
+type Script struct {
+	steps []scriptStep
+}
+
+type scriptStep struct {
+	line int
+	text string
+	step Step
+}
+
+// ScriptError is returned by Script.Run for the failing directive.
+type ScriptError struct {
+	Line      int    // Of the directive.
+	Directive string // As written in the script.
+	Err       error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Directive, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error { return e.Err }
+
+// ParseScript reads a Script from r.
+func ParseScript(r io.Reader) (*Script, error) {
+	sc := &Script{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		step, err := parseDirective(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		sc.steps = append(sc.steps, scriptStep{line: n, text: text, step: step})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+func parseDirective(text string) (Step, error) {
+	directive, arg := text, ""
+	if i := strings.IndexAny(text, " \t"); i >= 0 {
+		directive, arg = text[:i], strings.TrimSpace(text[i+1:])
+	}
+	if strings.HasPrefix(arg, `"`) {
+		// Find the closing quote, to drop a comment following it.
+		end := 1
+		for end < len(arg) && arg[end] != '"' {
+			if arg[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end < len(arg) {
+			end++
+		} else {
+			end = len(arg)
+		}
+		s, err := strconv.Unquote(arg[:end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", arg)
+		}
+		arg = s
+	} else if i := strings.Index(arg, " #"); i >= 0 {
+		arg = strings.TrimSpace(arg[:i])
+	}
+
+	switch directive {
+	case "send":
+		return Send(arg), nil
+	case "sendline":
+		return SendLine(arg), nil
+	case "expect":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return Expect(re), nil
+	case "expect-string":
+		return ExpectString(arg), nil
+	case "sleep":
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return nil, err
+		}
+		return sleepStep(d), nil
+	case "timeout":
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return nil, err
+		}
+		return stepFunc(func(_ context.Context, e *Expecter, _ io.Writer) error {
+			e.SetTimeout(d)
+			return nil
+		}), nil
+	case "resize":
+		var rows, cols uint16
+		if _, err := fmt.Sscanf(arg, "%d %d", &rows, &cols); err != nil {
+			return nil, fmt.Errorf("invalid size %q", arg)
+		}
+		return resizeStep(&Winsize{Rows: rows, Cols: cols}), nil
+	}
+	return nil, fmt.Errorf("unknown directive %q", directive)
+}
+
+func sleepStep(d time.Duration) Step {
+	return stepFunc(func(ctx context.Context, _ *Expecter, _ io.Writer) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			return nil
+		}
+	})
+}
+
+func resizeStep(ws *Winsize) Step {
+	return stepFunc(func(_ context.Context, _ *Expecter, w io.Writer) error {
+		r, ok := w.(resizer)
+		if !ok {
+			return errors.New("resize not supported")
+		}
+		return r.Resize(ws)
+	})
+}
+
+// Run runs the script, sending input to w, typically the Session e reads
+// from. It stops at the first failing directive with a *ScriptError. The
+// timeout of e is restored once it returns.
+func (sc *Script) Run(ctx context.Context, e *Expecter, w io.Writer) error {
+	e.mu.Lock()
+	timeout := e.timeout
+	e.mu.Unlock()
+	defer e.SetTimeout(timeout)
+
+	for _, s := range sc.steps {
+		if err := s.step.run(ctx, e, w); err != nil {
+			return &ScriptError{Line: s.line, Directive: s.text, Err: err}
+		}
+	}
+	return nil
+}
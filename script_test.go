@@ -0,0 +1,57 @@
+package pty
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScript(t *testing.T) {
+	t.Parallel()
+
+	sc, err := ParseScript(strings.NewReader(`
+# Log in.
+timeout 5s
+expect-string login:     # The prompt.
+sendline bob
+expect password: $
+send "secret # not a comment\r"
+sleep 10ms
+resize 30 100
+expect-string "secret # not a comment"
+expect size 30 100
+expect-string "never"
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseScript: %s", err)
+	}
+
+	s, err := StartSession(exec.Command("sh", "-c", `printf "login: "; read u; printf "password: "; read p; sleep 0.5; echo "$p"; echo size $(stty size)`))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	defer func() {
+		_ = s.Close()
+		_ = s.Wait()
+	}()
+
+	e := NewExpecter(s)
+	e.SetTimeout(time.Minute)
+	err = sc.Run(context.Background(), e, s)
+	var serr *ScriptError
+	if !errors.As(err, &serr) || serr.Line != 12 || serr.Directive != `expect-string "never"` {
+		t.Fatalf("Unexpected error from Run, got %v", err)
+	}
+	if e.timeout != time.Minute {
+		t.Errorf("Unexpected timeout after Run, got %v expected %v", e.timeout, time.Minute)
+	}
+
+	for _, bad := range []string{"wait 1s", "sleep soon", "resize 10", `send "open`, "expect ("} {
+		if _, err := ParseScript(strings.NewReader("\n" + bad)); err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+			t.Errorf("Unexpected error from ParseScript of %q, got %v", bad, err)
+		}
+	}
+}
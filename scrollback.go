@@ -0,0 +1,101 @@
+package pty
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Scrollback is an io.Writer retaining the last lines written to it, within
+// a number of lines and of bytes, to repopulate the view of a client
+// reconnecting to a session. Lines are retained whole unless a single line
+// exceeds the byte limit, in which case only its end is retained. It is
+// safe for concurrent use.
+type Scrollback struct {
+	maxLines int
+	maxBytes int
+
+	mu    sync.Mutex
+	lines [][]byte // The last one is the current, unterminated, line.
+	size  int
+}
+
+// NewScrollback returns a Scrollback retaining up to maxLines lines and
+// maxBytes bytes. A limit of zero or less means no limit.
+func NewScrollback(maxLines, maxBytes int) *Scrollback {
+	return &Scrollback{maxLines: maxLines, maxBytes: maxBytes, lines: [][]byte{nil}}
+}
+
+// Write appends p. It never fails.
+func (s *Scrollback) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+		}
+		last := len(s.lines) - 1
+		s.lines[last] = append(s.lines[last], line...)
+		s.size += len(line)
+		if line[len(line)-1] == '\n' {
+			s.lines = append(s.lines, nil)
+		}
+		p = p[len(line):]
+	}
+	s.trim()
+	return n, nil
+}
+
+// trim drops the oldest lines beyond the limits.
+func (s *Scrollback) trim() {
+	// The current line only counts once it is not empty.
+	count := len(s.lines)
+	if len(s.lines[count-1]) == 0 {
+		count--
+	}
+	for s.maxLines > 0 && count > s.maxLines {
+		s.drop()
+		count--
+	}
+	// A line exceeding the limit alone is truncated rather than dropped.
+	for s.maxBytes > 0 && s.size > s.maxBytes && s.size > len(s.lines[0]) {
+		s.drop()
+	}
+	if s.maxBytes > 0 && s.size > s.maxBytes {
+		cur := s.lines[0]
+		s.lines[0] = append([]byte(nil), cur[len(cur)-s.maxBytes:]...)
+		s.size = s.maxBytes
+	}
+}
+
+func (s *Scrollback) drop() {
+	s.size -= len(s.lines[0])
+	s.lines[0] = nil
+	s.lines = s.lines[1:]
+}
+
+// Snapshot returns a copy of the retained output.
+func (s *Scrollback) Snapshot() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return bytes.Join(s.lines, nil)
+}
+
+// Reset discards the retained output.
+func (s *Scrollback) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines, s.size = [][]byte{nil}, 0
+}
+
+// Scrollback returns a Scrollback retaining the output read from the
+// session from now on, until stop is called, up to maxLines lines and
+// maxBytes bytes.
+func (s *Session) Scrollback(maxLines, maxBytes int) (sb *Scrollback, stop func()) {
+	sb = NewScrollback(maxLines, maxBytes)
+	s.addTap(sb)
+	var once sync.Once
+	return sb, func() { once.Do(func() { s.removeTap(sb) }) }
+}
@@ -0,0 +1,31 @@
+package pty
+
+import "testing"
+
+func TestScrollback(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		maxLines, maxBytes int
+		writes             []string
+		want               string
+	}{
+		{0, 0, []string{"a\nb", "c\n"}, "a\nbc\n"},
+		{2, 0, []string{"a\nb\n", "c\nd"}, "c\nd"},
+		{2, 0, []string{"a\nb\nc\n"}, "b\nc\n"},
+		{0, 5, []string{"aa\nbb\n", "cc\n"}, "cc\n"},
+		{0, 4, []string{"aa\n", "bbbbbb"}, "bbbb"},
+		{0, 4, []string{"aa\n", "bbbbbb\n"}, "bbb\n"},
+		{0, 4, []string{"aaaaaa\n", "b"}, "b"},
+	} {
+		sb := NewScrollback(tc.maxLines, tc.maxBytes)
+		for _, w := range tc.writes {
+			if _, err := sb.Write([]byte(w)); err != nil {
+				t.Fatalf("Unexpected error from Write: %s", err)
+			}
+		}
+		if got := string(sb.Snapshot()); got != tc.want {
+			t.Errorf("Unexpected snapshot of %q with %d lines and %d bytes, got %q expected %q", tc.writes, tc.maxLines, tc.maxBytes, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	prSetNoNewPrivs   = 38 // PR_SET_NO_NEW_PRIVS.
+	prSetSeccomp      = 22 // PR_SET_SECCOMP.
+	seccompModeFilter = 2  // SECCOMP_MODE_FILTER.
+)
+
+var errSeccompFilter = errors.New("invalid seccomp filter")
+
+// installSeccomp sets no_new_privs and installs filter on the calling
+// thread, which must be locked, and must never be unlocked: the filter
+// would apply to any goroutine scheduled on it, and to the Go runtime.
+func installSeccomp(filter []byte) error {
+	size := int(unsafe.Sizeof(syscall.SockFilter{}))
+	n := len(filter) / size
+	if n == 0 || n > 0xffff || len(filter)%size != 0 {
+		return errSeccompFilter
+	}
+	prog := make([]syscall.SockFilter, n)
+	//nolint:gosec // Expected unsafe pointer to copy the instructions as is.
+	copy((*[1 << 24]byte)(unsafe.Pointer(&prog[0]))[:len(filter):len(filter)], filter)
+
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0); errno != 0 {
+		return os.NewSyscallError("prctl", errno)
+	}
+	fprog := syscall.SockFprog{Len: uint16(n), Filter: &prog[0]}
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prSetSeccomp, seccompModeFilter, uintptr(unsafe.Pointer(&fprog)), 0, 0, 0); errno != 0 {
+		return os.NewSyscallError("prctl", errno)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"errors"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+func TestStartWithOptionsSeccomp(t *testing.T) {
+	t.Parallel()
+
+	// Fail uname with EPERM, allow everything else.
+	prog := []syscall.SockFilter{
+		{Code: syscall.BPF_LD | syscall.BPF_W | syscall.BPF_ABS, K: 0}, // seccomp_data.nr.
+		{Code: syscall.BPF_JMP | syscall.BPF_JEQ | syscall.BPF_K, Jt: 0, Jf: 1, K: syscall.SYS_UNAME},
+		{Code: syscall.BPF_RET | syscall.BPF_K, K: 0x00050000 | uint32(syscall.EPERM)}, // SECCOMP_RET_ERRNO.
+		{Code: syscall.BPF_RET | syscall.BPF_K, K: 0x7fff0000},                         // SECCOMP_RET_ALLOW.
+	}
+	filter := (*[1 << 10]byte)(unsafe.Pointer(&prog[0]))[:len(prog)*int(unsafe.Sizeof(prog[0]))]
+
+	c := exec.Command("sh", "-c", "uname || echo denied")
+	pty, err := StartWithOptions(c, WithSeccomp(filter))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartWithOptions: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+
+	out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+	_ = c.Wait()
+	if !strings.Contains(string(out), "denied") {
+		t.Errorf("Unexpected output, got %q expected uname to be denied", out)
+	}
+
+	if _, err := StartWithOptions(exec.Command("true"), WithSeccomp(filter[:3])); !errors.Is(err, errSeccompFilter) {
+		t.Errorf("Unexpected error from StartWithOptions, got %v expected %v", err, errSeccompFilter)
+	}
+}
@@ -0,0 +1,31 @@
+package pty
+
+import (
+	"os"
+	"os/exec"
+)
+
+// selfEnv carries the entrypoint given to StartSelf to the new process.
+const selfEnv = "PTY_ENTRYPOINT"
+
+// StartSelf starts the current executable again, with args, in a Session:
+// a new session leader whose controlling terminal is a new pty, the master
+// of which stays with the caller, as forkpty(3) does. This is for agents
+// daemonizing themselves and for test harnesses. The new process tells it
+// was started so, and for what, from SelfEntrypoint, which returns
+// entrypoint in it.
+func StartSelf(entrypoint string, args []string, opts ...StartOption) (*Session, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	c := exec.Command(exe, args...)
+	c.Env = setEnv(nil, []string{selfEnv + "=" + entrypoint})
+	return StartSession(c, opts...)
+}
+
+// SelfEntrypoint returns the entrypoint the current process was started
+// for by StartSelf, "" if it was not.
+func SelfEntrypoint() string {
+	return os.Getenv(selfEnv)
+}
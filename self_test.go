@@ -0,0 +1,39 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestStartSelfEntrypoint is the entrypoint started by TestStartSelf.
+func TestStartSelfEntrypoint(t *testing.T) {
+	if SelfEntrypoint() != "self-test" {
+		return
+	}
+	_, err := GetsizeFull(os.Stdin)
+	fmt.Printf("entrypoint %s, tty %v\n", SelfEntrypoint(), err == nil)
+	os.Exit(0)
+}
+
+func TestStartSelf(t *testing.T) {
+	t.Parallel()
+
+	s, err := StartSelf("self-test", []string{"-test.run=^TestStartSelfEntrypoint$"})
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSelf: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+	out, _ := ioutil.ReadAll(EOFReader(s))
+	if err := s.Wait(); err != nil {
+		t.Fatalf("Unexpected error from Wait: %s", err)
+	}
+	if !strings.Contains(string(out), "entrypoint self-test, tty true") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
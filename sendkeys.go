@@ -0,0 +1,69 @@
+package pty
+
+import "sync"
+
+// modeTracker follows the DECCKM sequences, CSI ? 1 h and CSI ? 1 l, in the
+// output of a command, to know whether it expects the application cursor
+// keys, without emulating a whole terminal.
+type modeTracker struct {
+	mu        sync.Mutex
+	appCursor bool
+	state     int // 0: ground, 1: after ESC, 2: after ESC [, 3: in ESC [ ?.
+	params    []int
+	param     int
+}
+
+// Write implements io.Writer, fed the raw output of the session.
+func (m *modeTracker) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range p {
+		switch {
+		case c == 0x1b:
+			m.state = 1
+		case m.state == 1 && c == '[':
+			m.state = 2
+		case m.state == 2 && c == '?':
+			m.state, m.params, m.param = 3, m.params[:0], 0
+		case m.state == 3 && c >= '0' && c <= '9':
+			if m.param < 1<<16 {
+				m.param = m.param*10 + int(c-'0')
+			}
+		case m.state == 3 && c == ';':
+			if len(m.params) < 16 {
+				m.params = append(m.params, m.param)
+			}
+			m.param = 0
+		case m.state == 3 && (c == 'h' || c == 'l'):
+			for _, p := range append(m.params, m.param) {
+				if p == 1 {
+					m.appCursor = c == 'h'
+				}
+			}
+			m.state = 0
+		default:
+			m.state = 0
+		}
+	}
+	return len(p), nil
+}
+
+// AppCursor tells whether the application cursor keys mode is set.
+func (m *modeTracker) AppCursor() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.appCursor
+}
+
+// SendKeys sends keys to the command, named as EncodeKeys expects, for
+// example SendKeys("ctrl+c"), SendKeys("up", "enter") or SendKeys("f5").
+// Cursor keys are encoded for the mode the command set, as seen in the
+// output read from the session so far.
+func (s *Session) SendKeys(keys ...string) error {
+	b, err := EncodeKeys(s.modes.AppCursor(), keys...)
+	if err != nil {
+		return err
+	}
+	_, err = s.Write(b)
+	return err
+}
@@ -0,0 +1,82 @@
+package pty
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// serialSync is how often BridgeSerial copies the line settings of the tty
+// to the device.
+const serialSync = 200 * time.Millisecond
+
+// BridgeSerial connects the pty of a pair to dev, a serial device or any
+// other io.ReadWriteCloser, so that the tty stands in for dev: what dev
+// sends is read from the tty and what is written to the tty is sent to dev.
+// Console servers can so expose a physical console to the programs and
+// sessions they already run on ttys. It returns when ctx is done, with
+// ctx.Err(), or when either copy ends, once it closed dev: this interrupts
+// the reads of dev, even when it does not support deadlines.
+//
+// When dev is an *os.File on a platform with termios, the speed, the
+// character size, the parity, the stop bits and the flow control set on the
+// tty, e.g. by stty, are applied to dev too. The other settings of dev are
+// left alone: it is meant to be raw, as the tty usually is too.
+func BridgeSerial(parent context.Context, pty, tty *os.File, dev io.ReadWriteCloser) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	errc := make(chan error, 2)
+	copyTo := func(dst io.Writer, src io.Reader) {
+		_, err := CopyContext(ctx, dst, src)
+		cancel()
+		errc <- err
+	}
+	go copyTo(dev, pty)
+	go copyTo(pty, dev)
+
+	synced := make(chan struct{})
+	if f, ok := dev.(*os.File); ok {
+		go func() {
+			defer close(synced)
+			t := time.NewTicker(serialSync)
+			defer t.Stop()
+			for {
+				if err := syncLine(f, tty); err != nil {
+					if !errors.Is(err, ErrUnsupported) {
+						logDebug("pty: stop syncing serial line", "err", err)
+					}
+					return
+				}
+				select {
+				case <-t.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	} else {
+		close(synced)
+	}
+
+	// The first copy to end ends the other one.
+	var err error
+	pending := 2
+	select {
+	case err = <-errc:
+		pending--
+	case <-ctx.Done():
+	}
+	cancel()
+	<-synced
+	_ = dev.Close() // Best effort.
+	for ; pending > 0; pending-- {
+		<-errc
+	}
+	if perr := parent.Err(); perr != nil {
+		return perr
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package pty
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestBridgeSerial(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close(); _ = tty.Close() }()
+	// Another pair stands in for the serial device, its tty for the other end
+	// of the line.
+	dev, line, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = dev.Close(); _ = line.Close() }()
+
+	// Do not echo the input back to the device.
+	if err := setTerminalModes(tty, map[uint8]uint32{53: 0, 40: 1}); err != nil {
+		t.Fatalf("Unexpected error from setTerminalModes: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- BridgeSerial(ctx, pty, tty, dev) }()
+
+	if _, err := line.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	rctx, rcancel := context.WithTimeout(ctx, 5*time.Second)
+	defer rcancel()
+	buf := make([]byte, 64)
+	n, err := ReadContext(rctx, tty, buf)
+	if err != nil {
+		t.Fatalf("Unexpected error from Read: %s", err)
+	}
+	if got := string(buf[:n]); !strings.Contains(got, "hello") {
+		t.Errorf("Unexpected data from the device: %q", got)
+	}
+
+	// The flow control set on the tty is applied to the device.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var tio syscall.Termios
+		//nolint:gosec // Expected unsafe pointer for Syscall call.
+		if err := ioctl(dev, ioctlGetTermios, uintptr(unsafe.Pointer(&tio))); err != nil {
+			t.Fatalf("Unexpected error from ioctl: %s", err)
+		}
+		if tio.Iflag&syscall.IXOFF != 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("The flow control was not applied to the device")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("Unexpected error from BridgeSerial: %v", err)
+	}
+	if _, err := dev.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Unexpected error from Write to the device after BridgeSerial, got %v expected %v", err, os.ErrClosed)
+	}
+}
+
+// pipeDevice is a device without deadlines, whose reads only end once it
+// is closed.
+type pipeDevice struct {
+	*io.PipeReader
+	io.Writer
+}
+
+func TestBridgeSerialNoDeadline(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close(); _ = tty.Close() }()
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- BridgeSerial(ctx, pty, tty, pipeDevice{pr, ioutil.Discard}) }()
+	select {
+	case err := <-errc:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Unexpected error from BridgeSerial, got %v expected %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for BridgeSerial to return")
+	}
+}
@@ -0,0 +1,463 @@
+package pty
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds the I/O counters of a Session.
+type Stats struct {
+	BytesRead    uint64 // Bytes of output read from the command.
+	BytesWritten uint64 // Bytes of input written to the command.
+	Reads        uint64 // Number of reads that returned output.
+	Writes       uint64 // Number of writes that sent input.
+}
+
+// Session is a command running attached to a pty. Reading from a Session
+// reads the output of the command, writing to it sends input to the command.
+type Session struct {
+	stats Stats // Updated atomically, first for 64 bits alignment.
+
+	cmd    *exec.Cmd
+	pty    *os.File
+	name   string
+	stderr *os.File // The separate stderr, if any.
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	paused   bool
+	pauseGen uint64    // Incremented by PauseOutput to tell interrupted reads apart.
+	deadline time.Time // Set with SetReadDeadline, restored by ResumeOutput.
+	closed   bool
+	taps     []io.Writer // Receive a copy of the output read.
+	rawTaps  []io.Writer // Same, before the transformers.
+	inTaps   []io.Writer // Receive a copy of the input written.
+	sizeTaps []resizeTap // Told about the resizes.
+	xforms   []Transformer
+	pending  []byte       // Output read past a WaitFor match, returned first.
+	modes    *modeTracker // Fed the raw output, for the modes it sets.
+	watchers []*idleWatcher
+
+	tracer    Tracer
+	hooks     []func(*Session, LifecycleEvent)
+	eioAsEOF  bool
+	doneError bool
+	inLimit   *Throttle // Of the input, nil for no limit.
+	outLimit  *Throttle // Of the output, nil for no limit.
+
+	waitOnce  sync.Once
+	readOnce  sync.Once // Emits LifecycleFirstOutput.
+	watchOnce sync.Once
+	waitErr   error
+	state     *os.ProcessState // Once waited for.
+	done      chan struct{}    // Closed once waited for.
+}
+
+// StartSession starts c attached to a new pty, like StartWithOptions, and
+// returns the corresponding Session.
+func StartSession(c *exec.Cmd, opts ...StartOption) (*Session, error) {
+	o := &startOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	pty, stderr, err := startWithOptions(c, o)
+	if err != nil {
+		return nil, err
+	}
+	s := newSession(c, pty, stderr, o)
+	s.emit(LifecycleEvent{Kind: LifecycleStart})
+	return s, nil
+}
+
+func newSession(c *exec.Cmd, pty, stderr *os.File, o *startOptions) *Session {
+	s := &Session{cmd: c, pty: pty, name: o.name, stderr: stderr, tracer: o.tracer, hooks: o.hooks, eioAsEOF: o.eioAsEOF, doneError: o.doneError, inLimit: o.inLimit, outLimit: o.outLimit}
+	s.cond = sync.NewCond(&s.mu)
+	s.done = make(chan struct{})
+	s.modes = &modeTracker{}
+	return s
+}
+
+// Cmd returns the command running in the session. Wait for it with
+// Session.Wait rather than its own Wait method, which must only be called
+// once.
+func (s *Session) Cmd() *exec.Cmd { return s.cmd }
+
+// Pty returns the pty of the session. Reading from it directly bypasses
+// the session.
+func (s *Session) Pty() *os.File { return s.pty }
+
+// Name returns the label given with WithName, or the name of the pty.
+func (s *Session) Name() string {
+	if s.name != "" {
+		return s.name
+	}
+	return s.pty.Name()
+}
+
+// Stderr returns the pty or the pipe the standard error of the command is
+// connected to with WithStderrPty or WithStderrPipe, nil otherwise.
+// Closing the session closes it.
+func (s *Session) Stderr() *os.File { return s.stderr }
+
+// Read reads the output of the command. It blocks while the output is
+// paused.
+func (s *Session) Read(p []byte) (int, error) { return s.read(p, nil) }
+
+// read reads the output of the command, like Read, returning
+// errReadStopped once stop is closed and the cond broadcast, even while
+// the output is paused.
+func (s *Session) read(p []byte, stop <-chan struct{}) (int, error) {
+	for {
+		s.mu.Lock()
+		for s.paused && !s.closed && !isDone(stop) {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return 0, os.ErrClosed
+		}
+		if isDone(stop) {
+			s.mu.Unlock()
+			return 0, errReadStopped
+		}
+		if len(s.pending) > 0 {
+			n := copy(p, s.pending)
+			s.pending = s.pending[n:]
+			s.mu.Unlock()
+			return n, nil
+		}
+		gen := s.pauseGen
+		s.mu.Unlock()
+
+		n, err := s.pty.Read(p[:s.outLimit.limit(len(p))])
+		if n > 0 {
+			s.outLimit.wait(n)
+			atomic.AddUint64(&s.stats.BytesRead, uint64(n))
+			atomic.AddUint64(&s.stats.Reads, 1)
+			s.touch()
+			s.readOnce.Do(func() { s.emit(LifecycleEvent{Kind: LifecycleFirstOutput}) })
+			_, _ = s.modes.Write(p[:n]) // Before the transformers, which may hide them.
+			s.tap(p[:n])
+		}
+		if n == 0 && err != nil && os.IsTimeout(err) {
+			s.mu.Lock()
+			interrupted := s.pauseGen != gen
+			s.mu.Unlock()
+			if interrupted {
+				continue
+			}
+		}
+		if err != nil && s.doneError {
+			err = s.toDoneError(err)
+		} else if err != nil && s.eioAsEOF && isEIO(err) {
+			err = io.EOF
+		}
+		return n, err
+	}
+}
+
+// tap copies the output p to the taps of the session.
+func (s *Session) tap(p []byte) {
+	s.mu.Lock()
+	raw, taps, xforms := s.rawTaps, s.taps, s.xforms
+	s.mu.Unlock()
+	writeTaps(raw, p)
+	writeTaps(taps, transform(xforms, EventOutput, p))
+}
+
+// flushTaps copies the input and output held back by the transformers to
+// the taps of the session.
+func (s *Session) flushTaps() {
+	s.mu.Lock()
+	taps, inTaps, xforms := s.taps, s.inTaps, s.xforms
+	s.mu.Unlock()
+	writeTaps(inTaps, flushTransform(xforms, EventInput))
+	writeTaps(taps, flushTransform(xforms, EventOutput))
+}
+
+// tapInput copies the input p to the input taps of the session.
+func (s *Session) tapInput(p []byte) {
+	s.mu.Lock()
+	taps, xforms := s.inTaps, s.xforms
+	s.mu.Unlock()
+	writeTaps(taps, transform(xforms, EventInput, p))
+}
+
+func writeTaps(taps []io.Writer, p []byte) {
+	if len(p) == 0 {
+		return // Held back by a transformer.
+	}
+	for _, w := range taps {
+		_, err := w.Write(p)
+		logErr("pty: write session tap", err) // Best effort.
+	}
+}
+
+func (s *Session) addTap(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taps = append(s.taps[:len(s.taps):len(s.taps)], w)
+}
+
+// addRawTap adds a tap of the output as read, before the transformers,
+// for those looking for the control sequences the transformers may hide.
+func (s *Session) addRawTap(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rawTaps = append(s.rawTaps[:len(s.rawTaps):len(s.rawTaps)], w)
+}
+
+// removeTap removes the tap w, raw or not, which must be comparable.
+func (s *Session) removeTap(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taps = withoutWriter(s.taps, w)
+	s.rawTaps = withoutWriter(s.rawTaps, w)
+}
+
+// withoutWriter returns a copy of ws without w.
+func withoutWriter(ws []io.Writer, w io.Writer) []io.Writer {
+	out := make([]io.Writer, 0, len(ws))
+	for _, t := range ws {
+		if t != w {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Tee copies the output read from the session from now on to ws, for
+// example to an audit log or to observers of the session, until stop is
+// called. A failing writer doesn't keep the others from receiving the
+// output, its errors are logged.
+func (s *Session) Tee(ws ...io.Writer) (stop func()) {
+	t := &tee{ws: ws}
+	s.addTap(t)
+	var once sync.Once
+	return func() { once.Do(func() { s.removeTap(t) }) }
+}
+
+type tee struct{ ws []io.Writer }
+
+func (t *tee) Write(p []byte) (int, error) {
+	writeTaps(t.ws, p)
+	return len(p), nil
+}
+
+func (s *Session) addInputTap(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inTaps = append(s.inTaps[:len(s.inTaps):len(s.inTaps)], w)
+}
+
+// removeInputTap removes the input tap w, which must be comparable.
+func (s *Session) removeInputTap(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inTaps = withoutWriter(s.inTaps, w)
+}
+
+// CaptureOutput returns a RingBuffer retaining the last size bytes of
+// output read from the session from now on, until stop is called, for
+// example to log the tail of the output when the command crashes. A size of
+// zero or less retains nothing.
+func (s *Session) CaptureOutput(size int) (r *RingBuffer, stop func()) {
+	r = NewRingBuffer(size)
+	s.addTap(r)
+	var once sync.Once
+	return r, func() { once.Do(func() { s.removeTap(r) }) }
+}
+
+// Write sends p as input to the command.
+func (s *Session) Write(p []byte) (int, error) {
+	if s.inLimit == nil {
+		return s.write(p)
+	}
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:s.inLimit.limit(len(p))]
+		s.inLimit.wait(len(chunk))
+		n, err := s.write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (s *Session) write(p []byte) (int, error) {
+	n, err := s.pty.Write(p)
+	if n > 0 {
+		atomic.AddUint64(&s.stats.BytesWritten, uint64(n))
+		atomic.AddUint64(&s.stats.Writes, 1)
+		s.touch()
+		s.tapInput(p[:n])
+	}
+	return n, err
+}
+
+// Stats returns a snapshot of the I/O counters of the session, for example
+// to export per session throughput metrics.
+func (s *Session) Stats() Stats {
+	return Stats{
+		BytesRead:    atomic.LoadUint64(&s.stats.BytesRead),
+		BytesWritten: atomic.LoadUint64(&s.stats.BytesWritten),
+		Reads:        atomic.LoadUint64(&s.stats.Reads),
+		Writes:       atomic.LoadUint64(&s.stats.Writes),
+	}
+}
+
+// Resize resizes the pty of the session.
+func (s *Session) Resize(ws *Winsize) error {
+	end := trace(s.tracer, OpResize)
+	err := Setsize(s.pty, ws)
+	end(err)
+	if err == nil {
+		s.mu.Lock()
+		taps := s.sizeTaps
+		s.mu.Unlock()
+		for _, t := range taps {
+			t.resized(ws)
+		}
+		s.emit(LifecycleEvent{Kind: LifecycleResize, Size: ws})
+	}
+	return err
+}
+
+// resizeTap is told about the resizes of a session.
+type resizeTap interface {
+	resized(ws *Winsize)
+}
+
+// addResizeTap adds a tap of the resizes of the session.
+func (s *Session) addResizeTap(t resizeTap) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sizeTaps = append(s.sizeTaps[:len(s.sizeTaps):len(s.sizeTaps)], t)
+}
+
+// removeResizeTap removes the resize tap t, which must be comparable.
+func (s *Session) removeResizeTap(t resizeTap) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	taps := make([]resizeTap, 0, len(s.sizeTaps))
+	for _, u := range s.sizeTaps {
+		if u != t {
+			taps = append(taps, u)
+		}
+	}
+	s.sizeTaps = taps
+}
+
+// Wait waits for the command to exit. See exec.Cmd.Wait. Unlike it, it
+// can be called more than once, and from any number of goroutines, all
+// getting the same result.
+func (s *Session) Wait() error {
+	s.waitOnce.Do(func() {
+		end := trace(s.tracer, OpWait)
+		err := s.cmd.Wait()
+		end(err)
+		s.mu.Lock()
+		s.waitErr, s.state = err, s.cmd.ProcessState
+		s.mu.Unlock()
+		s.emit(LifecycleEvent{Kind: LifecycleExit, State: s.cmd.ProcessState, Err: err})
+		close(s.done)
+	})
+	return s.Err()
+}
+
+// Done returns a channel closed once the command exited, for selecting on
+// it along with other events. The command is waited for from a new
+// goroutine: Wait then returns immediately with the same result as Err.
+func (s *Session) Done() <-chan struct{} {
+	s.watchOnce.Do(func() { go func() { _ = s.Wait() }() })
+	return s.done
+}
+
+// Err returns the result of waiting for the command, nil until Done is
+// closed.
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waitErr
+}
+
+// Alive reports whether the command still runs and the pty is still open,
+// cheaply enough for orchestrators to poll their sessions with. A command
+// which exited without being waited for is only detected on Linux, as a
+// zombie, until Wait is called.
+func (s *Session) Alive() bool {
+	s.mu.Lock()
+	closed, state := s.closed, s.state
+	s.mu.Unlock()
+	if closed || state != nil {
+		return false
+	}
+	if err := controlFd(s.pty, func(int) {}); err != nil {
+		return false
+	}
+	return processAlive(s.cmd.Process)
+}
+
+// Close closes the pty of the session. The command gets a SIGHUP on Unix.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	s.flushTaps()
+	end := trace(s.tracer, OpClose)
+	err := s.pty.Close()
+	end(err)
+	if s.stderr != nil {
+		logErr("pty: close stderr", s.stderr.Close()) // Best effort.
+	}
+	return err
+}
+
+// PauseOutput stops reading the output of the command until ResumeOutput
+// is called, letting the kernel apply backpressure to the command once
+// the pty buffer is full, instead of buffering indefinitely. Pending
+// Reads are interrupted where the pty supports deadlines, and wait for
+// ResumeOutput.
+func (s *Session) PauseOutput() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		return
+	}
+	s.paused = true
+	s.pauseGen++
+	logErr("pty: interrupt pending reads", s.pty.SetReadDeadline(time.Now())) // Best effort.
+}
+
+// ResumeOutput resumes reading the output of the command.
+func (s *Session) ResumeOutput() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		return
+	}
+	s.paused = false
+	logErr("pty: restore read deadline", s.pty.SetReadDeadline(s.deadline)) // Best effort.
+	s.cond.Broadcast()
+}
+
+// SetReadDeadline sets the deadline of pending and future reads. While the
+// output is paused, it only applies once resumed.
+func (s *Session) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadline = t
+	if s.paused {
+		return nil
+	}
+	return s.pty.SetReadDeadline(t)
+}
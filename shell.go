@@ -0,0 +1,96 @@
+package pty
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// defaultShell is the login shell of the users whose entry names none.
+const defaultShell = "/bin/sh"
+
+// loginPath is the PATH of login shells, until their profile sets it.
+const loginPath = "/usr/local/bin:/usr/bin:/bin"
+
+// LookupShell returns a command running the login shell of the user with
+// the given name, ready to start, as SSH and web terminal hosts need. The
+// shell is resolved through NSS with getent(1) where available, from
+// Directory Services on macOS, and from /etc/passwd otherwise. It fails
+// with a user.UnknownUserError if the user is not found.
+//
+// Not supported on Windows.
+func LookupShell(username string) (*exec.Cmd, error) {
+	_, shell, err := lookupUser(username)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(shell), nil
+}
+
+// StartLoginShell starts the login shell of the user with the given name in
+// a new Session, as login(1) does: its argv[0] is its name with a leading
+// dash, for it to read the profile of the user, it runs in the home
+// directory of the user, and its environment is made of HOME, SHELL, USER,
+// LOGNAME, a default PATH and the TERM of this process. Unless the user is
+// the one running this process, the shell runs as the user, as with
+// WithCredential, which requires privileges. The user and their shell come
+// from the same record, looked up as by LookupShell. The options are
+// applied after those, and may override them.
+//
+// Not supported on Windows.
+func StartLoginShell(username string, opts ...StartOption) (*Session, error) {
+	u, shell, err := lookupUser(username)
+	if err != nil {
+		return nil, err
+	}
+	c := exec.Command(shell)
+
+	c.Args[0] = "-" + filepath.Base(c.Path)
+	c.Dir = u.HomeDir
+	c.Env = []string{
+		"HOME=" + u.HomeDir,
+		"SHELL=" + c.Path,
+		"USER=" + u.Username,
+		"LOGNAME=" + u.Username,
+		"PATH=" + loginPath,
+	}
+	if term, ok := os.LookupEnv("TERM"); ok {
+		c.Env = append(c.Env, "TERM="+term)
+	}
+
+	if u.Uid != strconv.Itoa(os.Getuid()) {
+		cred, err := userCredential(u)
+		if err != nil {
+			return nil, err
+		}
+		opts = append([]StartOption{cred}, opts...)
+	}
+	return StartSession(c, opts...)
+}
+
+// userCredential returns WithCredential for u and its groups.
+func userCredential(u *user.User) (StartOption, error) {
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := u.GroupIds()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		g, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, uint32(g))
+	}
+	return WithCredential(uint32(uid), uint32(gid), groups), nil
+}
@@ -0,0 +1,88 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLookupShell(t *testing.T) {
+	t.Parallel()
+
+	u, err := user.Current()
+	if err != nil {
+		t.Skipf("Current user unknown: %s", err)
+	}
+	c, err := LookupShell(u.Username)
+	if err != nil {
+		t.Fatalf("Unexpected error from LookupShell: %s", err)
+	}
+	if c.Path == "" || len(c.Args) != 1 {
+		t.Errorf("Unexpected command %q %q", c.Path, c.Args)
+	}
+
+	var unknown user.UnknownUserError
+	for _, name := range []string{"no-such-user-pty", "-s", "../root"} {
+		if _, err := LookupShell(name); !errors.As(err, &unknown) {
+			t.Errorf("Unexpected error from LookupShell of %q, got %v expected an unknown user", name, err)
+		}
+	}
+}
+
+func TestPasswdUser(t *testing.T) {
+	t.Parallel()
+
+	passwd := []byte("root:x:0:0:root:/root:/bin/bash\nnobody:x:65534:65534::/nonexistent:\n")
+	for name, expect := range map[string]string{"root": "/bin/bash", "nobody": defaultShell, "bob": ""} {
+		if _, shell, _ := passwdUser(passwd, name); shell != expect {
+			t.Errorf("Unexpected shell of %s, got %q expected %q", name, shell, expect)
+		}
+	}
+	if u, _, _ := passwdUser(passwd, "nobody"); u == nil || u.Uid != "65534" || u.HomeDir != "/nonexistent" {
+		t.Errorf("Unexpected user nobody: %+v", u)
+	}
+}
+
+func TestDsclUser(t *testing.T) {
+	t.Parallel()
+
+	out := []byte("NFSHomeDirectory:\n /Users/bob smith\nPrimaryGroupID: 20\nUniqueID: 501\nUserShell: /bin/zsh\n")
+	u, shell, ok := dsclUser(out, "bob")
+	if !ok || u.Uid != "501" || u.Gid != "20" || u.HomeDir != "/Users/bob smith" || shell != "/bin/zsh" {
+		t.Errorf("Unexpected user %+v, shell %q, found %v", u, shell, ok)
+	}
+	if _, _, ok := dsclUser([]byte("UserShell: /bin/zsh\n"), "bob"); ok {
+		t.Error("Unexpected user found in an incomplete record")
+	}
+}
+
+func TestStartLoginShell(t *testing.T) {
+	t.Parallel()
+
+	u, err := user.Current()
+	if err != nil {
+		t.Skipf("Current user unknown: %s", err)
+	}
+	s, err := StartLoginShell(u.Username)
+	if err != nil {
+		t.Fatalf("Unexpected error from StartLoginShell: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	if _, err := s.Write([]byte("echo \"$0:$(pwd):$HOME:$LOGNAME\"; exit\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	out, _ := ioutil.ReadAll(EOFReader(s))
+	_ = s.Wait()
+	expect := fmt.Sprintf("-%s:%s:%s:%s", filepath.Base(s.Cmd().Path), u.HomeDir, u.HomeDir, u.Username)
+	if !strings.Contains(string(out), expect) {
+		t.Errorf("Unexpected output, got %q expected %q", out, expect)
+	}
+}
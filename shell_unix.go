@@ -0,0 +1,87 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strings"
+)
+
+// lookupUser returns the user with the given name and their shell, both
+// from the same record of the user database.
+func lookupUser(name string) (*user.User, string, error) {
+	// Names are never empty, nor hold separators, nor start with a dash,
+	// and must not be taken as options or paths by the commands below.
+	if name == "" || name[0] == '-' || strings.ContainsAny(name, ":/\n\x00") {
+		return nil, "", user.UnknownUserError(name)
+	}
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("dscl", ".", "-read", "/Users/"+name, "UniqueID", "PrimaryGroupID", "NFSHomeDirectory", "UserShell").Output()
+		if err == nil {
+			if u, shell, ok := dsclUser(out, name); ok {
+				return u, shell, nil
+			}
+		}
+	}
+	if out, err := exec.Command("getent", "passwd", "--", name).Output(); err == nil {
+		if u, shell, ok := passwdUser(out, name); ok {
+			return u, shell, nil
+		}
+	}
+	passwd, err := ioutil.ReadFile("/etc/passwd")
+	if err != nil {
+		return nil, "", err
+	}
+	if u, shell, ok := passwdUser(passwd, name); ok {
+		return u, shell, nil
+	}
+	return nil, "", user.UnknownUserError(name)
+}
+
+// passwdUser returns the user name in passwd, in the format of
+// /etc/passwd, their shell, and whether they are found.
+func passwdUser(passwd []byte, name string) (*user.User, string, bool) {
+	for _, line := range bytes.Split(passwd, []byte("\n")) {
+		// name:password:uid:gid:gecos:home:shell
+		fields := strings.Split(string(line), ":")
+		if len(fields) != 7 || fields[0] != name {
+			continue
+		}
+		u := &user.User{Username: name, Uid: fields[2], Gid: fields[3], Name: strings.Split(fields[4], ",")[0], HomeDir: fields[5]}
+		if fields[6] == "" {
+			return u, defaultShell, true
+		}
+		return u, fields[6], true
+	}
+	return nil, "", false
+}
+
+// dsclUser returns the user name read by dscl(1) in out, their shell, and
+// whether the record is complete.
+func dsclUser(out []byte, name string) (*user.User, string, bool) {
+	attrs := map[string]string{}
+	var key string
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case strings.HasPrefix(line, " "): // The value of the last key, too long for its line.
+			attrs[key] = strings.TrimSpace(line)
+		case strings.Contains(line, ":"):
+			i := strings.Index(line, ":")
+			key = line[:i]
+			attrs[key] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	u := &user.User{Username: name, Uid: attrs["UniqueID"], Gid: attrs["PrimaryGroupID"], HomeDir: attrs["NFSHomeDirectory"]}
+	if u.Uid == "" || u.Gid == "" {
+		return nil, "", false
+	}
+	if shell := attrs["UserShell"]; shell != "" {
+		return u, shell, true
+	}
+	return u, defaultShell, true
+}
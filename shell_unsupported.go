@@ -0,0 +1,10 @@
+//go:build windows || js || plan9 || wasip1
+// +build windows js plan9 wasip1
+
+package pty
+
+import "os/user"
+
+func lookupUser(string) (*user.User, string, error) {
+	return nil, "", ErrUnsupported
+}
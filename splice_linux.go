@@ -0,0 +1,88 @@
+//go:build linux && go1.12
+// +build linux,go1.12
+
+package pty
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// from <linux/fcntl.h>
+const (
+	_SPLICE_F_MOVE     = 0x1
+	_SPLICE_F_NONBLOCK = 0x2
+)
+
+// maxSpliceSize is the default capacity of a pipe.
+const maxSpliceSize = 1 << 16
+
+// splice moves data from src to dst through an intermediate pipe.
+// handled is false if splice can't be used, in which case nothing has
+// been consumed from src.
+func splice(dst io.Writer, src *os.File) (written int64, handled bool, err error) {
+	sc, ok := dst.(syscall.Conn)
+	if !ok {
+		return 0, false, nil
+	}
+	dstRC, err := sc.SyscallConn()
+	if err != nil {
+		return 0, false, nil
+	}
+	srcRC, err := src.SyscallConn()
+	if err != nil {
+		return 0, false, nil
+	}
+
+	var p [2]int
+	if err := syscall.Pipe2(p[:], syscall.O_CLOEXEC|syscall.O_NONBLOCK); err != nil {
+		return 0, false, nil
+	}
+	defer func() { _ = syscall.Close(p[0]); _ = syscall.Close(p[1]) }() // Best effort.
+
+	for {
+		var (
+			n    int64
+			serr error
+		)
+		if err := srcRC.Read(func(fd uintptr) bool {
+			serr = ignoringEINTR(func() error {
+				r, err := syscall.Splice(int(fd), nil, p[1], nil, maxSpliceSize, _SPLICE_F_MOVE|_SPLICE_F_NONBLOCK)
+				n = int64(r) // int on some 32 bits platforms.
+				return err
+			})
+			return serr != syscall.EAGAIN
+		}); err != nil {
+			return written, true, err
+		}
+		if serr != nil {
+			if written == 0 && (serr == syscall.EINVAL || serr == syscall.ENOSYS) {
+				return 0, false, nil // Kernel can't splice from this tty.
+			}
+			return written, true, os.NewSyscallError("splice", serr)
+		}
+		if n == 0 {
+			return written, true, nil // EOF.
+		}
+
+		for n > 0 {
+			var m int64
+			if err := dstRC.Write(func(fd uintptr) bool {
+				serr = ignoringEINTR(func() error {
+					r, err := syscall.Splice(p[0], nil, int(fd), nil, int(n), _SPLICE_F_MOVE|_SPLICE_F_NONBLOCK)
+					m = int64(r)
+					return err
+				})
+				return serr != syscall.EAGAIN
+			}); err != nil {
+				return written, true, err
+			}
+			if serr != nil {
+				return written, true, os.NewSyscallError("splice", serr)
+			}
+			n -= m
+			written += m
+		}
+	}
+}
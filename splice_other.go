@@ -0,0 +1,13 @@
+//go:build !linux || !go1.12
+// +build !linux !go1.12
+
+package pty
+
+import (
+	"io"
+	"os"
+)
+
+func splice(io.Writer, *os.File) (int64, bool, error) {
+	return 0, false, nil
+}
@@ -0,0 +1,91 @@
+package pty
+
+import (
+	"encoding/binary"
+	"errors"
+	"os/exec"
+)
+
+var errShortPayload = errors.New("short payload")
+
+// PtyRequest is the payload of an SSH "pty-req" channel request, see RFC
+// 4254 section 6.2, as received by servers built on golang.org/x/crypto/ssh
+// in ssh.Request.Payload.
+type PtyRequest struct {
+	Term  string           // The TERM environment variable.
+	Size  Winsize          // The window size.
+	Modes map[uint8]uint32 // The encoded terminal modes.
+}
+
+// ParsePtyRequest parses the payload of a "pty-req" request.
+func ParsePtyRequest(payload []byte) (*PtyRequest, error) {
+	term, payload, ok := sshString(payload)
+	if !ok || len(payload) < 16 {
+		return nil, errShortPayload
+	}
+	r := &PtyRequest{Term: string(term), Size: sshWinsize(payload), Modes: map[uint8]uint32{}}
+	modes, _, ok := sshString(payload[16:])
+	if !ok {
+		return nil, errShortPayload
+	}
+	for len(modes) > 0 {
+		op := modes[0]
+		if op == 0 || op >= 160 { // TTY_OP_END, or opcodes with undefined arguments.
+			break
+		}
+		if len(modes) < 5 {
+			return nil, errShortPayload
+		}
+		r.Modes[op] = binary.BigEndian.Uint32(modes[1:5])
+		modes = modes[5:]
+	}
+	return r, nil
+}
+
+// ParseWindowChange parses the payload of a "window-change" request, see
+// RFC 4254 section 6.7, to resize the session with.
+func ParseWindowChange(payload []byte) (*Winsize, error) {
+	if len(payload) < 16 {
+		return nil, errShortPayload
+	}
+	ws := sshWinsize(payload)
+	return &ws, nil
+}
+
+// Start starts c in a Session whose tty is set up as requested: TERM is
+// set to the one of r with WithTerm, DefaultTerm if it is empty, and the
+// tty gets the window size and the terminal modes of r. The "window-change"
+// requests received later are applied with ParseWindowChange and
+// Session.Resize, as ServeRequests of the github.com/creack/pty/xssh module
+// does.
+func (r *PtyRequest) Start(c *exec.Cmd, opts ...StartOption) (*Session, error) {
+	ws := r.Size
+	opts = append([]StartOption{WithTerm(r.Term), WithSize(&ws), WithTerminalModes(r.Modes)}, opts...)
+	return StartSession(c, opts...)
+}
+
+// sshString splits the SSH string at the beginning of b from the rest.
+func sshString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(len(b)-4) < uint64(n) {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
+
+// sshWinsize decodes the columns, rows, width and height in pixels at the
+// beginning of b, as uint32, clamping them to the size of Winsize.
+func sshWinsize(b []byte) Winsize {
+	var v [4]uint16
+	for i := range v {
+		n := binary.BigEndian.Uint32(b[4*i:])
+		if n > 0xffff {
+			n = 0xffff
+		}
+		v[i] = uint16(n)
+	}
+	return Winsize{Cols: v[0], Rows: v[1], X: v[2], Y: v[3]}
+}
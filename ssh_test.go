@@ -0,0 +1,76 @@
+package pty
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestPtyRequest(t *testing.T) {
+	t.Parallel()
+
+	u32 := func(v uint32) []byte {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], v)
+		return b[:]
+	}
+	modes := []byte{53}                  // ECHO
+	modes = append(modes, u32(0)...)     // off,
+	modes = append(modes, 4)             // VKILL
+	modes = append(modes, u32(0x0b)...)  // ^K,
+	modes = append(modes, 128)           // TTY_OP_ISPEED
+	modes = append(modes, u32(38400)...) // ignored,
+	modes = append(modes, 0, 0xff, 0xff) // TTY_OP_END and garbage.
+
+	var payload []byte
+	payload = append(payload, u32(5)...)
+	payload = append(payload, "vt220"...)
+	for _, v := range []uint32{100, 30, 0, 0} {
+		payload = append(payload, u32(v)...)
+	}
+	payload = append(payload, u32(uint32(len(modes)))...)
+	payload = append(payload, modes...)
+
+	r, err := ParsePtyRequest(payload)
+	if err != nil {
+		t.Fatalf("Unexpected error from ParsePtyRequest: %s", err)
+	}
+	if r.Term != "vt220" || r.Size.Cols != 100 || r.Size.Rows != 30 || len(r.Modes) != 3 || r.Modes[53] != 0 {
+		t.Fatalf("Unexpected request: %+v", r)
+	}
+	if _, err := ParsePtyRequest(payload[:30]); err == nil {
+		t.Error("Expected an error from ParsePtyRequest of a short payload")
+	}
+
+	s, err := r.Start(exec.Command("sh", "-c", "echo $TERM; stty size; stty -a"))
+	if err != nil {
+		t.Fatalf("Unexpected error from Start: %s", err)
+	}
+	out, _ := ioutil.ReadAll(EOFReader(s))
+	_ = s.Wait()
+	_ = s.Close()
+	for _, want := range []string{"vt220\r\n", "30 100\r\n", "-echo ", "kill = ^K"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected %q in the output: %q", want, out)
+		}
+	}
+
+	// Without a TERM from the client, the one of the server is not used.
+	s, err = (&PtyRequest{Size: Winsize{Rows: 24, Cols: 80}}).Start(exec.Command("sh", "-c", "echo $TERM"))
+	if err != nil {
+		t.Fatalf("Unexpected error from Start: %s", err)
+	}
+	out, _ = ioutil.ReadAll(EOFReader(s))
+	_ = s.Wait()
+	_ = s.Close()
+	if got := strings.TrimSpace(string(out)); got != DefaultTerm {
+		t.Errorf("Unexpected TERM, got %q expected %q", got, DefaultTerm)
+	}
+
+	ws, err := ParseWindowChange(append(u32(120), append(u32(40), make([]byte, 8)...)...))
+	if err != nil || ws.Cols != 120 || ws.Rows != 40 {
+		t.Errorf("Unexpected result from ParseWindowChange, got %+v, %v", ws, err)
+	}
+}
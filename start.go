@@ -1,5 +1,5 @@
-//go:build !windows
-// +build !windows
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
 
 package pty
 
@@ -16,10 +16,48 @@ import (
 // This will resize the pty to the specified size before starting the command.
 // Starts the process in a new session and sets the controlling terminal.
 func StartWithSize(cmd *exec.Cmd, ws *Winsize) (*os.File, error) {
+	return StartWithOptions(cmd, WithSize(ws))
+}
+
+// startWithOptions starts cmd as StartWithOptions does, returning the end
+// of its separate stderr too, if any.
+func startWithOptions(cmd *exec.Cmd, o *startOptions) (pty, stderr *os.File, err error) {
 	if cmd.SysProcAttr == nil {
 		cmd.SysProcAttr = &syscall.SysProcAttr{}
 	}
-	cmd.SysProcAttr.Setsid = true
-	cmd.SysProcAttr.Setctty = true
-	return StartWithAttrs(cmd, ws, cmd.SysProcAttr)
+	if !o.attrsAsIs {
+		cmd.SysProcAttr.Setsid = true
+		cmd.SysProcAttr.Setctty = !o.noCtty
+	}
+	if o.credential != nil {
+		cmd.SysProcAttr.Credential = &syscall.Credential{
+			Uid:    o.credential.uid,
+			Gid:    o.credential.gid,
+			Groups: o.credential.groups,
+		}
+	}
+	if o.chroot != "" {
+		cmd.SysProcAttr.Chroot = o.chroot
+	}
+	return startWithAttrs(cmd, o, cmd.SysProcAttr)
+}
+
+// startOnTty starts c with tty as its controlling terminal and, unless set,
+// its standard input and outputs.
+func startOnTty(c *exec.Cmd, tty *os.File) error {
+	if c.SysProcAttr == nil {
+		c.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	c.SysProcAttr.Setsid = true
+	c.SysProcAttr.Setctty = true
+	if c.Stdin == nil {
+		c.Stdin = tty
+	}
+	if c.Stdout == nil {
+		c.Stdout = tty
+	}
+	if c.Stderr == nil {
+		c.Stderr = tty
+	}
+	return wrapErr(OpStart, c.Path, c.Start())
 }
@@ -0,0 +1,73 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestStartWithOptionsUmask(t *testing.T) {
+	t.Parallel()
+
+	c := exec.Command("sh", "-c", "umask")
+	pty, err := StartWithOptions(c, WithUmask(0o027))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartWithOptions: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+
+	out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+	_ = c.Wait()
+	if got := strings.TrimSpace(string(out)); got != "0027" {
+		t.Errorf("Unexpected umask, got %q expected %q", got, "0027")
+	}
+
+	// The umask of this process is left alone.
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+	if mask == 0o027 {
+		t.Errorf("Unexpected umask of the test process %#o", mask)
+	}
+}
+
+func TestStartWithOptionsCapabilities(t *testing.T) {
+	t.Parallel()
+
+	const (
+		capNetBindService = 10
+		capNetRaw         = 13
+	)
+	c := exec.Command("grep", "-E", "^Cap(Bnd|Amb):", "/proc/self/status")
+	caps := make([]uintptr, 0, 1) // With room for an append to go unnoticed.
+	attr := &syscall.SysProcAttr{AmbientCaps: caps}
+	c.SysProcAttr = attr
+	pty, err := StartWithOptions(c, WithDroppedCapabilities(capNetRaw), WithAmbientCaps(capNetBindService))
+	if err != nil {
+		t.Skipf("Capabilities not settable: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+	if c.SysProcAttr != attr || len(attr.AmbientCaps) != 0 || caps[:1][0] != 0 {
+		t.Errorf("Unexpected SysProcAttr of the command, changed to %+v", c.SysProcAttr)
+	}
+
+	out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+	_ = c.Wait()
+	sets := map[string]uint64{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			sets[fields[0]], _ = strconv.ParseUint(fields[1], 16, 64)
+		}
+	}
+	if sets["CapBnd:"]&(1<<capNetRaw) != 0 {
+		t.Errorf("Unexpected bounding set %#x, expected CAP_NET_RAW dropped", sets["CapBnd:"])
+	}
+	if sets["CapAmb:"]&(1<<capNetBindService) == 0 {
+		t.Errorf("Unexpected ambient set %#x, expected CAP_NET_BIND_SERVICE raised", sets["CapAmb:"])
+	}
+}
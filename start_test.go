@@ -0,0 +1,199 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStartWithOptionsSize(t *testing.T) {
+	t.Parallel()
+
+	c := exec.Command("stty", "size")
+	pty, err := StartWithOptions(c, WithSize(&Winsize{Rows: 42, Cols: 84}))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartWithOptions: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+
+	out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+	_ = c.Wait()
+	if got := strings.TrimSpace(string(out)); got != "42 84" {
+		t.Errorf("Unexpected size, got %q expected %q", got, "42 84")
+	}
+}
+
+func TestStartWithOptionsCredential(t *testing.T) {
+	t.Parallel()
+
+	if os.Getuid() != 0 {
+		t.Skip("changing credentials requires root")
+	}
+
+	c := exec.Command("id", "-u")
+	pty, err := StartWithOptions(c, WithCredential(65534, 65534, nil))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartWithOptions: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+
+	out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+	_ = c.Wait()
+	if got := strings.TrimSpace(string(out)); got != "65534" {
+		t.Errorf("Unexpected uid, got %q expected %q", got, "65534")
+	}
+}
+
+func TestStartStdinBlocking(t *testing.T) {
+	t.Parallel()
+
+	c := exec.Command("head", "-n1")
+	pty, err := Start(c)
+	if err != nil {
+		t.Fatalf("Unexpected error from Start: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+
+	time.Sleep(100 * time.Millisecond) // Let the child block on its stdin.
+	if _, err := pty.Write([]byte("ping\n")); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	if err := c.Wait(); err != nil {
+		t.Errorf("Unexpected error from Wait, stdin may be non-blocking: %s", err)
+	}
+}
+
+func TestStartWithOptionsNoCtty(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		attrs *syscall.SysProcAttr
+		opts  []StartOption
+		want  string
+	}{
+		{nil, nil, "ctty"},
+		{&syscall.SysProcAttr{}, nil, "ctty"}, // Merged, not skipped.
+		{nil, []StartOption{WithNoCtty()}, "none"},
+		{&syscall.SysProcAttr{Setsid: true}, []StartOption{WithSysProcAttrAsIs()}, "none"},
+	} {
+		c := exec.Command("sh", "-c", "(: </dev/tty) 2>/dev/null && echo ctty || echo none")
+		c.SysProcAttr = tc.attrs
+		pty, err := StartWithOptions(c, tc.opts...)
+		if err != nil {
+			t.Fatalf("Unexpected error from StartWithOptions: %s", err)
+		}
+		out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+		_ = c.Wait()
+		_ = pty.Close()
+		if got := strings.TrimSpace(string(out)); got != tc.want {
+			t.Errorf("Unexpected controlling terminal, got %q expected %q", got, tc.want)
+		}
+	}
+}
+
+func TestStartError(t *testing.T) {
+	t.Parallel()
+
+	_, err := Start(exec.Command("/nonexistent/command"))
+	var perr *PtyError
+	if !errors.As(err, &perr) || perr.Op != OpStart || perr.Path != "/nonexistent/command" {
+		t.Fatalf("Unexpected error from Start: %v", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Unexpected underlying error from Start: %v", err)
+	}
+}
+
+func TestStartWithStderr(t *testing.T) {
+	t.Parallel()
+
+	c := exec.Command("sh", "-c", "test -t 2 && echo out && echo err >&2")
+	pty, stderr, err := StartWithStderr(c)
+	if err != nil {
+		t.Fatalf("Unexpected error from StartWithStderr: %s", err)
+	}
+	defer func() { _ = pty.Close(); _ = stderr.Close() }()
+
+	out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+	errOut, _ := ioutil.ReadAll(stderr)
+	_ = c.Wait()
+	if got := strings.TrimSpace(string(out)); got != "out" {
+		t.Errorf("Unexpected output, got %q expected %q", got, "out")
+	}
+	if got := strings.TrimSpace(string(errOut)); got != "err" {
+		t.Errorf("Unexpected error output, got %q expected %q", got, "err")
+	}
+}
+
+func TestStartWithStderrPipe(t *testing.T) {
+	t.Parallel()
+
+	c := exec.Command("sh", "-c", "test -t 1 && ! test -t 2 && echo out && echo err >&2")
+	s, err := StartSession(c, WithStderrPipe())
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	errOut, _ := ioutil.ReadAll(s.Stderr())
+	out, _ := ioutil.ReadAll(s) // Linux returns EIO once the child exits.
+	_ = s.Wait()
+	if got := strings.TrimSpace(string(out)); got != "out" {
+		t.Errorf("Unexpected output, got %q expected %q", got, "out")
+	}
+	if got := string(errOut); got != "err\n" {
+		t.Errorf("Unexpected error output, got %q expected %q", got, "err\n")
+	}
+}
+
+func TestStartWithTerm(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		term, want string
+	}{
+		{"vt100", "vt100 truecolor"},
+		{"", DefaultTerm + " truecolor"},
+	} {
+		c := exec.Command("sh", "-c", "echo $TERM $COLORTERM")
+		c.Env = []string{"TERM=dumb", "PATH=" + os.Getenv("PATH")}
+		pty, err := StartWithOptions(c, WithTerm(tc.term, "COLORTERM=truecolor"))
+		if err != nil {
+			t.Fatalf("Unexpected error from StartWithOptions: %s", err)
+		}
+		out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+		_ = c.Wait()
+		_ = pty.Close()
+		if got := strings.TrimSpace(string(out)); got != tc.want {
+			t.Errorf("Unexpected environment, got %q expected %q", got, tc.want)
+		}
+	}
+}
+
+func TestStartWithOptionsPriority(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("ps"); err != nil {
+		t.Skip("ps not found")
+	}
+	c := exec.Command("sh", "-c", "ps -o nice= -p $$")
+	pty, err := StartWithOptions(c, WithPriority(7))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartWithOptions: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+
+	out, _ := ioutil.ReadAll(pty) // Linux returns EIO once the child exits.
+	_ = c.Wait()
+	if got := strings.TrimSpace(string(out)); got != "7" {
+		t.Errorf("Unexpected nice value, got %q expected %q", got, "7")
+	}
+}
@@ -1,5 +1,5 @@
-//go:build windows
-// +build windows
+//go:build windows || js || plan9 || wasip1
+// +build windows js plan9 wasip1
 
 package pty
 
@@ -17,3 +17,11 @@ import (
 func StartWithSize(cmd *exec.Cmd, ws *Winsize) (*os.File, error) {
 	return nil, ErrUnsupported
 }
+
+func startWithOptions(*exec.Cmd, *startOptions) (pty, stderr *os.File, err error) {
+	return nil, nil, ErrUnsupported
+}
+
+func startOnTty(*exec.Cmd, *os.File) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,67 @@
+package pty
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+var errStderrSet = errors.New("exec: Stderr already set")
+
+// stderrMode is where the standard error of a command goes.
+type stderrMode int
+
+const (
+	stderrTty  stderrMode = iota // Along with the standard output.
+	stderrPty                    // To a pty of its own.
+	stderrPipe                   // To a pipe.
+)
+
+// StartWithStderr starts c like StartWithOptions, with its standard error
+// connected to a pty of its own, as with WithStderrPty, or to a pipe with
+// WithStderrPipe. It returns the pty of its standard input and output, and
+// the pty or the pipe of its standard error.
+func StartWithStderr(c *exec.Cmd, opts ...StartOption) (pty, stderr *os.File, err error) {
+	o := &startOptions{stderrMode: stderrPty}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return startWithOptions(c, o)
+}
+
+// openStderr connects the standard error of c to a pty of its own or to a
+// pipe if o asks for it. It returns the end to read it from, and the end of
+// c, to close once c started.
+func openStderr(c *exec.Cmd, o *startOptions) (stderr, peer *os.File, err error) {
+	if o.stderrMode == stderrTty {
+		return nil, nil, nil
+	}
+	if c.Stderr != nil {
+		return nil, nil, errStderrSet
+	}
+	if o.stderrMode == stderrPipe {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		c.Stderr = w
+		return r, w, nil
+	}
+	pty, tty, err := Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.size != nil {
+		err = Setsize(pty, o.size)
+	}
+	if err == nil && o.credential != nil {
+		err = wrapErr(OpChown, tty.Name(), tty.Chown(int(o.credential.uid), int(o.credential.gid)))
+	}
+	if err != nil {
+		logErr("pty: close stderr tty", tty.Close()) // Best effort.
+		logErr("pty: close stderr pty", pty.Close()) // Best effort.
+		return nil, nil, err
+	}
+	c.Stderr = tty
+	return pty, tty, nil
+}
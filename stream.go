@@ -0,0 +1,79 @@
+package pty
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// Stream adapts the attached stream of a command running with a tty
+// elsewhere, such as the hijacked connection of a Docker exec or of another
+// OCI runtime, whose window is resized by a separate call. It implements
+// File and, like Session, Resize, so that code written for local commands
+// works with it too, e.g. Script or Player.
+type Stream struct {
+	name   string
+	rw     io.ReadWriteCloser
+	resize func(ws *Winsize) error
+}
+
+// NewStream returns a Stream named name reading and writing rw, and calling
+// resize on Resize. With Docker, rw is the connection returned by the exec
+// start endpoint with Tty set, and resize calls the exec resize endpoint.
+func NewStream(name string, rw io.ReadWriteCloser, resize func(ws *Winsize) error) *Stream {
+	return &Stream{name: name, rw: rw, resize: resize}
+}
+
+// Name returns the name given to NewStream.
+func (s *Stream) Name() string { return s.name }
+
+// Read reads the output of the command.
+func (s *Stream) Read(p []byte) (int, error) { return s.rw.Read(p) }
+
+// Write sends input to the command.
+func (s *Stream) Write(p []byte) (int, error) { return s.rw.Write(p) }
+
+// Resize resizes the window of the command.
+func (s *Stream) Resize(ws *Winsize) error {
+	if s.resize == nil {
+		return ErrUnsupported
+	}
+	return s.resize(ws)
+}
+
+// CloseWrite closes the input of the command, as an end of file, if the
+// stream supports half closing, like a TCP or unix connection.
+func (s *Stream) CloseWrite() error {
+	if cw, ok := s.rw.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return ErrUnsupported
+}
+
+// Close closes the stream.
+func (s *Stream) Close() error { return s.rw.Close() }
+
+// SetDeadline sets the read and write deadlines of the stream, if it
+// supports them, like a connection, and returns os.ErrNoDeadline otherwise.
+func (s *Stream) SetDeadline(t time.Time) error {
+	if d, ok := s.rw.(interface{ SetDeadline(time.Time) error }); ok {
+		return d.SetDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
+// SetReadDeadline sets the read deadline of the stream, as SetDeadline.
+func (s *Stream) SetReadDeadline(t time.Time) error {
+	if d, ok := s.rw.(readDeadliner); ok {
+		return d.SetReadDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
+// SetWriteDeadline sets the write deadline of the stream, as SetDeadline.
+func (s *Stream) SetWriteDeadline(t time.Time) error {
+	if d, ok := s.rw.(writeDeadliner); ok {
+		return d.SetWriteDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
@@ -0,0 +1,49 @@
+package pty
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	local, remote := net.Pipe()
+	var size Winsize
+	s := NewStream("exec", local, func(ws *Winsize) error {
+		size = *ws
+		return nil
+	})
+	defer func() { _ = s.Close() }()
+
+	// Act as the container, echoing lines.
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := remote.Read(buf)
+			if err != nil {
+				return
+			}
+			_, _ = remote.Write(buf[:n])
+		}
+	}()
+
+	sc, err := ParseScript(strings.NewReader("resize 50 132\nsend hello\nexpect-string hello"))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseScript: %s", err)
+	}
+	e := NewExpecter(s)
+	e.SetTimeout(5 * time.Second)
+	if err := sc.Run(context.Background(), e, s); err != nil {
+		t.Fatalf("Unexpected error from Run: %s", err)
+	}
+	if size.Rows != 50 || size.Cols != 132 {
+		t.Errorf("Unexpected size, got %dx%d", size.Rows, size.Cols)
+	}
+	if err := s.CloseWrite(); err != ErrUnsupported {
+		t.Errorf("Unexpected error from CloseWrite, got %v", err)
+	}
+}
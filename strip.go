@@ -0,0 +1,103 @@
+package pty
+
+import "io"
+
+// States of the ansiStripper.
+const (
+	stripGround   = iota
+	stripEsc      // After ESC.
+	stripEscInter // In the intermediate bytes of an escape sequence.
+	stripCSI      // In a control sequence.
+	stripString   // In an OSC, DCS, SOS, PM or APC string.
+)
+
+// ansiStripper removes escape sequences from a stream, keeping its state
+// between chunks so that sequences split across them are removed too.
+// 8 bits C1 controls are left alone as they are ambiguous with UTF-8.
+type ansiStripper struct {
+	state int
+}
+
+// strip appends p without escape sequences to dst.
+func (s *ansiStripper) strip(dst, p []byte) []byte {
+	for _, c := range p {
+		if c == 0x1b { // ESC starts a new sequence, aborting any other.
+			s.state = stripEsc
+			continue
+		}
+		switch s.state {
+		case stripGround:
+			dst = append(dst, c)
+		case stripEsc:
+			switch {
+			case c == '[':
+				s.state = stripCSI
+			case c == ']' || c == 'P' || c == 'X' || c == '^' || c == '_':
+				s.state = stripString
+			case c >= 0x20 && c <= 0x2f:
+				s.state = stripEscInter
+			case c == 0x18 || c == 0x1a: // CAN and SUB abort.
+				s.state = stripGround
+			case c >= 0x30:
+				s.state = stripGround
+			}
+		case stripEscInter:
+			if c >= 0x30 || c == 0x18 || c == 0x1a {
+				s.state = stripGround
+			}
+		case stripCSI:
+			if (c >= 0x40 && c <= 0x7e) || c == 0x18 || c == 0x1a {
+				s.state = stripGround
+			}
+		case stripString:
+			if c == 0x07 || c == 0x18 || c == 0x1a { // BEL ends xterm OSC.
+				s.state = stripGround
+			}
+		}
+	}
+	return dst
+}
+
+// StripANSI returns a Reader reading from r with the escape sequences,
+// such as colors, cursor movements and window titles, removed, to get plain
+// text out of the output of a command for logging or assertions. Sequences
+// split across reads are handled.
+func StripANSI(r io.Reader) io.Reader {
+	return &stripReader{r: r}
+}
+
+type stripReader struct {
+	r io.Reader
+	s ansiStripper
+}
+
+func (r *stripReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.r.Read(p)
+		n = len(r.s.strip(p[:0], p[:n])) // Stripping never grows p.
+		if n > 0 || err != nil || len(p) == 0 {
+			return n, err
+		}
+	}
+}
+
+// StripANSIWriter returns a Writer writing to w what is written to it with
+// the escape sequences removed, for example to tee the plain text output
+// of a Session. See StripANSI.
+func StripANSIWriter(w io.Writer) io.Writer {
+	return &stripWriter{w: w}
+}
+
+type stripWriter struct {
+	w   io.Writer
+	s   ansiStripper
+	buf []byte
+}
+
+func (w *stripWriter) Write(p []byte) (int, error) {
+	w.buf = w.s.strip(w.buf[:0], p)
+	if _, err := w.w.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,40 @@
+package pty
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStripANSI(t *testing.T) {
+	t.Parallel()
+
+	const (
+		in   = "\x1b[1;31mred\x1b[0m \x1b]0;title\x07plain\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\ \x1b(Bcharset\x1bPdcs\x1b\\\r\n\tdone"
+		want = "red plainlink charset\r\n\tdone"
+	)
+	got, err := ioutil.ReadAll(StripANSI(iotest.OneByteReader(strings.NewReader(in))))
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadAll: %s", err)
+	}
+	if string(got) != want {
+		t.Errorf("Unexpected stripped output, got %q expected %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	w := StripANSIWriter(&buf)
+	for i := 0; i < len(in); i += 3 {
+		end := i + 3
+		if end > len(in) {
+			end = len(in)
+		}
+		if _, err := w.Write([]byte(in[i:end])); err != nil {
+			t.Fatalf("Unexpected error from Write: %s", err)
+		}
+	}
+	if buf.String() != want {
+		t.Errorf("Unexpected stripped output, got %q expected %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,200 @@
+package pty
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// SupervisorOption configures a Supervisor.
+type SupervisorOption func(*Supervisor)
+
+// WithRestartBackoff sets the delay before restarting a command, doubled
+// after each restart up to max, and reset once a command ran for max.
+// It is 100 milliseconds up to 10 seconds by default.
+func WithRestartBackoff(min, max time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.minBackoff, s.maxBackoff = min, max
+	}
+}
+
+// WithMaxRestarts sets how many times a command is restarted, 5 by
+// default. A negative n means no limit.
+func WithMaxRestarts(n int) SupervisorOption {
+	return func(s *Supervisor) {
+		s.maxRestarts = n
+	}
+}
+
+// WithSupervisorSize resizes the pty to ws before starting the first
+// command.
+func WithSupervisorSize(ws *Winsize) SupervisorOption {
+	return func(s *Supervisor) {
+		s.size = ws
+	}
+}
+
+// Supervisor runs a command on a pty and restarts it on the same pty when
+// it fails, so that long-lived consoles survive the crashes of their shell
+// without their clients noticing more than the output of the new shell.
+// A command exiting successfully ends the supervision, as does reaching the
+// maximum number of restarts. Reading the pty then returns an error, EIO
+// on Linux or io.EOF.
+type Supervisor struct {
+	newCmd      func() *exec.Cmd
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxRestarts int
+	size        *Winsize
+
+	pty, tty *os.File // The tty is kept open for the next commands.
+
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	restarts int
+	err      error
+	closed   bool
+	stop     chan struct{} // Closed by Close.
+	done     chan struct{}
+}
+
+// Supervise opens a pty and starts the command returned by newCmd on it,
+// calling newCmd again for each restart.
+func Supervise(newCmd func() *exec.Cmd, opts ...SupervisorOption) (*Supervisor, error) {
+	s := &Supervisor{
+		newCmd:      newCmd,
+		minBackoff:  100 * time.Millisecond,
+		maxBackoff:  10 * time.Second,
+		maxRestarts: 5,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	pty, tty, err := Open()
+	if err != nil {
+		return nil, err
+	}
+	s.pty, s.tty = pty, tty
+	if s.size != nil {
+		err = Setsize(pty, s.size)
+	}
+	if err == nil {
+		s.cmd = newCmd()
+		err = startOnTty(s.cmd, tty)
+	}
+	if err != nil {
+		logErr("pty: close tty", tty.Close()) // Best effort.
+		logErr("pty: close pty", pty.Close()) // Best effort.
+		return nil, err
+	}
+	go s.supervise()
+	return s, nil
+}
+
+func (s *Supervisor) supervise() {
+	backoff := s.minBackoff
+	s.mu.Lock()
+	cmd := s.cmd
+	s.mu.Unlock()
+	for {
+		started := time.Now()
+		err := cmd.Wait()
+
+		s.mu.Lock()
+		if err == nil || s.closed || (s.maxRestarts >= 0 && s.restarts >= s.maxRestarts) {
+			s.finish(err)
+			return
+		}
+		s.mu.Unlock()
+
+		if time.Since(started) >= s.maxBackoff {
+			backoff = s.minBackoff
+		}
+		logDebug("pty: restarting command", "err", err, "backoff", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-s.stop:
+		}
+		if backoff *= 2; backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.finish(err)
+			return
+		}
+		cmd = s.newCmd()
+		if err := startOnTty(cmd, s.tty); err != nil {
+			s.finish(err)
+			return
+		}
+		s.cmd = cmd
+		s.restarts++
+		s.mu.Unlock()
+	}
+}
+
+// finish ends the supervision with err, and unlocks s.mu.
+func (s *Supervisor) finish(err error) {
+	s.err = err
+	s.mu.Unlock()
+	logErr("pty: close tty", s.tty.Close()) // Best effort.
+	close(s.done)
+}
+
+// Pty returns the pty the commands run on.
+func (s *Supervisor) Pty() *os.File { return s.pty }
+
+// Read reads the output of the commands.
+func (s *Supervisor) Read(p []byte) (int, error) { return s.pty.Read(p) }
+
+// Write sends input to the running command.
+func (s *Supervisor) Write(p []byte) (int, error) { return s.pty.Write(p) }
+
+// Resize resizes the pty.
+func (s *Supervisor) Resize(ws *Winsize) error { return Setsize(s.pty, ws) }
+
+// Cmd returns the running command, or the last one once done.
+func (s *Supervisor) Cmd() *exec.Cmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cmd
+}
+
+// Restarts returns how many times the command was restarted.
+func (s *Supervisor) Restarts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.restarts
+}
+
+// Done is closed once the supervision ended.
+func (s *Supervisor) Done() <-chan struct{} { return s.done }
+
+// Err returns the result of waiting for the last command, nil if it exited
+// successfully. It is only valid once Done is closed.
+func (s *Supervisor) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close stops the supervision, killing the running command, and closes the
+// pty.
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+	cmd := s.cmd
+	s.mu.Unlock()
+	_ = cmd.Process.Kill() // Best effort.
+	<-s.done
+	return s.pty.Close()
+}
@@ -0,0 +1,50 @@
+package pty
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSupervisor(t *testing.T) {
+	t.Parallel()
+
+	runs := 0
+	s, err := Supervise(func() *exec.Cmd {
+		runs++
+		return exec.Command("sh", "-c", "echo run; exit 1")
+	}, WithRestartBackoff(time.Millisecond, 10*time.Millisecond), WithMaxRestarts(2))
+	if err != nil {
+		t.Fatalf("Unexpected error from Supervise: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	out, _ := ioutil.ReadAll(EOFReader(s))
+	<-s.Done()
+	if n := strings.Count(string(out), "run\r\n"); n != 3 {
+		t.Errorf("Unexpected output of 3 runs: %q", out)
+	}
+	if s.Restarts() != 2 || runs != 3 {
+		t.Errorf("Unexpected restarts, got %d for %d runs", s.Restarts(), runs)
+	}
+	if s.Err() == nil {
+		t.Error("Expected the error of the last run")
+	}
+}
+
+func TestSupervisorClose(t *testing.T) {
+	t.Parallel()
+
+	s, err := Supervise(func() *exec.Cmd { return exec.Command("sleep", "60") }, WithMaxRestarts(-1))
+	if err != nil {
+		t.Fatalf("Unexpected error from Supervise: %s", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Unexpected error from Close: %s", err)
+	}
+	if s.Restarts() != 0 {
+		t.Errorf("Unexpected restart after Close: %d", s.Restarts())
+	}
+}
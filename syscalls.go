@@ -0,0 +1,36 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import "os"
+
+// syscalls is the layer of system calls opening and configuring ptys go
+// through. Tests swap it with withSyscalls to exercise error paths which
+// real kernels don't trigger reliably.
+type syscalls interface {
+	ioctl(fd, cmd, ptr uintptr) error
+	ioctlRet(fd, cmd, arg uintptr) (uintptr, error)
+	openFile(name string, flag int, perm os.FileMode) (*os.File, error)
+}
+
+type realSyscalls struct{}
+
+func (realSyscalls) ioctl(fd, cmd, ptr uintptr) error {
+	return ioctl_inner(fd, cmd, ptr)
+}
+
+func (realSyscalls) ioctlRet(fd, cmd, arg uintptr) (uintptr, error) {
+	return ioctl_ret(fd, cmd, arg)
+}
+
+func (realSyscalls) openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+var sys syscalls = realSyscalls{}
+
+// sysIoctl issues an ioctl through sys, retrying on EINTR.
+func sysIoctl(fd, cmd, ptr uintptr) error {
+	return ignoringEINTR(func() error { return sys.ioctl(fd, cmd, ptr) })
+}
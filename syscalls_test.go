@@ -0,0 +1,79 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+type fakeSyscalls struct {
+	realSyscalls
+	ioctlErrs   []error // Returned by the next ioctls, in order.
+	ioctlRetErr error
+	openErr     error
+	ttyOpenErr  error // Returned by the opens of a tty by path.
+}
+
+func (f *fakeSyscalls) ioctl(fd, cmd, ptr uintptr) error {
+	if len(f.ioctlErrs) > 0 {
+		err := f.ioctlErrs[0]
+		f.ioctlErrs = f.ioctlErrs[1:]
+		return err
+	}
+	return f.realSyscalls.ioctl(fd, cmd, ptr)
+}
+
+func (f *fakeSyscalls) ioctlRet(fd, cmd, arg uintptr) (uintptr, error) {
+	if f.ioctlRetErr != nil {
+		return 0, f.ioctlRetErr
+	}
+	return f.realSyscalls.ioctlRet(fd, cmd, arg)
+}
+
+func (f *fakeSyscalls) openFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	if f.ttyOpenErr != nil && strings.HasPrefix(name, "/dev/pts/") && name != "/dev/pts/ptmx" {
+		return nil, f.ttyOpenErr
+	}
+	return f.realSyscalls.openFile(name, flag, perm)
+}
+
+// withSyscalls makes the package go through s until the end of the test,
+// which must not be parallel.
+func withSyscalls(t *testing.T, s syscalls) {
+	old := sys
+	sys = s
+	t.Cleanup(func() { sys = old })
+}
+
+func TestSyscallsEINTR(t *testing.T) {
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+	defer func() { _ = tty.Close() }()
+
+	f := &fakeSyscalls{ioctlErrs: []error{syscall.EINTR, syscall.EINTR}}
+	withSyscalls(t, f)
+	if err := Setsize(pty, &Winsize{Rows: 10, Cols: 20}); err != nil {
+		t.Errorf("Unexpected error from Setsize: %s", err)
+	}
+	if len(f.ioctlErrs) != 0 {
+		t.Errorf("Unexpected EINTR left, got %d", len(f.ioctlErrs))
+	}
+}
+
+func TestSyscallsOpenError(t *testing.T) {
+	withSyscalls(t, &fakeSyscalls{openErr: syscall.ENOSPC})
+	if _, _, err := Open(); !errors.Is(err, syscall.ENOSPC) {
+		t.Errorf("Unexpected error from Open, got %v expected %v", err, syscall.ENOSPC)
+	}
+}
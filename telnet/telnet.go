@@ -0,0 +1,194 @@
+// Package telnet serves a pty session to telnet clients, for lab devices
+// and legacy equipment expecting one. The server echoes and suppresses
+// go ahead, as character mode clients expect, and follows the window size
+// of the client with NAWS, RFC 1073.
+package telnet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/creack/pty"
+)
+
+// Telnet commands and options.
+const (
+	se   = 240
+	sb   = 250
+	will = 251
+	wont = 252
+	do   = 253
+	dont = 254
+	iac  = 255
+
+	optEcho = 1
+	optSGA  = 3
+	optNAWS = 31
+)
+
+// Serve bridges conn to s until either ends, then closes both. It does not
+// wait for the command. It returns the error writing to conn, if any.
+func Serve(conn io.ReadWriteCloser, s *pty.Session) error {
+	w := &lockedWriter{w: conn}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r := &reader{r: conn, w: w, s: s}
+		_ = r.run()
+		_ = s.Close() // Best effort.
+	}()
+
+	_, err := w.Write([]byte{iac, will, optEcho, iac, will, optSGA, iac, do, optNAWS})
+	if err == nil {
+		err = copyOutput(w, s)
+	}
+	_ = s.Close()
+	_ = conn.Close()
+	<-done
+	return err
+}
+
+// lockedWriter serializes the writes of the output and of the replies to
+// the client.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// copyOutput sends the output of s, escaping IAC, until it ends.
+func copyOutput(w io.Writer, s *pty.Session) error {
+	buf := make([]byte, 32*1024)
+	var out []byte
+	for {
+		n, err := s.Read(buf)
+		if n > 0 {
+			out = append(out[:0], buf[:n]...)
+			if bytes.IndexByte(out, iac) >= 0 {
+				out = bytes.Replace(out, []byte{iac}, []byte{iac, iac}, -1)
+			}
+			if _, werr := w.Write(out); werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			return nil // EIO once the command exited on Linux.
+		}
+	}
+}
+
+// reader applies the input of the client to the session.
+type reader struct {
+	r io.Reader
+	w io.Writer // For the replies.
+	s *pty.Session
+
+	state int    // 0: data, 1: after IAC, 2: after option command, 3: in SB, 4: after IAC in SB.
+	cmd   byte   // The option command or SB option.
+	sub   []byte // The subnegotiation so far.
+	cr    bool   // After a CR.
+}
+
+func (r *reader) run() error {
+	buf := make([]byte, 4096)
+	var data []byte
+	for {
+		n, err := r.r.Read(buf)
+		data = data[:0]
+		for _, c := range buf[:n] {
+			if d, ok := r.feed(c); ok {
+				data = append(data, d)
+			}
+		}
+		if len(data) > 0 {
+			if _, err := r.s.Write(data); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// feed parses c, returning it if it is data for the session.
+func (r *reader) feed(c byte) (byte, bool) {
+	switch r.state {
+	case 1:
+		switch c {
+		case iac:
+			r.state = 0
+			return iac, true
+		case will, wont, do, dont:
+			r.state, r.cmd = 2, c
+		case sb:
+			r.state, r.sub = 3, r.sub[:0]
+		default: // NOP, GA, BRK, AYT...
+			r.state = 0
+		}
+		return 0, false
+	case 2:
+		r.negotiate(r.cmd, c)
+		r.state = 0
+		return 0, false
+	case 3:
+		if c == iac {
+			r.state = 4
+		} else if len(r.sub) < 64 {
+			r.sub = append(r.sub, c)
+		}
+		return 0, false
+	case 4:
+		if c == se {
+			r.subnegotiation()
+			r.state = 0
+		} else {
+			if len(r.sub) < 64 {
+				r.sub = append(r.sub, c) // IAC IAC in the subnegotiation.
+			}
+			r.state = 3
+		}
+		return 0, false
+	}
+
+	if c == iac {
+		r.state = 1
+		return 0, false
+	}
+	// Enter is sent as CR LF or CR NUL, the pty only wants the CR.
+	cr := r.cr
+	r.cr = c == '\r'
+	if cr && (c == '\n' || c == 0) {
+		return 0, false
+	}
+	return c, true
+}
+
+// negotiate answers the option commands of the client, refusing the
+// options other than those the server asked for.
+func (r *reader) negotiate(cmd, opt byte) {
+	var reply []byte
+	switch {
+	case cmd == do && opt != optEcho && opt != optSGA:
+		reply = []byte{iac, wont, opt}
+	case cmd == will && opt != optNAWS:
+		reply = []byte{iac, dont, opt}
+	}
+	if reply != nil {
+		_, _ = r.w.Write(reply) // Best effort.
+	}
+}
+
+func (r *reader) subnegotiation() {
+	if len(r.sub) == 5 && r.sub[0] == optNAWS {
+		ws := &pty.Winsize{Cols: binary.BigEndian.Uint16(r.sub[1:]), Rows: binary.BigEndian.Uint16(r.sub[3:])}
+		_ = r.s.Resize(ws) // Best effort.
+	}
+}
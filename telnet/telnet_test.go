@@ -0,0 +1,49 @@
+package telnet
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/creack/pty"
+)
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+
+	s, err := pty.StartSession(exec.Command("sh", "-c", `read l; stty size; echo "got $l"`))
+	if err != nil {
+		t.Fatalf("Unexpected error from StartSession: %s", err)
+	}
+	defer func() { _ = s.Wait() }()
+	sconn, cconn := net.Pipe()
+	served := make(chan error, 1)
+	go func() { served <- Serve(sconn, s) }()
+
+	hello := make([]byte, 9)
+	if _, err := cconn.Read(hello); err != nil {
+		t.Fatalf("Unexpected error from Read: %s", err)
+	}
+	if !bytes.Equal(hello, []byte{iac, will, optEcho, iac, will, optSGA, iac, do, optNAWS}) {
+		t.Errorf("Unexpected negotiation: %v", hello)
+	}
+
+	// Agree to NAWS with a size of 100x30 and refuse TTYPE, then type.
+	go func() {
+		_, _ = cconn.Write([]byte{iac, will, optNAWS, iac, sb, optNAWS, 0, 100, 0, 30, iac, se, iac, will, 24})
+		_, _ = cconn.Write([]byte("h\xff\xffi\r\n"))
+	}()
+	out, _ := ioutil.ReadAll(cconn)
+	if !bytes.Contains(out, []byte{iac, dont, 24}) {
+		t.Errorf("Expected TTYPE to be refused: %q", out)
+	}
+	if !strings.Contains(string(out), "30 100") || !strings.Contains(string(out), "got h\xff\xffi\r\n") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Unexpected error from Serve: %s", err)
+	}
+}
@@ -0,0 +1,172 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package pty
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Termios holds the attributes of a terminal, as termios(3).
+type Termios = syscall.Termios
+
+// GetTermios returns the attributes of the terminal t.
+func GetTermios(t *os.File) (*Termios, error) {
+	var tio Termios
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	if err := ioctl(t, ioctlGetTermios, uintptr(unsafe.Pointer(&tio))); err != nil {
+		return nil, wrapErr(OpGetTermios, t.Name(), err)
+	}
+	return &tio, nil
+}
+
+// eofChar returns the end of file character, VEOF, of the terminal t, or
+// the default one when it can't be read or is disabled.
+func eofChar(t *os.File) byte {
+	tio, err := GetTermios(t)
+	if err != nil || tio.Cc[syscall.VEOF] == vdisable {
+		return defaultEOF
+	}
+	return tio.Cc[syscall.VEOF]
+}
+
+// setTermios applies the attributes tio to the terminal t.
+func setTermios(t *os.File, tio *Termios) error {
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	return ioctl(t, ioctlSetTermios, uintptr(unsafe.Pointer(tio)))
+}
+
+// Indexes of the flags of a termios in termModeFlag.
+const (
+	termIflag = iota
+	termOflag
+	termCflag
+	termLflag
+)
+
+type termModeFlag struct {
+	field int
+	bit   uint64
+}
+
+// termModeFlags maps the SSH terminal mode opcodes of flags, from RFC 4254
+// section 8, to the termios flags.
+var termModeFlags = map[uint8]termModeFlag{
+	30: {termIflag, syscall.IGNPAR},
+	31: {termIflag, syscall.PARMRK},
+	32: {termIflag, syscall.INPCK},
+	33: {termIflag, syscall.ISTRIP},
+	34: {termIflag, syscall.INLCR},
+	35: {termIflag, syscall.IGNCR},
+	36: {termIflag, syscall.ICRNL},
+	38: {termIflag, syscall.IXON},
+	39: {termIflag, syscall.IXANY},
+	40: {termIflag, syscall.IXOFF},
+	41: {termIflag, syscall.IMAXBEL},
+	50: {termLflag, syscall.ISIG},
+	51: {termLflag, syscall.ICANON},
+	53: {termLflag, syscall.ECHO},
+	54: {termLflag, syscall.ECHOE},
+	55: {termLflag, syscall.ECHOK},
+	56: {termLflag, syscall.ECHONL},
+	57: {termLflag, syscall.NOFLSH},
+	58: {termLflag, syscall.TOSTOP},
+	59: {termLflag, syscall.IEXTEN},
+	60: {termLflag, syscall.ECHOCTL},
+	61: {termLflag, syscall.ECHOKE},
+	62: {termLflag, syscall.PENDIN},
+	70: {termOflag, syscall.OPOST},
+	72: {termOflag, syscall.ONLCR},
+	73: {termOflag, syscall.OCRNL},
+	74: {termOflag, syscall.ONOCR},
+	75: {termOflag, syscall.ONLRET},
+	92: {termCflag, syscall.PARENB},
+	93: {termCflag, syscall.PARODD},
+}
+
+// termModeChars maps the SSH terminal mode opcodes of special characters
+// to their index in the termios.
+var termModeChars = map[uint8]int{
+	1:  syscall.VINTR,
+	2:  syscall.VQUIT,
+	3:  syscall.VERASE,
+	4:  syscall.VKILL,
+	5:  syscall.VEOF,
+	6:  syscall.VEOL,
+	7:  syscall.VEOL2,
+	8:  syscall.VSTART,
+	9:  syscall.VSTOP,
+	10: syscall.VSUSP,
+	12: syscall.VREPRINT,
+	13: syscall.VWERASE,
+	14: syscall.VLNEXT,
+	18: syscall.VDISCARD,
+}
+
+// setTerminalModes applies modes, encoded as SSH terminal modes, to the
+// terminal t. Unknown modes, such as the speeds, are ignored.
+func setTerminalModes(t *os.File, modes map[uint8]uint32) error {
+	var tio syscall.Termios
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	if err := ioctl(t, ioctlGetTermios, uintptr(unsafe.Pointer(&tio))); err != nil {
+		return err
+	}
+
+	flags := [4]uint64{uint64(tio.Iflag), uint64(tio.Oflag), uint64(tio.Cflag), uint64(tio.Lflag)}
+	for op, v := range modes {
+		if f, ok := termModeFlags[op]; ok {
+			if v != 0 {
+				flags[f.field] |= f.bit
+			} else {
+				flags[f.field] &^= f.bit
+			}
+			continue
+		}
+		if i, ok := termModeChars[op]; ok {
+			c := uint8(v)
+			if v == 255 {
+				c = vdisable
+			}
+			tio.Cc[i] = c
+			continue
+		}
+		switch {
+		case op == 90 && v != 0: // CS7.
+			flags[termCflag] = flags[termCflag]&^syscall.CSIZE | syscall.CS7
+		case op == 91 && v != 0: // CS8.
+			flags[termCflag] = flags[termCflag]&^syscall.CSIZE | syscall.CS8
+		}
+	}
+	tio.Iflag, tio.Oflag, tio.Cflag, tio.Lflag = tcflag(flags[0]), tcflag(flags[1]), tcflag(flags[2]), tcflag(flags[3])
+
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	return ioctl(t, ioctlSetTermios, uintptr(unsafe.Pointer(&tio)))
+}
+
+// syncLine applies the line settings of src, its speed, character framing
+// and flow control, to dst, a serial device, if they differ.
+func syncLine(dst, src *os.File) error {
+	var s, d syscall.Termios
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	if err := ioctl(src, ioctlGetTermios, uintptr(unsafe.Pointer(&s))); err != nil {
+		return err
+	}
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	if err := ioctl(dst, ioctlGetTermios, uintptr(unsafe.Pointer(&d))); err != nil {
+		return err
+	}
+
+	const iflags = syscall.IXON | syscall.IXOFF | syscall.IXANY
+	const cflags = syscall.CSIZE | syscall.CSTOPB | syscall.PARENB | syscall.PARODD | cbaud | crtscts
+	n := d
+	n.Iflag = n.Iflag&^iflags | s.Iflag&iflags
+	n.Cflag = n.Cflag&^cflags | s.Cflag&cflags
+	copySpeed(&n, &s)
+	if n == d {
+		return nil
+	}
+	//nolint:gosec // Expected unsafe pointer for Syscall call.
+	return ioctl(dst, ioctlSetTermios, uintptr(unsafe.Pointer(&n)))
+}
@@ -0,0 +1,18 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package pty
+
+import "syscall"
+
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+
+	vdisable = 0xff // _POSIX_VDISABLE.
+	cbaud    = 0    // The speed is only in c_ispeed and c_ospeed.
+)
+
+func copySpeed(dst, src *syscall.Termios) {
+	dst.Ispeed, dst.Ospeed = src.Ispeed, src.Ospeed
+}
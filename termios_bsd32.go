@@ -0,0 +1,6 @@
+//go:build dragonfly || freebsd || netbsd || openbsd
+// +build dragonfly freebsd netbsd openbsd
+
+package pty
+
+type tcflag = uint32
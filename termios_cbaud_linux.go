@@ -0,0 +1,6 @@
+//go:build linux && !ppc64 && !ppc64le
+// +build linux,!ppc64,!ppc64le
+
+package pty
+
+const cbaud = 0x100f // CBAUD | CBAUDEX, from <asm-generic/termbits.h>.
@@ -0,0 +1,7 @@
+//go:build linux && (ppc64 || ppc64le)
+// +build linux
+// +build ppc64 ppc64le
+
+package pty
+
+const cbaud = 0xff // CBAUD, from <asm/termbits.h>.
@@ -0,0 +1,6 @@
+//go:build dragonfly || freebsd
+// +build dragonfly freebsd
+
+package pty
+
+const crtscts = 0x30000 // CCTS_OFLOW | CRTS_IFLOW.
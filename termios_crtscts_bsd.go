@@ -0,0 +1,6 @@
+//go:build netbsd || openbsd
+// +build netbsd openbsd
+
+package pty
+
+const crtscts = 0x10000
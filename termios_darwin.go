@@ -0,0 +1,8 @@
+//go:build darwin
+// +build darwin
+
+package pty
+
+type tcflag = uint64
+
+const crtscts = 0x30000 // CCTS_OFLOW | CRTS_IFLOW.
@@ -0,0 +1,19 @@
+//go:build linux
+// +build linux
+
+package pty
+
+import "syscall"
+
+type tcflag = uint32
+
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+
+	vdisable = 0 // _POSIX_VDISABLE.
+	crtscts  = 0x80000000
+)
+
+// copySpeed is a no-op: the speed is in the CBAUD bits of c_cflag.
+func copySpeed(dst, src *syscall.Termios) {}
@@ -0,0 +1,48 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package pty
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestOpenFull(t *testing.T) {
+	t.Parallel()
+
+	p, tt, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	term, err := GetTermios(tt)
+	_ = p.Close()
+	_ = tt.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error from GetTermios: %s", err)
+	}
+	term.Lflag &^= syscall.ECHO
+
+	pty, tty, err := OpenFull(term, &Winsize{Rows: 12, Cols: 34})
+	if err != nil {
+		t.Fatalf("Unexpected error from OpenFull: %s", err)
+	}
+	defer func() { _ = pty.Close(); _ = tty.Close() }()
+
+	c := exec.Command("sh", "-c", "stty size; stty -a")
+	c.Stdin, c.Stdout, c.Stderr = tty, tty, tty
+	if err := c.Start(); err != nil {
+		t.Fatalf("Unexpected error from Start: %s", err)
+	}
+	_ = tty.Close()
+	out, _ := ioutil.ReadAll(EOFReader(pty))
+	_ = c.Wait()
+	for _, want := range []string{"12 34\r\n", "-echo "} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected %q in the output: %q", want, out)
+		}
+	}
+}
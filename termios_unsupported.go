@@ -0,0 +1,30 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package pty
+
+import "os"
+
+// Termios holds the attributes of a terminal, unsupported on this platform.
+type Termios struct{}
+
+// GetTermios returns the attributes of the terminal t.
+func GetTermios(*os.File) (*Termios, error) {
+	return nil, ErrUnsupported
+}
+
+func eofChar(*os.File) byte {
+	return defaultEOF
+}
+
+func setTermios(*os.File, *Termios) error {
+	return ErrUnsupported
+}
+
+func setTerminalModes(*os.File, map[uint8]uint32) error {
+	return ErrUnsupported
+}
+
+func syncLine(*os.File, *os.File) error {
+	return ErrUnsupported
+}
@@ -26,6 +26,7 @@ cross() {
 set -e
 
 cross linux     amd64 386 arm arm64 ppc64 ppc64le s390x mips mipsle mips64 mips64le riscv64
+cross android   arm64
 cross darwin    amd64 arm64
 cross freebsd   amd64 386 arm arm64 riscv64
 cross netbsd    amd64 386 arm arm64
@@ -35,6 +36,8 @@ cross solaris   amd64
 
 # Not expected to work but should still compile.
 cross windows amd64 386 arm
+cross js      wasm
+cross plan9   amd64 386
 
 # TODO: Fix compilation error on openbsd/arm.
 # TODO: Merge the solaris PR.
@@ -52,8 +55,9 @@ docker build -t creack-pty-test -f Dockerfile.golang --build-arg=GOVERSION=1.14
 echo2 "  - darwin/arm"
 docker build -t creack-pty-test -f Dockerfile.golang --build-arg=GOVERSION=1.14 --build-arg=GOOS=darwin --build-arg=GOARCH=arm .
 
-# Run a single test for an old go version. Would be best with go1.0, but not available on Dockerhub.
+# Build with the oldest go version supported, the one of go.mod: the package uses context, errors.Is, sync.Map and
+# 0o literals, so go1.6 is no longer built.
 # Would also be better to run all the tests, not just one, need to refactor this file to allow for specifc archs per version.
-echo2 "Build for linux - go1.6."
+echo2 "Build for linux - go1.13."
 echo2 "  - linux/amd64"
-docker build -t creack-pty-test -f Dockerfile.golang --build-arg=GOVERSION=1.6 --build-arg=GOOS=linux --build-arg=GOARCH=amd64 .
+docker build -t creack-pty-test -f Dockerfile.golang --build-arg=GOVERSION=1.13 --build-arg=GOOS=linux --build-arg=GOARCH=amd64 .
@@ -0,0 +1,114 @@
+package pty
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Throttle is a token bucket limiting the throughput of the readers and
+// writers it wraps to rate bytes per second, with bursts of up to burst
+// bytes. A single Throttle can be shared by several readers and writers to
+// cap their combined throughput.
+type Throttle struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewThrottle returns a Throttle allowing rate bytes per second and bursts
+// of up to burst bytes. The bucket starts full. A rate of zero or less
+// means no limit.
+func NewThrottle(rate, burst int) *Throttle {
+	if rate < 0 {
+		rate = 0
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &Throttle{rate: float64(rate), burst: burst, tokens: float64(burst), last: time.Now()}
+}
+
+// wait takes n tokens from the bucket, sleeping until the debt is paid off.
+// A nil Throttle never waits.
+func (t *Throttle) wait(n int) {
+	if t == nil || t.rate == 0 {
+		return
+	}
+	t.mu.Lock()
+	now := time.Now()
+	t.tokens += now.Sub(t.last).Seconds() * t.rate
+	if t.tokens > float64(t.burst) {
+		t.tokens = float64(t.burst)
+	}
+	t.last = now
+	t.tokens -= float64(n)
+	var d time.Duration
+	if t.tokens < 0 {
+		d = time.Duration(-t.tokens / t.rate * float64(time.Second))
+	}
+	t.mu.Unlock()
+
+	time.Sleep(d)
+}
+
+// limit returns how much of n bytes fits in a single burst, all of them
+// without limit.
+func (t *Throttle) limit(n int) int {
+	if t == nil || t.rate == 0 || n <= t.burst {
+		return n
+	}
+	return t.burst
+}
+
+// Reader returns a reader reading from r within the limits of t.
+func (t *Throttle) Reader(r io.Reader) io.Reader {
+	return &throttledReader{t: t, r: r}
+}
+
+// Writer returns a writer writing to w within the limits of t.
+func (t *Throttle) Writer(w io.Writer) io.Writer {
+	return &throttledWriter{t: t, w: w}
+}
+
+// WithThrottle limits the throughput of the input written to a Session to
+// in, and of the output read from it to out. Either may be nil for no
+// limit, or both the same Throttle to cap their combined throughput.
+func WithThrottle(in, out *Throttle) StartOption {
+	return func(o *startOptions) { o.inLimit, o.outLimit = in, out }
+}
+
+type throttledReader struct {
+	t *Throttle
+	r io.Reader
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p[:r.t.limit(len(p))])
+	if n > 0 {
+		r.t.wait(n)
+	}
+	return n, err
+}
+
+type throttledWriter struct {
+	t *Throttle
+	w io.Writer
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:w.t.limit(len(p))]
+		w.t.wait(len(chunk))
+		n, err := w.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
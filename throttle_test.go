@@ -0,0 +1,72 @@
+package pty
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	t.Parallel()
+
+	// The bucket starts with 100 bytes, the remaining 200 take 200ms.
+	th := NewThrottle(1000, 100)
+	text := bytes.Repeat([]byte("x"), 300)
+
+	start := time.Now()
+	n, err := th.Writer(ioutil.Discard).Write(text)
+	if err != nil {
+		t.Errorf("Unexpected error from Write: %s", err)
+	}
+	if n != len(text) {
+		t.Errorf("Unexpected count returned from Write, got %d expected %d", n, len(text))
+	}
+	if d := time.Since(start); d < 150*time.Millisecond {
+		t.Errorf("Write was not throttled, took %s", d)
+	}
+
+	out, err := ioutil.ReadAll(NewThrottle(1<<20, 10).Reader(bytes.NewReader(text)))
+	if err != nil {
+		t.Errorf("Unexpected error from ReadAll: %s", err)
+	}
+	if !bytes.Equal(out, text) {
+		t.Error("Unexpected result returned from Read")
+	}
+}
+
+func TestThrottleUnlimited(t *testing.T) {
+	t.Parallel()
+
+	for _, rate := range []int{0, -1} {
+		th := NewThrottle(rate, 10)
+		start := time.Now()
+		if _, err := th.Writer(ioutil.Discard).Write(bytes.Repeat([]byte("x"), 1000)); err != nil {
+			t.Errorf("Unexpected error from Write: %s", err)
+		}
+		if d := time.Since(start); d > 100*time.Millisecond {
+			t.Errorf("Unexpected throttling with a rate of %d, took %s", rate, d)
+		}
+	}
+}
+
+// callCounter counts the calls to Read and Write.
+type callCounter struct{ calls int }
+
+func (c *callCounter) Read(p []byte) (int, error)  { c.calls++; return len(p), nil }
+func (c *callCounter) Write(p []byte) (int, error) { c.calls++; return len(p), nil }
+
+func TestThrottleUnlimitedChunks(t *testing.T) {
+	t.Parallel()
+
+	th := NewThrottle(0, 0)
+	buf := make([]byte, 1<<16)
+	var c callCounter
+	if n, err := th.Writer(&c).Write(buf); err != nil || n != len(buf) || c.calls != 1 {
+		t.Errorf("Unexpected Write, got %d in %d calls, %v", n, c.calls, err)
+	}
+	c.calls = 0
+	if n, err := th.Reader(&c).Read(buf); err != nil || n != len(buf) || c.calls != 1 {
+		t.Errorf("Unexpected Read, got %d in %d calls, %v", n, c.calls, err)
+	}
+}
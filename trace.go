@@ -0,0 +1,42 @@
+package pty
+
+// Operations reported to a Tracer, and in a PtyError.
+const (
+	OpOpen   = "open"   // Allocation of the pty.
+	OpStart  = "start"  // Start of the command.
+	OpResize = "resize" // Resize of a session.
+	OpClose  = "close"  // Close of a session.
+	OpWait   = "wait"   // Wait for the command of a session to exit.
+
+	OpGetsize     = "getsize"     // Read of the size, in a PtyError only.
+	OpGetTermios  = "gettermios"  // Read of the terminal attributes, in a PtyError only.
+	OpSetModes    = "setmodes"    // Setting of the terminal modes, in a PtyError only.
+	OpChown       = "chown"       // Change of the owner of the tty, in a PtyError only.
+	OpSetNonblock = "setnonblock" // Setting of the non-blocking mode, in a PtyError only.
+	OpReadV       = "readv"       // Read of ReadV, in a PtyError only.
+	OpWriteV      = "writev"      // Write of WriteV, in a PtyError only.
+)
+
+// Tracer is notified of the lifecycle operations of sessions, so they can be
+// recorded as spans, for example with OpenTelemetry, to trace slow session
+// setups. Span is called when op begins and returns a function called with
+// the outcome of op when it ends. For OpWait, that is the exit status of
+// the command.
+type Tracer interface {
+	Span(op string) (end func(err error))
+}
+
+// WithTracer reports the operations of the command, and of the session for
+// StartSession, to t.
+func WithTracer(t Tracer) StartOption {
+	return func(o *startOptions) { o.tracer = t }
+}
+
+func noopEnd(error) {}
+
+func trace(t Tracer, op string) func(error) {
+	if t == nil {
+		return noopEnd
+	}
+	return t.Span(op)
+}
@@ -0,0 +1,183 @@
+package pty
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// transcriptTime is the layout of the timestamps prefixing each line of a
+// transcript, and of the suffix of rotated transcripts.
+const (
+	transcriptTime       = "2006-01-02T15:04:05.000Z07:00"
+	transcriptRotateTime = "20060102T150405.000000000"
+)
+
+// TranscriptOption configures a Transcript.
+type TranscriptOption func(*Transcript)
+
+// WithTranscriptMaxSize rotates the transcript once it reaches size bytes.
+// Zero, the default, means no limit.
+func WithTranscriptMaxSize(size int64) TranscriptOption {
+	return func(t *Transcript) { t.maxSize = size }
+}
+
+// WithTranscriptMaxAge rotates the transcript once it is older than d.
+// Zero, the default, means no limit.
+func WithTranscriptMaxAge(d time.Duration) TranscriptOption {
+	return func(t *Transcript) { t.maxAge = d }
+}
+
+// WithTranscriptMaxBackups removes the oldest rotated transcripts beyond
+// n. Zero, the default, keeps them all.
+func WithTranscriptMaxBackups(n int) TranscriptOption {
+	return func(t *Transcript) { t.maxBackups = n }
+}
+
+// Transcript is an io.WriteCloser writing a log of terminal activity to a
+// file, each line prefixed with the time it started, for example with
+// Session.Tee. The file is rotated when it gets too large or too old:
+// it is renamed with the time of the rotation as suffix and a new one is
+// started. It is safe for concurrent use.
+type Transcript struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	now        func() time.Time
+
+	mu        sync.Mutex
+	f         *os.File
+	size      int64
+	opened    time.Time
+	lineStart bool
+}
+
+// NewTranscript opens the transcript at path, appending to it if it exists.
+func NewTranscript(path string, opts ...TranscriptOption) (*Transcript, error) {
+	t := &Transcript{path: path, now: time.Now}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if err := t.open(t.now()); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Transcript) open(now time.Time) error {
+	f, err := os.OpenFile(t.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close() // Best effort.
+		return err
+	}
+	t.f, t.size, t.opened, t.lineStart = f, fi.Size(), now, true
+	return nil
+}
+
+// Write writes p to the transcript, prefixing each line with the time.
+func (t *Transcript) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.f == nil {
+		return 0, os.ErrClosed
+	}
+	now := t.now()
+	if (t.maxSize > 0 && t.size >= t.maxSize) || (t.maxAge > 0 && now.Sub(t.opened) >= t.maxAge) {
+		if err := t.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	var buf bytes.Buffer
+	stamp := now.Format(transcriptTime) + " "
+	for rest := p; len(rest) > 0; {
+		if t.lineStart {
+			buf.WriteString(stamp)
+		}
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+		}
+		buf.Write(line)
+		rest = rest[len(line):]
+		t.lineStart = line[len(line)-1] == '\n'
+	}
+	n, err := t.f.Write(buf.Bytes())
+	t.size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// rotate renames the current transcript and opens a new one. If it can not
+// be renamed, it is reopened to keep writing to it, and rotated later.
+func (t *Transcript) rotate(now time.Time) error {
+	err := t.f.Close()
+	t.f = nil
+	if err == nil {
+		err = os.Rename(t.path, t.path+"."+now.UTC().Format(transcriptRotateTime))
+	}
+	if err != nil {
+		logErr("pty: rotate transcript", err)
+		lineStart := t.lineStart
+		if err := t.open(now); err != nil {
+			return err
+		}
+		t.lineStart = lineStart
+		return nil
+	}
+	if t.maxBackups > 0 {
+		logErr("pty: remove old transcripts", t.prune()) // Best effort.
+	}
+	return t.open(now)
+}
+
+// prune removes the oldest rotated transcripts beyond maxBackups.
+func (t *Transcript) prune() error {
+	dir, base := filepath.Split(t.path)
+	entries, err := ioutil.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return err
+	}
+	var rotated []string
+	for _, e := range entries {
+		suffix := strings.TrimPrefix(e.Name(), base+".")
+		if suffix == e.Name() || len(suffix) != len(transcriptRotateTime) {
+			continue
+		}
+		if _, err := time.Parse(transcriptRotateTime, suffix); err == nil {
+			rotated = append(rotated, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(rotated) // Oldest first, thanks to the time layout.
+	for len(rotated) > t.maxBackups {
+		if err := os.Remove(rotated[0]); err != nil {
+			return err
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
+
+// Close closes the transcript.
+func (t *Transcript) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.f == nil {
+		return os.ErrClosed
+	}
+	err := t.f.Close()
+	t.f = nil
+	return err
+}
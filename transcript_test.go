@@ -0,0 +1,118 @@
+package pty
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTranscript(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "transcript")
+	if err != nil {
+		t.Fatalf("Unexpected error from TempDir: %s", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "session.log")
+	tr, err := NewTranscript(path, WithTranscriptMaxSize(32), WithTranscriptMaxBackups(1))
+	if err != nil {
+		t.Fatalf("Unexpected error from NewTranscript: %s", err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	for _, s := range []string{"hello ", "world\nfoo\n", "bar\n", "baz\n", "qux\n"} {
+		now = now.Add(time.Second)
+		if _, err := tr.Write([]byte(s)); err != nil {
+			t.Fatalf("Unexpected error from Write: %s", err)
+		}
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Unexpected error from Close: %s", err)
+	}
+
+	names, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatalf("Unexpected error from Glob: %s", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("Unexpected transcripts, got %q", names)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadFile: %s", err)
+	}
+	if want := "2024-01-01T00:00:05.000Z qux\n"; string(b) != want {
+		t.Errorf("Unexpected transcript, got %q expected %q", b, want)
+	}
+	b, err = ioutil.ReadFile(names[1])
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadFile: %s", err)
+	}
+	if want := "2024-01-01T00:00:03.000Z bar\n2024-01-01T00:00:04.000Z baz\n"; string(b) != want {
+		t.Errorf("Unexpected rotated transcript, got %q expected %q", b, want)
+	}
+}
+
+func TestTranscriptRotateFailure(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "transcript")
+	if err != nil {
+		t.Fatalf("Unexpected error from TempDir: %s", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// Glob metacharacters in the path, and unrelated files next to it.
+	path := filepath.Join(dir, "session[1].log")
+	for _, name := range []string{"session[1].log.bak", "session1.log.20240101T000000.000000000"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatalf("Unexpected error from WriteFile: %s", err)
+		}
+	}
+	tr, err := NewTranscript(path, WithTranscriptMaxSize(1), WithTranscriptMaxBackups(1))
+	if err != nil {
+		t.Fatalf("Unexpected error from NewTranscript: %s", err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	// A non-empty directory in the way of the first rotation.
+	blocker := path + "." + now.Add(2*time.Second).UTC().Format(transcriptRotateTime)
+	if err := os.MkdirAll(filepath.Join(blocker, "x"), 0o700); err != nil {
+		t.Fatalf("Unexpected error from MkdirAll: %s", err)
+	}
+	for _, s := range []string{"foo\n", "bar\n", "baz\n"} {
+		now = now.Add(time.Second)
+		if _, err := tr.Write([]byte(s)); err != nil {
+			t.Fatalf("Unexpected error from Write: %s", err)
+		}
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Unexpected error from Close: %s", err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadFile: %s", err)
+	}
+	if want := "2024-01-01T00:00:03.000Z baz\n"; string(b) != want {
+		t.Errorf("Unexpected transcript, got %q expected %q", b, want)
+	}
+	b, err = ioutil.ReadFile(path + "." + now.UTC().Format(transcriptRotateTime))
+	if err != nil {
+		t.Fatalf("Unexpected error from ReadFile: %s", err)
+	}
+	if want := "2024-01-01T00:00:01.000Z foo\n2024-01-01T00:00:02.000Z bar\n"; string(b) != want {
+		t.Errorf("Unexpected rotated transcript, got %q expected %q", b, want)
+	}
+	for _, name := range []string{"session[1].log.bak", "session1.log.20240101T000000.000000000"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Unexpected removal of %s: %s", name, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package pty
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+)
+
+// Transformer transforms the input or output of a Session copied to its
+// taps, such as Tee writers and recorders. See Session.AddTransformer.
+type Transformer interface {
+	// Transform returns p, of the given kind, EventInput or EventOutput,
+	// transformed. It may modify p in place.
+	Transform(kind EventKind, p []byte) []byte
+}
+
+// flusher is implemented by the transformers holding back data, to be
+// returned, transformed, once the session is closed.
+type flusher interface {
+	Flush(kind EventKind) []byte
+}
+
+// AddTransformer adds t to the transformers applied, in order, to the
+// input and output of the session before it is copied to its taps, for
+// example to redact secrets from audit logs and recordings. The data read
+// from and written to the session is left untouched. Transformers holding
+// back data, with a Flush(kind EventKind) []byte method as Redactor has,
+// are flushed to the taps when the session is closed.
+func (s *Session) AddTransformer(t Transformer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.xforms = append(s.xforms[:len(s.xforms):len(s.xforms)], t)
+}
+
+// transform applies xforms to a copy of p.
+func transform(xforms []Transformer, kind EventKind, p []byte) []byte {
+	if len(xforms) == 0 {
+		return p
+	}
+	p = append([]byte(nil), p...)
+	for _, t := range xforms {
+		p = t.Transform(kind, p)
+	}
+	return p
+}
+
+// flushTransform returns the data of the given kind held back by xforms,
+// transformed by the transformers following the one holding it.
+func flushTransform(xforms []Transformer, kind EventKind) []byte {
+	var p []byte
+	for _, t := range xforms {
+		if len(p) > 0 {
+			p = t.Transform(kind, p)
+		}
+		if f, ok := t.(flusher); ok {
+			p = append(p, f.Flush(kind)...)
+		}
+	}
+	return p
+}
+
+// redacted replaces the secrets masked by a Redactor.
+var redacted = []byte("[REDACTED]")
+
+// passwordPrompt matches output ending with a password prompt.
+var passwordPrompt = regexp.MustCompile(`(?i)(password|passphrase)[^\n]*:\s*$`)
+
+// awsAccessKey matches AWS access key ids.
+var awsAccessKey = regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)
+
+// awsSecretKey matches AWS secret access keys, which have no prefix of
+// their own, assigned to a key named like aws_secret_access_key, as in
+// credential files, environment variables, and the JSON output of the
+// AWS CLI. Only the secret is masked, the groups around it are kept.
+var awsSecretKey = regexp.MustCompile(`(?i)(secret[_-]?access[_-]?key["']?\s*[:=]\s*["']?)[A-Za-z0-9/+]{40}([^A-Za-z0-9/+=]|$)`)
+
+// awsSecretRedacted replaces the matches of awsSecretKey.
+var awsSecretRedacted = []byte("${1}[REDACTED]${2}")
+
+// redactHold bounds the data of a line a Redactor holds back, waiting for
+// the end of the line to match its patterns on the whole of it.
+const redactHold = 1024
+
+// Redactor is a Transformer masking secrets: AWS access key ids, AWS
+// secret access keys assigned to a key named like aws_secret_access_key,
+// the input typed after a password prompt, and any pattern or word added to
+// it. Each secret is replaced by "[REDACTED]", whatever its length, and
+// a password by a single one however many chunks it is typed in.
+//
+// Input is typed a key at a time and output is read in arbitrary chunks, so
+// the Redactor holds back each line until its end, or until it is over 1KiB
+// long, to match its patterns on the whole line. A password prompt is not
+// held back. What is still held back once the session is closed is
+// flushed to its taps. It is safe for concurrent use.
+type Redactor struct {
+	mu       sync.Mutex
+	patterns []*regexp.Regexp
+	held     map[EventKind][]byte // The end of the last line, by kind.
+	masking  bool                 // Whether input is masked until the end of the line.
+	masked   bool                 // Whether the mask of the line was output.
+}
+
+// NewRedactor returns a Redactor masking AWS credentials and passwords.
+func NewRedactor() *Redactor {
+	return &Redactor{patterns: []*regexp.Regexp{awsAccessKey}}
+}
+
+// AddPattern masks the matches of re too.
+func (r *Redactor) AddPattern(re *regexp.Regexp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.patterns = append(r.patterns, re)
+}
+
+// AddWords masks words too.
+func (r *Redactor) AddWords(words ...string) {
+	for _, w := range words {
+		r.AddPattern(regexp.MustCompile(regexp.QuoteMeta(w)))
+	}
+}
+
+// Transform implements Transformer. It returns the lines of p, and of the
+// data held back, which ended, holding back the rest.
+func (r *Redactor) Transform(kind EventKind, p []byte) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.held == nil {
+		r.held = map[EventKind][]byte{}
+	}
+
+	var out []byte
+	if kind == EventInput && r.masking {
+		end := bytes.IndexAny(p, "\r\n")
+		if end < 0 {
+			end = len(p)
+		} else {
+			r.masking = false
+		}
+		if end > 0 && !r.masked {
+			// Typed after what was held back.
+			out = append(r.redact(r.held[kind]), redacted...)
+			r.held[kind], r.masked = nil, true
+		}
+		p = p[end:]
+	}
+
+	data := append(r.held[kind], p...)
+	cut := bytes.LastIndexAny(data, "\r\n") + 1
+	if kind == EventOutput && passwordPrompt.Match(data) {
+		r.masking, r.masked = true, false
+		cut = len(data)
+	} else if len(data)-cut > redactHold {
+		cut = len(data)
+	}
+	r.held[kind] = append([]byte(nil), data[cut:]...)
+	return append(out, r.redact(data[:cut])...)
+}
+
+// Flush returns the data of the given kind held back, redacted.
+func (r *Redactor) Flush(kind EventKind) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.held[kind]
+	delete(r.held, kind)
+	return r.redact(p)
+}
+
+// redact masks the secrets matched in p.
+func (r *Redactor) redact(p []byte) []byte {
+	if len(p) == 0 {
+		return nil
+	}
+	p = awsSecretKey.ReplaceAll(p, awsSecretRedacted)
+	for _, re := range r.patterns {
+		p = re.ReplaceAllLiteral(p, redacted)
+	}
+	return p
+}
@@ -0,0 +1,74 @@
+package pty
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RecordTtyrec records s to w in the ttyrec format used by ttyplay and
+// ipbt. The format has no room for resizes or input, which are not
+// recorded. See Recorder.
+func RecordTtyrec(s *Session, w io.Writer, opts ...RecordOption) (*Recorder, error) {
+	start := time.Now()
+	return newRecorder(s, start, &ttyrec{w: w, start: start}, opts), nil
+}
+
+type ttyrec struct {
+	w     io.Writer
+	start time.Time
+	hdr   [12]byte
+}
+
+// output writes a frame: the absolute time in seconds and microseconds and
+// the length of p as little endian 32 bits integers, followed by p.
+func (r *ttyrec) output(t time.Duration, p []byte) error {
+	at := r.start.Add(t)
+	binary.LittleEndian.PutUint32(r.hdr[0:], uint32(at.Unix()))
+	binary.LittleEndian.PutUint32(r.hdr[4:], uint32(at.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(r.hdr[8:], uint32(len(p)))
+	if _, err := r.w.Write(r.hdr[:]); err != nil {
+		return err
+	}
+	_, err := r.w.Write(p)
+	return err
+}
+
+func (r *ttyrec) resize(time.Duration, *Winsize) error { return nil }
+
+func (r *ttyrec) close() error { return nil }
+
+// LoadTtyrec loads a ttyrec recording from r.
+func LoadTtyrec(r io.Reader) (*Recording, error) {
+	br := bufio.NewReader(r)
+	rec := &Recording{}
+	var (
+		hdr   [12]byte
+		start time.Time
+	)
+	for {
+		if _, err := io.ReadFull(br, hdr[:]); err == io.EOF {
+			return rec, nil
+		} else if err != nil {
+			return nil, err
+		}
+		at := time.Unix(int64(binary.LittleEndian.Uint32(hdr[0:])), int64(binary.LittleEndian.Uint32(hdr[4:]))*1000)
+		if start.IsZero() {
+			start = at
+		}
+		n := binary.LittleEndian.Uint32(hdr[8:])
+		if n > maxEventData {
+			return nil, fmt.Errorf("ttyrec frame too large: %d bytes", n)
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(br, data); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		rec.Events = append(rec.Events, Event{Time: at.Sub(start), Kind: EventOutput, Data: data})
+	}
+}
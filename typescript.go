@@ -0,0 +1,86 @@
+package pty
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// scriptTime is the date layout used by script(1) in its header and footer.
+const scriptTime = "2006-01-02 15:04:05-07:00"
+
+// RecordTypescript records s like script(1) does with its -t option:
+// typescript receives the raw output between a header and a footer line,
+// and timing the delay and size of each chunk, so that the recording can
+// be played back with scriptreplay(1). Resizes and input are not recorded.
+// See Recorder.
+func RecordTypescript(s *Session, typescript, timing io.Writer, opts ...RecordOption) (*Recorder, error) {
+	start := time.Now()
+	ws := initialSize(s)
+	header := fmt.Sprintf("Script started on %s [", start.Format(scriptTime))
+	if term := sessionTerm(s); term != "" {
+		header += "TERM=" + strconv.Quote(term) + " "
+	}
+	header += fmt.Sprintf("COLUMNS=\"%d\" LINES=\"%d\"]\n", ws.Cols, ws.Rows)
+	if _, err := io.WriteString(typescript, header); err != nil {
+		return nil, err
+	}
+	return newRecorder(s, start, &typescriptFormat{typescript: typescript, timing: timing, start: start}, opts), nil
+}
+
+type typescriptFormat struct {
+	typescript io.Writer
+	timing     io.Writer
+	start      time.Time
+	last       time.Duration
+}
+
+func (f *typescriptFormat) output(t time.Duration, p []byte) error {
+	if _, err := fmt.Fprintf(f.timing, "%.6f %d\n", (t - f.last).Seconds(), len(p)); err != nil {
+		return err
+	}
+	f.last = t
+	_, err := f.typescript.Write(p)
+	return err
+}
+
+func (f *typescriptFormat) resize(time.Duration, *Winsize) error { return nil }
+
+func (f *typescriptFormat) close() error {
+	_, err := fmt.Fprintf(f.typescript, "\nScript done on %s\n", time.Now().Format(scriptTime))
+	return err
+}
+
+// LoadTypescript loads a recording made by script(1) from typescript and
+// its timing file, in the classic format read by scriptreplay(1).
+func LoadTypescript(typescript, timing io.Reader) (*Recording, error) {
+	ts := bufio.NewReader(typescript)
+	if _, err := ts.ReadString('\n'); err != nil { // Skip the header.
+		return nil, err
+	}
+	rec := &Recording{}
+	var t time.Duration
+	sc := bufio.NewScanner(timing)
+	for line := 1; sc.Scan(); line++ {
+		var (
+			delay float64
+			n     int
+		)
+		if _, err := fmt.Sscanf(sc.Text(), "%f %d", &delay, &n); err != nil || n < 0 {
+			return nil, fmt.Errorf("timing line %d: malformed %q", line, sc.Text())
+		}
+		if n > maxEventData {
+			return nil, fmt.Errorf("timing line %d: chunk too large: %d bytes", line, n)
+		}
+		t += time.Duration(delay * float64(time.Second))
+		data := make([]byte, n)
+		if _, err := io.ReadFull(ts, data); err != nil {
+			return nil, errors.New("typescript shorter than its timing")
+		}
+		rec.Events = append(rec.Events, Event{Time: t, Kind: EventOutput, Data: data})
+	}
+	return rec, sc.Err()
+}
@@ -0,0 +1,11 @@
+//go:build go1.21
+// +build go1.21
+
+package pty
+
+import "errors"
+
+// Is makes ErrUnsupported match errors.ErrUnsupported.
+func (unsupportedError) Is(target error) bool {
+	return target == errors.ErrUnsupported
+}
@@ -0,0 +1,24 @@
+//go:build go1.21
+// +build go1.21
+
+package pty
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrUnsupported(t *testing.T) {
+	t.Parallel()
+
+	err := wrapErr(OpOpen, "", ErrUnsupported)
+	if err != ErrUnsupported {
+		t.Errorf("Unexpected wrapped ErrUnsupported, got %v", err)
+	}
+	if !errors.Is(err, errors.ErrUnsupported) || !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Unexpected errors.Is mismatch for %v", err)
+	}
+	if errors.Is(errors.ErrUnsupported, ErrUnsupported) {
+		t.Error("Unexpected match of errors.ErrUnsupported as ErrUnsupported")
+	}
+}
@@ -0,0 +1,33 @@
+package pty
+
+import (
+	"io"
+	"os"
+)
+
+// ReadV reads from pty into bufs with a single call, filling each buffer in
+// turn, like readv(2). It returns the total number of bytes read.
+func ReadV(pty *os.File, bufs [][]byte) (n int, err error) {
+	n, err = readv(pty, bufs)
+	return n, wrapIOErr(OpReadV, pty, err)
+}
+
+// WriteV writes the content of bufs to pty in order, like writev(2), without
+// first concatenating them. It returns the total number of bytes written and
+// an error if it is less than the total length of bufs.
+func WriteV(pty *os.File, bufs [][]byte) (n int, err error) {
+	n, err = writev(pty, bufs)
+	return n, wrapIOErr(OpWriteV, pty, err)
+}
+
+// wrapIOErr wraps the error of an I/O on f in a PtyError, unwrapping the
+// os.PathError of f, but io.EOF, returned as is.
+func wrapIOErr(op string, f *os.File, err error) error {
+	if err == io.EOF {
+		return err
+	}
+	if pe, ok := err.(*os.PathError); ok {
+		err = pe.Err
+	}
+	return wrapErr(op, f.Name(), err)
+}
@@ -0,0 +1,28 @@
+//go:build (!linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd) || !go1.12
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd !go1.12
+
+package pty
+
+import "os"
+
+// readv fills only the first non empty buffer, which readv(2) allows.
+func readv(f *os.File, bufs [][]byte) (int, error) {
+	for _, b := range bufs {
+		if len(b) > 0 {
+			return f.Read(b)
+		}
+	}
+	return 0, nil
+}
+
+func writev(f *os.File, bufs [][]byte) (int, error) {
+	written := 0
+	for _, b := range bufs {
+		n, err := f.Write(b)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
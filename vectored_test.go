@@ -0,0 +1,72 @@
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
+
+package pty
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestReadVWriteV(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	defer func() { _ = pty.Close() }()
+	defer func() { _ = tty.Close() }()
+
+	n, err := WriteV(tty, [][]byte{[]byte("head"), nil, []byte("payload")})
+	if err != nil {
+		t.Errorf("Unexpected error from WriteV: %s", err)
+	}
+	if n != 11 {
+		t.Errorf("Unexpected count returned from WriteV, got %d expected %d", n, 11)
+	}
+
+	head, payload := make([]byte, 4), make([]byte, 7)
+	read := 0
+	for read < 11 {
+		bufs := [][]byte{head, payload}
+		if read < len(head) {
+			bufs[0] = head[read:]
+		} else {
+			bufs = [][]byte{payload[read-len(head):]}
+		}
+		n, err := ReadV(pty, bufs)
+		if err != nil {
+			t.Fatalf("Unexpected error from ReadV: %s", err)
+		}
+		read += n
+	}
+	if !bytes.Equal(head, []byte("head")) || !bytes.Equal(payload, []byte("payload")) {
+		t.Errorf("Unexpected result returned from ReadV, got %q and %q", head, payload)
+	}
+}
+
+func TestReadVWriteVErrors(t *testing.T) {
+	t.Parallel()
+
+	pty, tty, err := Open()
+	if err != nil {
+		t.Fatalf("Unexpected error from Open: %s", err)
+	}
+	_ = tty.Close()
+	_ = pty.Close()
+
+	buf := make([]byte, 4)
+	for op, fn := range map[string]func() (int, error){
+		OpReadV:  func() (int, error) { return ReadV(pty, [][]byte{buf}) },
+		OpWriteV: func() (int, error) { return WriteV(pty, [][]byte{buf}) },
+	} {
+		_, err := fn()
+		var perr *PtyError
+		if !errors.As(err, &perr) || perr.Op != op || !errors.Is(err, os.ErrClosed) {
+			t.Errorf("Unexpected error from %s of a closed pty, got %#v", op, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+//go:build (linux || darwin || dragonfly || freebsd || netbsd || openbsd) && go1.12
+// +build linux darwin dragonfly freebsd netbsd openbsd
+// +build go1.12
+
+package pty
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// maxIovecs is IOV_MAX on all the supported platforms.
+const maxIovecs = 1024
+
+func iovecs(bufs [][]byte) []syscall.Iovec {
+	iov := make([]syscall.Iovec, 0, len(bufs))
+	for _, b := range bufs {
+		if len(b) == 0 {
+			continue
+		}
+		v := syscall.Iovec{Base: &b[0]}
+		v.SetLen(len(b))
+		if iov = append(iov, v); len(iov) == maxIovecs {
+			break
+		}
+	}
+	return iov
+}
+
+func readv(f *os.File, bufs [][]byte) (int, error) {
+	iov := iovecs(bufs)
+	if len(iov) == 0 {
+		return 0, nil
+	}
+	sc, err := f.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		n    int
+		serr error
+	)
+	if err := sc.Read(func(fd uintptr) bool {
+		serr = ignoringEINTR(func() error {
+			r, _, e := syscall.Syscall(syscall.SYS_READV, fd, uintptr(unsafe.Pointer(&iov[0])), uintptr(len(iov)))
+			if e != 0 {
+				return e
+			}
+			n = int(r)
+			return nil
+		})
+		return serr != syscall.EAGAIN
+	}); err != nil {
+		return 0, rawConnErr(err)
+	}
+	if serr != nil {
+		return 0, serr
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func writev(f *os.File, bufs [][]byte) (int, error) {
+	sc, err := f.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	bufs = append([][]byte(nil), bufs...) // Don't modify the caller's slice.
+	written := 0
+	for {
+		iov := iovecs(bufs)
+		if len(iov) == 0 {
+			return written, nil
+		}
+
+		var (
+			n    int
+			serr error
+		)
+		if err := sc.Write(func(fd uintptr) bool {
+			serr = ignoringEINTR(func() error {
+				r, _, e := syscall.Syscall(syscall.SYS_WRITEV, fd, uintptr(unsafe.Pointer(&iov[0])), uintptr(len(iov)))
+				if e != 0 {
+					return e
+				}
+				n = int(r)
+				return nil
+			})
+			return serr != syscall.EAGAIN
+		}); err != nil {
+			return written, rawConnErr(err)
+		}
+		if serr != nil {
+			return written, serr
+		}
+		written += n
+
+		// Skip what was written and go again with the remainder.
+		for n > 0 && len(bufs) > 0 {
+			if n < len(bufs[0]) {
+				bufs[0] = bufs[0][n:]
+				break
+			}
+			n -= len(bufs[0])
+			bufs = bufs[1:]
+		}
+	}
+}
+
+// rawConnErr returns the error to report for err, returned by the Read or
+// Write of the syscall.RawConn of a file: a timeout, or the internal error
+// of a closed file, reported as os.ErrClosed as the methods of os.File do.
+func rawConnErr(err error) error {
+	if os.IsTimeout(err) {
+		return err
+	}
+	return os.ErrClosed
+}
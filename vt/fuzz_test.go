@@ -0,0 +1,33 @@
+//go:build go1.18
+// +build go1.18
+
+package vt
+
+import "testing"
+
+func FuzzTerminal(f *testing.F) {
+	for _, s := range []string{
+		"hello\r\nworld",
+		"\x1b[2;3Hx\x1b[A\x1b[10;10Hz\x1b[K\x1b[1J",
+		"\x1b[?1049h\x1b[1;31;48;2;1;2;3mA\x1b[0m\x1b[?1049l",
+		"\x1b[9;9H\x1b7\x1b[?1049h",
+		"\x1b7\x1b[2;3r\x1b[5L\x1b[3M\x1b[4@\x1b[2P\x1b8",
+		"\x1b]0;title\x07\x1bPdcs\x1b\\\xc3\xa9",
+	} {
+		f.Add([]byte(s), uint8(4), uint8(6))
+	}
+	f.Fuzz(func(t *testing.T, b []byte, rows, cols uint8) {
+		term := New(int(rows), int(cols))
+		half := len(b) / 2
+		_, _ = term.Write(b[:half])
+		term.Resize(int(cols), int(rows))
+		_, _ = term.Write(b[half:])
+		_, _ = term.Write([]byte("\x1b8\x1b[?1049lx"))
+
+		r, c := term.Size()
+		row, col := term.Cursor()
+		if row < 0 || row >= r || col < 0 || col >= c {
+			t.Fatalf("Cursor out of the screen: %d,%d in %dx%d", row, col, r, c)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package vt
+
+// maxOSC bounds the OSC kept by an OSCScanner, longer ones are truncated.
+const maxOSC = 1024
+
+// OSCScanner finds the OSC sequences in a stream, split across writes or
+// not, for those who need the titles or the markers set by a program but
+// not the screen. The zero value is ready to use.
+type OSCScanner struct {
+	state int    // 0: ground, 1: after ESC, 2: in OSC, 3: after ESC in OSC.
+	buf   []byte // The OSC so far.
+}
+
+// Scan calls fn with each OSC ended in p, between ESC ] and BEL or ST, for
+// instance "0;title". The slice is only valid during the call.
+func (s *OSCScanner) Scan(p []byte, fn func(osc []byte)) {
+	for _, c := range p {
+		switch {
+		case c == 0x1b && s.state == 2:
+			s.state = 3
+		case c == 0x1b:
+			s.state = 1
+		case s.state == 1 && c == ']':
+			s.state, s.buf = 2, s.buf[:0]
+		case s.state == 2 && c != 0x07:
+			if len(s.buf) < maxOSC {
+				s.buf = append(s.buf, c)
+			}
+		case s.state == 2 || s.state == 3 && c == '\\': // BEL or ST.
+			fn(s.buf)
+			s.state = 0
+		default:
+			s.state = 0
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package vt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOSCScanner(t *testing.T) {
+	t.Parallel()
+
+	var s OSCScanner
+	var got []string
+	fn := func(osc []byte) { got = append(got, string(osc)) }
+	for _, p := range []string{"a\x1b]0;ti", "tle\x07b\x1b]133;A\x1b", "\\\x1b]2;x\x1b[m\x1b]2;y\x07"} {
+		s.Scan([]byte(p), fn)
+	}
+	if expect := []string{"0;title", "133;A", "2;y"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("Unexpected OSC, got %q expected %q", got, expect)
+	}
+}
@@ -0,0 +1,684 @@
+// Package vt provides an in-memory model of a VT100/xterm screen, fed with
+// the output of a pty, to know what a terminal would display without one:
+// server side thumbnails, assertions in tests or redraws on reattach.
+//
+// The common subset of xterm is supported: cursor movements, erasures,
+// insertions and deletions, scroll regions, colors and attributes, and the
+// alternate screen. Every character is assumed to be one cell wide.
+package vt
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Color is a cell color: DefaultColor, an index in the 256 colors palette,
+// or a true color made with RGB.
+type Color int32
+
+// DefaultColor is the default foreground or background color.
+const DefaultColor Color = -1
+
+const rgbFlag = 1 << 24
+
+// RGB returns the true color r, g, b.
+func RGB(r, g, b uint8) Color {
+	return Color(rgbFlag | int32(r)<<16 | int32(g)<<8 | int32(b))
+}
+
+// IsRGB tells whether c is a true color, and returns its components.
+func (c Color) IsRGB() (r, g, b uint8, ok bool) {
+	if c < 0 || c&rgbFlag == 0 {
+		return 0, 0, 0, false
+	}
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), true
+}
+
+// Attr holds the rendition of a cell.
+type Attr struct {
+	Fg, Bg    Color
+	Bold      bool
+	Faint     bool
+	Italic    bool
+	Underline bool
+	Blink     bool
+	Reverse   bool
+	Hidden    bool
+	Strike    bool
+}
+
+var defaultAttr = Attr{Fg: DefaultColor, Bg: DefaultColor}
+
+// Cell is a character cell of the screen.
+type Cell struct {
+	Rune rune // ' ' when blank.
+	Attr Attr
+}
+
+// Parser states.
+const (
+	stateGround = iota
+	stateEsc
+	stateEscInter // After ESC and an intermediate byte, such as ESC ( B.
+	stateCSI
+	stateString // In an OSC, DCS, SOS, PM or APC string, ignored.
+)
+
+type cursor struct {
+	row, col int
+	attr     Attr
+}
+
+// Terminal is an in-memory terminal screen. Writing to it updates the
+// screen like a terminal displaying what is written. It is safe for
+// concurrent use.
+type Terminal struct {
+	mu sync.Mutex
+
+	rows, cols int
+	grid       [][]Cell
+	main       [][]Cell // Main screen while the alternate one is shown.
+	cur        cursor
+	saved      cursor
+	wrapNext   bool // The next character wraps to the next line.
+	top, bot   int  // Scroll region, inclusive.
+	hidden     bool // The cursor is hidden.
+	appCursor  bool // Application cursor keys mode (DECCKM).
+	tabWidth   int
+
+	state   int
+	params  []int
+	param   int
+	hasNum  bool
+	private byte   // Private marker of a control sequence, such as '?'.
+	partial []byte // Incomplete UTF-8 sequence.
+}
+
+// New returns a blank Terminal of rows and cols.
+func New(rows, cols int) *Terminal {
+	if rows < 1 {
+		rows = 1
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	t := &Terminal{rows: rows, cols: cols, tabWidth: 8}
+	t.reset()
+	return t
+}
+
+func (t *Terminal) reset() {
+	t.grid = blankGrid(t.rows, t.cols)
+	t.main = nil
+	t.cur = cursor{attr: defaultAttr}
+	t.saved = t.cur
+	t.wrapNext = false
+	t.top, t.bot = 0, t.rows-1
+	t.hidden = false
+	t.appCursor = false
+	t.state = stateGround
+}
+
+func blankGrid(rows, cols int) [][]Cell {
+	g := make([][]Cell, rows)
+	for i := range g {
+		g[i] = blankLine(cols, defaultAttr)
+	}
+	return g
+}
+
+func blankLine(cols int, a Attr) []Cell {
+	l := make([]Cell, cols)
+	for i := range l {
+		l[i] = Cell{Rune: ' ', Attr: Attr{Fg: DefaultColor, Bg: a.Bg}}
+	}
+	return l
+}
+
+// Screen returns a copy of the cells of the screen, by rows.
+func (t *Terminal) Screen() [][]Cell {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := make([][]Cell, t.rows)
+	for i, l := range t.grid {
+		s[i] = append([]Cell(nil), l...)
+	}
+	return s
+}
+
+// Cursor returns the position of the cursor, from 0.
+func (t *Terminal) Cursor() (row, col int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cur.row, t.cur.col
+}
+
+// CursorVisible tells whether the cursor is shown.
+func (t *Terminal) CursorVisible() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.hidden
+}
+
+// AppCursor tells whether the application cursor keys mode is set, in
+// which case cursor keys send SS3 sequences. See pty.EncodeKeys.
+func (t *Terminal) AppCursor() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.appCursor
+}
+
+// Size returns the size of the screen.
+func (t *Terminal) Size() (rows, cols int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rows, t.cols
+}
+
+// String returns the text of the screen, one line per row without trailing
+// blanks.
+func (t *Terminal) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var b strings.Builder
+	for i, l := range t.grid {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		line := make([]rune, len(l))
+		for j, c := range l {
+			line[j] = c.Rune
+		}
+		b.WriteString(strings.TrimRight(string(line), " "))
+	}
+	return b.String()
+}
+
+// Resize resizes the screen, keeping its top left content. The scroll
+// region is reset.
+func (t *Terminal) Resize(rows, cols int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if rows < 1 {
+		rows = 1
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	t.grid = resizeGrid(t.grid, rows, cols)
+	if t.main != nil {
+		t.main = resizeGrid(t.main, rows, cols)
+	}
+	t.rows, t.cols = rows, cols
+	t.top, t.bot = 0, rows-1
+	t.cur.row, t.cur.col = clamp(t.cur.row, 0, rows-1), clamp(t.cur.col, 0, cols-1)
+	t.saved.row, t.saved.col = clamp(t.saved.row, 0, rows-1), clamp(t.saved.col, 0, cols-1)
+	t.wrapNext = false
+}
+
+func resizeGrid(g [][]Cell, rows, cols int) [][]Cell {
+	n := blankGrid(rows, cols)
+	for i := 0; i < rows && i < len(g); i++ {
+		copy(n[i], g[i])
+	}
+	return n
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// Write updates the screen with p. It never fails.
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(p)
+	if len(t.partial) > 0 {
+		p = append(t.partial, p...)
+		t.partial = nil
+	}
+	for len(p) > 0 {
+		c := p[0]
+		if t.state != stateGround || c < 0x80 {
+			t.byte(c)
+			p = p[1:]
+			continue
+		}
+		if !utf8.FullRune(p) {
+			t.partial = append([]byte(nil), p...)
+			break
+		}
+		r, size := utf8.DecodeRune(p)
+		t.print(r)
+		p = p[size:]
+	}
+	return n, nil
+}
+
+// byte processes an ASCII byte or a byte of an escape sequence.
+func (t *Terminal) byte(c byte) {
+	switch c {
+	case 0x1b:
+		t.state = stateEsc
+		return
+	case 0x18, 0x1a: // CAN and SUB abort sequences.
+		t.state = stateGround
+		return
+	}
+
+	switch t.state {
+	case stateGround:
+		if c < 0x20 || c == 0x7f {
+			t.control(c)
+		} else {
+			t.print(rune(c))
+		}
+	case stateEsc:
+		t.esc(c)
+	case stateEscInter:
+		if c >= 0x30 {
+			t.state = stateGround
+		}
+	case stateCSI:
+		t.csiByte(c)
+	case stateString:
+		if c == 0x07 {
+			t.state = stateGround
+		}
+	}
+}
+
+func (t *Terminal) control(c byte) {
+	switch c {
+	case '\b':
+		if t.cur.col > 0 {
+			t.cur.col--
+		}
+		t.wrapNext = false
+	case '\t':
+		col := (t.cur.col/t.tabWidth + 1) * t.tabWidth
+		t.cur.col = clamp(col, 0, t.cols-1)
+		t.wrapNext = false
+	case '\n', '\v', '\f':
+		t.lineFeed()
+	case '\r':
+		t.cur.col = 0
+		t.wrapNext = false
+	}
+}
+
+func (t *Terminal) print(r rune) {
+	if t.wrapNext {
+		t.cur.col = 0
+		t.lineFeed()
+	}
+	t.grid[t.cur.row][t.cur.col] = Cell{Rune: r, Attr: t.cur.attr}
+	if t.cur.col == t.cols-1 {
+		t.wrapNext = true
+	} else {
+		t.cur.col++
+	}
+}
+
+// lineFeed moves the cursor down, scrolling at the bottom of the scroll
+// region.
+func (t *Terminal) lineFeed() {
+	t.wrapNext = false
+	if t.cur.row == t.bot {
+		t.scrollUp(1)
+	} else if t.cur.row < t.rows-1 {
+		t.cur.row++
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling at the top of the scroll
+// region.
+func (t *Terminal) reverseIndex() {
+	t.wrapNext = false
+	if t.cur.row == t.top {
+		t.scrollDown(1)
+	} else if t.cur.row > 0 {
+		t.cur.row--
+	}
+}
+
+// scrollUp scrolls the scroll region up by n lines.
+func (t *Terminal) scrollUp(n int) {
+	t.deleteLines(t.top, n)
+}
+
+// scrollDown scrolls the scroll region down by n lines.
+func (t *Terminal) scrollDown(n int) {
+	t.insertLines(t.top, n)
+}
+
+// deleteLines deletes n lines at row, within the scroll region.
+func (t *Terminal) deleteLines(row, n int) {
+	n = clamp(n, 0, t.bot-row+1)
+	region := t.grid[row : t.bot+1]
+	copy(region, region[n:])
+	for i := len(region) - n; i < len(region); i++ {
+		region[i] = blankLine(t.cols, t.cur.attr)
+	}
+}
+
+// insertLines inserts n blank lines at row, within the scroll region.
+func (t *Terminal) insertLines(row, n int) {
+	n = clamp(n, 0, t.bot-row+1)
+	region := t.grid[row : t.bot+1]
+	copy(region[n:], region)
+	for i := 0; i < n; i++ {
+		region[i] = blankLine(t.cols, t.cur.attr)
+	}
+}
+
+func (t *Terminal) esc(c byte) {
+	t.state = stateGround
+	switch c {
+	case '[':
+		t.state = stateCSI
+		t.params = t.params[:0]
+		t.param, t.hasNum, t.private = 0, false, 0
+	case ']', 'P', 'X', '^', '_':
+		t.state = stateString
+	case '7':
+		t.saved = t.cur
+	case '8':
+		t.cur = t.saved
+		t.wrapNext = false
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.cur.col = 0
+		t.lineFeed()
+	case 'M':
+		t.reverseIndex()
+	case 'c':
+		t.reset()
+	default:
+		if c >= 0x20 && c <= 0x2f {
+			t.state = stateEscInter
+		}
+	}
+}
+
+// maxParams and maxParam bound the number and the values of the parameters
+// of a control sequence, so hostile output can neither grow them nor
+// overflow them. The extra parameters are dropped, the values clamped.
+const (
+	maxParams = 32
+	maxParam  = 65535
+)
+
+func (t *Terminal) csiByte(c byte) {
+	switch {
+	case c >= '0' && c <= '9':
+		if t.param = t.param*10 + int(c-'0'); t.param > maxParam {
+			t.param = maxParam
+		}
+		t.hasNum = true
+	case c == ';' || c == ':':
+		t.pushParam()
+	case c >= '<' && c <= '?':
+		t.private = c
+	case c >= 0x40 && c <= 0x7e:
+		t.pushParam()
+		t.state = stateGround
+		t.csi(c)
+	case c < 0x20:
+		t.control(c) // Executed in the middle of sequences.
+	}
+}
+
+func (t *Terminal) pushParam() {
+	if !t.hasNum {
+		t.param = -1 // Default.
+	}
+	if len(t.params) < maxParams {
+		t.params = append(t.params, t.param)
+	}
+	t.param, t.hasNum = 0, false
+}
+
+// arg returns the i-th parameter, or def when missing or zero.
+func (t *Terminal) arg(i, def int) int {
+	if i >= len(t.params) || t.params[i] <= 0 {
+		return def
+	}
+	return t.params[i]
+}
+
+func (t *Terminal) csi(final byte) {
+	if t.private != 0 && t.private != '?' {
+		return // Unsupported, such as secondary device attributes.
+	}
+	if t.private == '?' {
+		switch final {
+		case 'h':
+			t.setMode(true)
+		case 'l':
+			t.setMode(false)
+		}
+		return
+	}
+
+	t.wrapNext = false
+	switch final {
+	case 'A':
+		t.cur.row = clamp(t.cur.row-t.arg(0, 1), 0, t.rows-1)
+	case 'B', 'e':
+		t.cur.row = clamp(t.cur.row+t.arg(0, 1), 0, t.rows-1)
+	case 'C', 'a':
+		t.cur.col = clamp(t.cur.col+t.arg(0, 1), 0, t.cols-1)
+	case 'D':
+		t.cur.col = clamp(t.cur.col-t.arg(0, 1), 0, t.cols-1)
+	case 'E':
+		t.cur.row, t.cur.col = clamp(t.cur.row+t.arg(0, 1), 0, t.rows-1), 0
+	case 'F':
+		t.cur.row, t.cur.col = clamp(t.cur.row-t.arg(0, 1), 0, t.rows-1), 0
+	case 'G', '`':
+		t.cur.col = clamp(t.arg(0, 1)-1, 0, t.cols-1)
+	case 'd':
+		t.cur.row = clamp(t.arg(0, 1)-1, 0, t.rows-1)
+	case 'H', 'f':
+		t.cur.row = clamp(t.arg(0, 1)-1, 0, t.rows-1)
+		t.cur.col = clamp(t.arg(1, 1)-1, 0, t.cols-1)
+	case 'J':
+		t.eraseDisplay(t.arg(0, 0))
+	case 'K':
+		t.eraseLine(t.arg(0, 0))
+	case 'L':
+		if t.cur.row >= t.top && t.cur.row <= t.bot {
+			t.insertLines(t.cur.row, t.arg(0, 1))
+		}
+	case 'M':
+		if t.cur.row >= t.top && t.cur.row <= t.bot {
+			t.deleteLines(t.cur.row, t.arg(0, 1))
+		}
+	case '@':
+		line := t.grid[t.cur.row][t.cur.col:]
+		n := clamp(t.arg(0, 1), 0, len(line))
+		copy(line[n:], line)
+		t.blank(line[:n])
+	case 'P':
+		line := t.grid[t.cur.row][t.cur.col:]
+		n := clamp(t.arg(0, 1), 0, len(line))
+		copy(line, line[n:])
+		t.blank(line[len(line)-n:])
+	case 'X':
+		line := t.grid[t.cur.row][t.cur.col:]
+		t.blank(line[:clamp(t.arg(0, 1), 0, len(line))])
+	case 'S':
+		t.scrollUp(t.arg(0, 1))
+	case 'T':
+		t.scrollDown(t.arg(0, 1))
+	case 'r':
+		top, bot := t.arg(0, 1)-1, t.arg(1, t.rows)-1
+		if top < bot && bot < t.rows {
+			t.top, t.bot = top, bot
+			t.cur.row, t.cur.col = 0, 0
+		}
+	case 's':
+		t.saved = t.cur
+	case 'u':
+		t.cur = t.saved
+	case 'm':
+		t.sgr()
+	}
+}
+
+// blank erases cells with the current background.
+func (t *Terminal) blank(cells []Cell) {
+	for i := range cells {
+		cells[i] = Cell{Rune: ' ', Attr: Attr{Fg: DefaultColor, Bg: t.cur.attr.Bg}}
+	}
+}
+
+func (t *Terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseLine(0)
+		for _, l := range t.grid[t.cur.row+1:] {
+			t.blank(l)
+		}
+	case 1:
+		t.eraseLine(1)
+		for _, l := range t.grid[:t.cur.row] {
+			t.blank(l)
+		}
+	case 2, 3:
+		for _, l := range t.grid {
+			t.blank(l)
+		}
+	}
+}
+
+func (t *Terminal) eraseLine(mode int) {
+	line := t.grid[t.cur.row]
+	switch mode {
+	case 0:
+		t.blank(line[t.cur.col:])
+	case 1:
+		t.blank(line[:t.cur.col+1])
+	case 2:
+		t.blank(line)
+	}
+}
+
+func (t *Terminal) setMode(on bool) {
+	for _, m := range t.params {
+		switch m {
+		case 1:
+			t.appCursor = on
+		case 25:
+			t.hidden = !on
+		case 47, 1047, 1049:
+			if m == 1049 && on {
+				t.saved = t.cur
+			}
+			t.altScreen(on)
+			if m == 1049 && !on {
+				t.cur = t.saved
+			}
+		}
+	}
+}
+
+func (t *Terminal) altScreen(on bool) {
+	if on == (t.main != nil) {
+		return
+	}
+	if on {
+		t.main, t.grid = t.grid, blankGrid(t.rows, t.cols)
+	} else {
+		t.grid, t.main = t.main, nil
+	}
+}
+
+// sgr applies a Select Graphic Rendition sequence.
+func (t *Terminal) sgr() {
+	a := &t.cur.attr
+	if len(t.params) == 0 {
+		*a = defaultAttr
+		return
+	}
+	for i := 0; i < len(t.params); i++ {
+		p := t.params[i]
+		switch {
+		case p <= 0:
+			*a = defaultAttr
+		case p == 1:
+			a.Bold = true
+		case p == 2:
+			a.Faint = true
+		case p == 3:
+			a.Italic = true
+		case p == 4:
+			a.Underline = true
+		case p == 5 || p == 6:
+			a.Blink = true
+		case p == 7:
+			a.Reverse = true
+		case p == 8:
+			a.Hidden = true
+		case p == 9:
+			a.Strike = true
+		case p == 21 || p == 22:
+			a.Bold, a.Faint = false, false
+		case p == 23:
+			a.Italic = false
+		case p == 24:
+			a.Underline = false
+		case p == 25:
+			a.Blink = false
+		case p == 27:
+			a.Reverse = false
+		case p == 28:
+			a.Hidden = false
+		case p == 29:
+			a.Strike = false
+		case p >= 30 && p <= 37:
+			a.Fg = Color(p - 30)
+		case p == 38:
+			a.Fg, i = t.extendedColor(i, a.Fg)
+		case p == 39:
+			a.Fg = DefaultColor
+		case p >= 40 && p <= 47:
+			a.Bg = Color(p - 40)
+		case p == 48:
+			a.Bg, i = t.extendedColor(i, a.Bg)
+		case p == 49:
+			a.Bg = DefaultColor
+		case p >= 90 && p <= 97:
+			a.Fg = Color(p - 90 + 8)
+		case p >= 100 && p <= 107:
+			a.Bg = Color(p - 100 + 8)
+		}
+	}
+}
+
+// extendedColor parses the 256 colors or true color following the 38 or
+// 48 parameter at i, returning it and the index of its last parameter.
+func (t *Terminal) extendedColor(i int, old Color) (Color, int) {
+	arg := func(j int) int {
+		if j < len(t.params) && t.params[j] > 0 {
+			return t.params[j]
+		}
+		return 0
+	}
+	switch arg(i + 1) {
+	case 5:
+		return Color(arg(i+2) & 0xff), i + 2
+	case 2:
+		return RGB(uint8(arg(i+2)), uint8(arg(i+3)), uint8(arg(i+4))), i + 4
+	}
+	return old, len(t.params)
+}
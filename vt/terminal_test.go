@@ -0,0 +1,72 @@
+package vt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTerminal(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		in       string
+		want     string
+		row, col int
+	}{
+		{"text", "hello\r\nworld", "hello\nworld\n\n", 1, 5},
+		{"wrap", "abcdefgh", "abcdef\ngh\n\n", 1, 2},
+		{"scroll", "1\r\n2\r\n3\r\n4\r\n5", "2\n3\n4\n5", 3, 1},
+		{"cursor", "\x1b[2;3Hx\x1b[Ay\x1b[10;10Hz", "   y\n  x\n\n     z", 3, 5},
+		{"erase", "abcdef\x1b[1;3H\x1b[K\r\nxyz\x1b[1K", "ab\n\n\n", 1, 3},
+		{"insert delete", "abcdef\r\x1b[2@\x1b[3P", "bcd\n\n\n", 0, 0},
+		{"split utf-8", "h\xc3", "h\n\n\n", 0, 1},
+		{"title and colors", "\x1b]0;title\x07\x1b[1;38;5;208mok\x1b[0m", "ok\n\n\n", 0, 2},
+		{"alt screen", "main\x1b[?1049hfull\x1b[?1049l", "main\n\n\n", 0, 4},
+		{"scroll region", "1\r\n2\r\n3\r\n4\x1b[2;3r\x1b[3;1H\n", "1\n3\n\n4", 2, 0},
+	} {
+		term := New(4, 6)
+		if _, err := term.Write([]byte(tc.in)); err != nil {
+			t.Fatalf("Unexpected error from Write: %s", err)
+		}
+		if got := term.String(); got != tc.want {
+			t.Errorf("%s: unexpected screen, got %q expected %q", tc.name, got, tc.want)
+		}
+		if row, col := term.Cursor(); row != tc.row || col != tc.col {
+			t.Errorf("%s: unexpected cursor, got %d,%d expected %d,%d", tc.name, row, col, tc.row, tc.col)
+		}
+	}
+}
+
+func TestTerminalAttrs(t *testing.T) {
+	t.Parallel()
+
+	term := New(2, 10)
+	_, _ = term.Write([]byte("\x1b[1;31;48;2;1;2;3mA\x1b[0mB\xc3\xa9"))
+	screen := term.Screen()
+	if c := screen[0][0]; c.Rune != 'A' || !c.Attr.Bold || c.Attr.Fg != 1 || c.Attr.Bg != RGB(1, 2, 3) {
+		t.Errorf("Unexpected cell: %+v", c)
+	}
+	if c := screen[0][1]; c.Rune != 'B' || c.Attr != defaultAttr {
+		t.Errorf("Unexpected cell: %+v", c)
+	}
+	if c := screen[0][2]; c.Rune != 'é' {
+		t.Errorf("Unexpected cell: %+v", c)
+	}
+}
+
+func TestTerminalHostileCSI(t *testing.T) {
+	t.Parallel()
+
+	term := New(4, 6)
+	in := "ab\x1b[" + strings.Repeat("1;", 1<<16) + "m\x1b[" + strings.Repeat("9", 64) + "Cc"
+	if _, err := term.Write([]byte(in)); err != nil {
+		t.Fatalf("Unexpected error from Write: %s", err)
+	}
+	if len(term.params) > maxParams {
+		t.Errorf("Unexpected number of parameters kept, got %d expected at most %d", len(term.params), maxParams)
+	}
+	if row, col := term.Cursor(); row != 0 || col != 5 {
+		t.Errorf("Unexpected cursor, got %d,%d expected 0,5", row, col)
+	}
+}
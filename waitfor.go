@@ -0,0 +1,72 @@
+package pty
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+)
+
+// WaitFor reads the output of the session until it contains substr, for
+// example to know a server running in the session is ready, and returns
+// the output read up to the end of substr. The output read past it is
+// returned by the next reads of the session. See WaitForRegexp.
+func (s *Session) WaitFor(ctx context.Context, substr string) ([]byte, error) {
+	return s.waitFor(ctx, func(b []byte, from int) int {
+		// Only the output read since the last call can complete a match.
+		if from -= len(substr) - 1; from < 0 || len(substr) == 0 {
+			from = 0
+		}
+		if i := bytes.Index(b[from:], []byte(substr)); i >= 0 {
+			return from + i + len(substr)
+		}
+		return -1
+	})
+}
+
+// WaitForRegexp reads the output of the session until it matches re, and
+// returns the output read up to the end of the match. The output read past
+// it is returned by the next reads of the session.
+//
+// It returns the output read so far along with ctx.Err() if ctx is done
+// first, or the error ending the output, io.EOF when the command exited.
+// A pending read is only aborted where the pty supports deadlines. The
+// output retained is bounded: once over 2MiB, the oldest is discarded down
+// to the last 1MiB, and is neither matched nor returned.
+func (s *Session) WaitForRegexp(ctx context.Context, re *regexp.Regexp) ([]byte, error) {
+	return s.waitFor(ctx, func(b []byte, _ int) int {
+		if loc := re.FindIndex(b); loc != nil {
+			return loc[1]
+		}
+		return -1
+	})
+}
+
+// waitFor reads the output until match returns the end of a match in it,
+// given the output and the length of the part of it already given to
+// match. Once the output is over twice defaultExpectBuffer long, the
+// oldest is discarded down to defaultExpectBuffer.
+func (s *Session) waitFor(ctx context.Context, match func(b []byte, from int) int) ([]byte, error) {
+	var out []byte
+	from := 0
+	buf := make([]byte, chunkSize)
+	for {
+		if end := match(out, from); end >= 0 {
+			if rest := out[end:]; len(rest) > 0 {
+				s.mu.Lock()
+				s.pending = append(append([]byte(nil), rest...), s.pending...)
+				s.mu.Unlock()
+			}
+			return out[:end], nil
+		}
+		from = len(out)
+		n, err := ReadContext(ctx, sessionOutput{s: s}, buf)
+		out = append(out, buf[:n]...)
+		if over := len(out) - defaultExpectBuffer; over > defaultExpectBuffer {
+			out = append(out[:0], out[over:]...)
+			from -= over
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+}
@@ -1,5 +1,5 @@
-//go:build !windows
-// +build !windows
+//go:build !windows && !js && !plan9 && !wasip1
+// +build !windows,!js,!plan9,!wasip1
 
 package pty
 
@@ -20,7 +20,7 @@ type Winsize struct {
 // Setsize resizes t to s.
 func Setsize(t *os.File, ws *Winsize) error {
 	//nolint:gosec // Expected unsafe pointer for Syscall call.
-	return ioctl(t, syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(ws)))
+	return wrapErr(OpResize, t.Name(), ioctl(t, syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(ws))))
 }
 
 // GetsizeFull returns the full terminal size description.
@@ -29,7 +29,7 @@ func GetsizeFull(t *os.File) (size *Winsize, err error) {
 
 	//nolint:gosec // Expected unsafe pointer for Syscall call.
 	if err := ioctl(t, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws))); err != nil {
-		return nil, err
+		return nil, wrapErr(OpGetsize, t.Name(), err)
 	}
 	return &ws, nil
 }
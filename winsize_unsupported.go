@@ -1,5 +1,5 @@
-//go:build windows
-// +build windows
+//go:build windows || js || plan9 || wasip1
+// +build windows js plan9 wasip1
 
 package pty
 
@@ -0,0 +1,12 @@
+module github.com/creack/pty/xssh
+
+go 1.19
+
+require (
+	github.com/creack/pty v1.1.21
+	golang.org/x/crypto v0.25.0
+)
+
+require golang.org/x/sys v0.22.0 // indirect
+
+replace github.com/creack/pty => ../
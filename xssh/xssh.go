@@ -0,0 +1,27 @@
+// Package xssh handles the channel requests of the sessions of a
+// golang.org/x/crypto/ssh server for a pty. It is a module of its own, so
+// that the pty module does not depend on SSH.
+package xssh
+
+import (
+	"github.com/creack/pty"
+	"golang.org/x/crypto/ssh"
+)
+
+// ServeRequests handles the requests of a session channel received once s
+// started, as by pty.PtyRequest.Start from the "pty-req" request, until
+// reqs is closed: s is resized to the size of each "window-change"
+// request, and the other requests are refused. Run it from a new
+// goroutine, as reqs must be consumed for the connection to go on.
+func ServeRequests(s *pty.Session, reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		ok := false
+		if req.Type == "window-change" {
+			ws, err := pty.ParseWindowChange(req.Payload)
+			ok = err == nil && s.Resize(ws) == nil
+		}
+		if req.WantReply {
+			_ = req.Reply(ok, nil) // Best effort.
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package xssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/crypto/ssh"
+)
+
+// serve serves the session channels of conn as a minimal SSH server would.
+func serve(conn net.Conn, config *ssh.ServerConfig, cmd string) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		ch, creqs, err := nc.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer func() { _ = ch.Close() }()
+			var ptyReq *pty.PtyRequest
+			for req := range creqs {
+				switch req.Type {
+				case "pty-req":
+					ptyReq, err = pty.ParsePtyRequest(req.Payload)
+					_ = req.Reply(err == nil, nil)
+				case "shell":
+					_ = req.Reply(ptyReq != nil, nil)
+					if ptyReq == nil {
+						continue
+					}
+					s, err := ptyReq.Start(exec.Command("sh", "-c", cmd))
+					if err != nil {
+						return
+					}
+					defer func() { _ = s.Close() }()
+					go ServeRequests(s, creqs)
+					_, _ = io.Copy(ch, s)
+					_ = s.Wait()
+					_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Code uint32 }{0}))
+					return
+				default:
+					_ = req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+func TestServeRequests(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error from GenerateKey: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewSignerFromKey: %s", err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error from Listen: %s", err)
+	}
+	defer func() { _ = ln.Close() }()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			serve(conn, config, `stty size; echo ready; while [ "$(stty size)" != "40 120" ]; do sleep 0.05; done; echo resized`)
+		}
+	}()
+
+	client, err := ssh.Dial("tcp", ln.Addr().String(), &ssh.ClientConfig{User: "user", HostKeyCallback: ssh.InsecureIgnoreHostKey()})
+	if err != nil {
+		t.Fatalf("Unexpected error from Dial: %s", err)
+	}
+	defer func() { _ = client.Close() }()
+	s, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("Unexpected error from NewSession: %s", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	if err := s.RequestPty("vt100", 24, 80, nil); err != nil {
+		t.Fatalf("Unexpected error from RequestPty: %s", err)
+	}
+	stdout, err := s.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Unexpected error from StdoutPipe: %s", err)
+	}
+	if err := s.Shell(); err != nil {
+		t.Fatalf("Unexpected error from Shell: %s", err)
+	}
+
+	outc := make(chan string)
+	go func() {
+		var out bytes.Buffer
+		buf := make([]byte, 1024)
+		resized := false
+		for {
+			n, err := stdout.Read(buf)
+			out.Write(buf[:n])
+			if !resized && strings.Contains(out.String(), "ready") {
+				_ = s.WindowChange(40, 120) // Checked by the command.
+				resized = true
+			}
+			if err != nil {
+				outc <- out.String()
+				return
+			}
+		}
+	}()
+	select {
+	case out := <-outc:
+		if !strings.Contains(out, "24 80") || !strings.Contains(out, "resized") {
+			t.Errorf("Unexpected output %q", out)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timeout waiting for the command")
+	}
+}